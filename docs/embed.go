@@ -0,0 +1,34 @@
+// Package docs embeds documentation assets that are served by or
+// compiled into chathooks.
+package docs
+
+import (
+	"embed"
+	"io/fs"
+)
+
+//go:embed icons/*.png icons/*.jpg icons/*.svg
+var icons embed.FS
+
+//go:embed handlers/*/event-example_*.json handlers/*/event-example_*.txt
+var handlers embed.FS
+
+// Icons returns the handler icons in `docs/icons`, rooted at the icons
+// directory.
+func Icons() fs.FS {
+	return mustSub(icons, "icons")
+}
+
+// Handlers returns the example events in `docs/handlers`, rooted at the
+// handlers directory with a directory per handler key.
+func Handlers() fs.FS {
+	return mustSub(handlers, "handlers")
+}
+
+func mustSub(fsys fs.FS, dir string) fs.FS {
+	sub, err := fs.Sub(fsys, dir)
+	if err != nil {
+		panic(err) // embedded directories always exist
+	}
+	return sub
+}
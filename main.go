@@ -1,13 +1,13 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"os"
 
 	"github.com/grokify/mogo/config"
-	"github.com/grokify/sogo/net/http/httpsimple"
 
-	"github.com/grokify/chathooks/pkg/service"
+	"github.com/grokify/chathooks/pkg/cli"
 )
 
 /*
@@ -15,6 +15,13 @@ import (
 Use the `CHATHOOKS_TOKENS` environment variable to load secret
 tokens as a comma delimited string.
 
+Configuration is read from a YAML or JSON file set with `-config` or
+`CHATHOOKS_CONFIG_FILE`, environment variables and command-line flags.
+Send `SIGHUP` or edit the configuration file to reload it.
+
+Run `chathooks help` for the `render`, `send`, `proxy-send` and
+`build-url` commands.
+
 */
 
 // CHATHOOKS_URL=http://localhost:8080/hook CHATHOOKS_HOME_URL=http://localhost:8080 go run main.go
@@ -25,8 +32,8 @@ func main() {
 		panic(err)
 	}
 
-	svc := service.NewService()
-	fmt.Printf("Starting on port [%d] with engine [%s].\n",
-		svc.PortInt(), svc.HTTPEngine())
-	httpsimple.Serve(svc)
+	if err := cli.Run(context.Background(), os.Args[1:], os.Stdout, os.Stderr); err != nil {
+		fmt.Fprintf(os.Stderr, "chathooks: %v\n", err)
+		os.Exit(1)
+	}
 }
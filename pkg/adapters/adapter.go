@@ -1,70 +1,201 @@
 package adapters
 
 import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
 	"github.com/grokify/commonchat"
 	"github.com/rs/zerolog/log"
 	"github.com/valyala/fasthttp"
 
+	"github.com/grokify/chathooks/pkg/adapters/adapterutil"
+	"github.com/grokify/chathooks/pkg/config"
 	"github.com/grokify/chathooks/pkg/models"
+	"github.com/grokify/chathooks/pkg/redact"
 )
 
 var (
 	ShowDisplayName = false
 )
 
+const (
+	// MaxRetries is the number of times a rate limited delivery is retried.
+	MaxRetries = 2
+	// MaxRetryAfter is the longest delay waited before retrying a rate
+	// limited delivery. Longer delays fail with `429`.
+	MaxRetryAfter = 5 * time.Second
+)
+
+// StatusChecker is implemented by adapters with configurable success
+// status codes. Other adapters succeed with `2xx`.
+type StatusChecker interface {
+	SuccessStatus(statusCode int) bool
+}
+
+// AdapterSet has the adapter per output type, used with a request's
+// output URL, and the named adapters, which use their own configuration.
+// They are kept apart so a request cannot send a named adapter's
+// credentials to its own URL.
 type AdapterSet struct {
+	Types    map[string]commonchat.Adapter
 	Adapters map[string]commonchat.Adapter
 }
 
 func NewAdapterSet() AdapterSet {
-	return AdapterSet{Adapters: map[string]commonchat.Adapter{}}
+	return AdapterSet{
+		Types:    map[string]commonchat.Adapter{},
+		Adapters: map[string]commonchat.Adapter{}}
 }
 
-func (set *AdapterSet) SendWebhooks(hookData models.HookData) []models.ErrorInfo {
-	errs := []models.ErrorInfo{}
-	hookOpts := map[string]any{}
-	if hookData.OutputFormat == "nocard" {
-		hookOpts["useAttachments"] = false
-		log.Debug().
-			Str("hookData.outputFormat", hookData.OutputFormat).
-			Bool("hookOpts.useAttachments", hookOpts["useAttachments"].(bool)).
-			Msg("AdapterSet.SendWebhooks.HookOpts")
-	}
+// SendWebhooks sends the canonical message to the `outputType` adapter
+// and each named adapter, returning one result per delivery.
+func (set *AdapterSet) SendWebhooks(hookData models.HookData) []models.DeliveryResult {
+	dels := []models.DeliveryResult{}
+	hookOpts := HookDataOptions(hookData)
 	if len(hookData.OutputType) > 0 && len(hookData.OutputURL) > 0 {
-		if adapter, ok := set.Adapters[hookData.OutputType]; ok {
-			var msg any
-			req, res, err := adapter.SendWebhook(
-				hookData.OutputURL, hookData.CanonicalMessage, &msg, hookOpts)
+		if adapter, ok := set.Types[hookData.OutputType]; ok {
+			del := deliver(hookData.OutputType, adapter, func() (*fasthttp.Request, *fasthttp.Response, error) {
+				var msg any
+				return adapter.SendWebhook(
+					hookData.OutputURL, hookData.CanonicalMessage, &msg, hookOpts)
+			})
+			if len(del.Host) == 0 {
+				if u, err := url.Parse(hookData.OutputURL); err == nil {
+					del.Host = u.Host
+				}
+			}
 			log.Debug().
 				Str("output_type", hookData.OutputType).
-				Int("status_code", res.StatusCode()).
-				Str("output_url", hookData.OutputURL).
-				Str("body", string(res.Body())).
+				Int("status_code", del.StatusCode).
+				Str("output_host", del.Host).
+				Int64("latency_ms", del.LatencyMS).
 				Msg("ADAPTER_API_REQ_RES_INFO")
-			errs = set.procResponse(errs, req, res, err)
+			dels = append(dels, del)
 		}
 	}
 	for _, namedAdapter := range hookData.OutputNames {
 		if adapter, ok := set.Adapters[namedAdapter]; ok {
-			var msg any
-			req, res, err := adapter.SendMessage(
-				hookData.CanonicalMessage, &msg, hookOpts)
-			errs = set.procResponse(errs, req, res, err)
+			dels = append(dels, deliver(namedAdapter, adapter, func() (*fasthttp.Request, *fasthttp.Response, error) {
+				var msg any
+				return adapter.SendMessage(hookData.CanonicalMessage, &msg, hookOpts)
+			}))
+		}
+	}
+	return dels
+}
+
+// OptionParams are the custom query string and route parameters passed to
+// adapters as string options, overriding adapter configuration options.
+var OptionParams = []string{
+	config.ParamNameChannel,
+	config.ParamNameUsername,
+	config.ParamNameIconURL,
+	config.ParamNameStream,
+	config.ParamNameTopic}
+
+// HookDataOptions returns the adapter options for a request: those of
+// `HookOptions`, the `OptionParams` overrides, the input type and the
+// custom parameters, with secrets redacted, as `adapterutil.OptionParams`
+// and the hook data as `adapterutil.OptionHookData`, used by templates.
+func HookDataOptions(hookData models.HookData) map[string]any {
+	hookOpts := HookOptions(hookData.OutputFormat)
+	for _, name := range OptionParams {
+		if v := strings.TrimSpace(hookData.CustomQueryParams.Get(name)); len(v) > 0 {
+			hookOpts[name] = v
 		}
 	}
-	return errs
+	if len(hookData.InputType) > 0 {
+		hookOpts[config.ParamNameInputType] = hookData.InputType
+	}
+	// Params are used by templates, so secrets such as the token are
+	// redacted.
+	params := map[string]string{}
+	customParams := redact.Values(hookData.CustomQueryParams)
+	for name := range customParams {
+		params[name] = customParams.Get(name)
+	}
+	hookOpts[adapterutil.OptionParams] = params
+	hookOpts[adapterutil.OptionHookData] = hookData
+	return hookOpts
 }
 
-func (set *AdapterSet) procResponse(errs []models.ErrorInfo, req *fasthttp.Request, res *fasthttp.Response, err error) []models.ErrorInfo {
+// HookOptions returns the adapter options for an `outputFormat`. Adapters
+// that render several formats read it from `config.ParamNameOutputFormat`.
+func HookOptions(outputFormat string) map[string]any {
+	hookOpts := map[string]any{}
+	if len(outputFormat) > 0 {
+		hookOpts[config.ParamNameOutputFormat] = outputFormat
+	}
+	if outputFormat == config.ParamNameOutputFormatNocard {
+		hookOpts[adapterutil.OptionUseAttachments] = false
+		log.Debug().
+			Str("hookData.outputFormat", outputFormat).
+			Bool("hookOpts.useAttachments", false).
+			Msg("AdapterSet.SendWebhooks.HookOpts")
+	}
+	return hookOpts
+}
+
+// deliver calls `send` and retries rate limited, `429`, responses after
+// the delay they request, up to `MaxRetries` times and if the delay is at
+// most `MaxRetryAfter`.
+func deliver(adapterName string, adapter commonchat.Adapter, send func() (*fasthttp.Request, *fasthttp.Response, error)) models.DeliveryResult {
+	start := time.Now()
+	retries := 0
+	for {
+		req, res, err := send()
+		if err == nil && res != nil && res.StatusCode() == http.StatusTooManyRequests && retries < MaxRetries {
+			if wait, ok := adapterutil.RetryAfter(res); ok && wait <= MaxRetryAfter {
+				log.Info().
+					Str("adapter", adapterName).
+					Dur("retry_after", wait).
+					Int("retry", retries+1).
+					Msg("ADAPTER_RATE_LIMITED_RETRY")
+				fasthttp.ReleaseRequest(req)
+				fasthttp.ReleaseResponse(res)
+				time.Sleep(wait)
+				retries++
+				continue
+			}
+		}
+		del := deliveryResult(adapterName, adapter, start, req, res, err)
+		del.Retries = retries
+		return del
+	}
+}
+
+// deliveryResult builds the result for one adapter call and releases the
+// request and response. The status is checked by adapters that implement
+// `StatusChecker`.
+func deliveryResult(adapterName string, adapter commonchat.Adapter, start time.Time, req *fasthttp.Request, res *fasthttp.Response, err error) models.DeliveryResult {
+	del := models.DeliveryResult{
+		Adapter:   adapterName,
+		LatencyMS: time.Since(start).Milliseconds()}
+	if req != nil {
+		del.Host = string(req.URI().Host())
+		fasthttp.ReleaseRequest(req)
+	}
 	if err != nil {
-		errs = append(errs, models.ErrorInfo{StatusCode: 500, Body: []byte(err.Error())})
-	} else if res.StatusCode() > 299 {
-		errs = append(errs, models.ErrorInfo{
-			StatusCode: res.StatusCode(),
-			Body:       res.Body(),
-		})
+		del.StatusCode = http.StatusBadGateway
+		del.Error = err.Error()
+	} else if res != nil {
+		del.StatusCode = res.StatusCode()
+		if checker, ok := adapter.(StatusChecker); ok {
+			if checker.SuccessStatus(del.StatusCode) {
+				del.Accepted = !del.OK()
+			} else if del.OK() {
+				del.Error = fmt.Sprintf("unexpected status [%d]", del.StatusCode)
+			}
+		}
+		if !del.OK() && len(del.Error) == 0 {
+			del.Error = string(res.Body())
+		}
+	}
+	if res != nil {
+		fasthttp.ReleaseResponse(res)
 	}
-	fasthttp.ReleaseRequest(req)
-	fasthttp.ReleaseResponse(res)
-	return errs
+	return del
 }
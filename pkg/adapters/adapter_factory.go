@@ -0,0 +1,192 @@
+package adapters
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/grokify/commonchat"
+	ccglip "github.com/grokify/commonchat/glip"
+	ccslack "github.com/grokify/commonchat/slack"
+
+	"github.com/grokify/chathooks/pkg/adapters/adapterutil"
+	"github.com/grokify/chathooks/pkg/adapters/discord"
+	"github.com/grokify/chathooks/pkg/adapters/email"
+	"github.com/grokify/chathooks/pkg/adapters/generichttp"
+	"github.com/grokify/chathooks/pkg/adapters/googlechat"
+	"github.com/grokify/chathooks/pkg/adapters/matrix"
+	"github.com/grokify/chathooks/pkg/adapters/mattermost"
+	"github.com/grokify/chathooks/pkg/adapters/ringcentral"
+	"github.com/grokify/chathooks/pkg/adapters/rocketchat"
+	"github.com/grokify/chathooks/pkg/adapters/slackcompat"
+	"github.com/grokify/chathooks/pkg/adapters/teams"
+	"github.com/grokify/chathooks/pkg/adapters/telegram"
+	"github.com/grokify/chathooks/pkg/adapters/webex"
+	"github.com/grokify/chathooks/pkg/adapters/zulip"
+	"github.com/grokify/chathooks/pkg/config"
+)
+
+const (
+	AdapterTypeDiscord     = "discord"
+	AdapterTypeEmail       = "email"
+	AdapterTypeGlip        = "glip"
+	AdapterTypeGoogleChat  = "googlechat"
+	AdapterTypeHTTP        = "http"
+	AdapterTypeMatrix      = "matrix"
+	AdapterTypeMattermost  = "mattermost"
+	AdapterTypeRingCentral = "ringcentral"
+	AdapterTypeRocketChat  = "rocketchat"
+	AdapterTypeSlack       = "slack"
+	AdapterTypeTeams       = "teams"
+	AdapterTypeTelegram    = "telegram"
+	AdapterTypeWebex       = "webex"
+	AdapterTypeZulip       = "zulip"
+)
+
+// NewAdapterSetConfig returns an `AdapterSet` with an adapter per output
+// type, used with the `outputType` and `outputURL` query string parameters,
+// and the named adapters in the configuration.
+func NewAdapterSetConfig(cfg config.Configuration) (AdapterSet, error) {
+	set := NewAdapterSet()
+	for _, adapterType := range AdapterTypes() {
+		adapter, err := NewAdapter(config.AdapterConfig{Type: adapterType})
+		if err != nil {
+			return set, err
+		}
+		set.Types[adapterType] = adapter
+	}
+	for _, adapterCfg := range cfg.Adapters {
+		name := strings.TrimSpace(adapterCfg.Name)
+		if len(name) == 0 {
+			return set, fmt.Errorf("adapter name not set for type [%s]", adapterCfg.Type)
+		}
+		adapter, err := NewAdapter(adapterCfg)
+		if err != nil {
+			return set, fmt.Errorf("adapter [%s]: %w", name, err)
+		}
+		set.Adapters[name] = adapter
+	}
+	return set, nil
+}
+
+// AdapterTypes returns the supported adapter types.
+func AdapterTypes() []string {
+	return []string{AdapterTypeDiscord, AdapterTypeEmail, AdapterTypeGlip, AdapterTypeGoogleChat, AdapterTypeHTTP,
+		AdapterTypeMatrix, AdapterTypeMattermost, AdapterTypeRingCentral, AdapterTypeRocketChat, AdapterTypeSlack,
+		AdapterTypeTeams, AdapterTypeTelegram, AdapterTypeWebex, AdapterTypeZulip}
+}
+
+// NewAdapter returns an adapter for an adapter configuration.
+func NewAdapter(adapterCfg config.AdapterConfig) (commonchat.Adapter, error) {
+	switch strings.ToLower(strings.TrimSpace(adapterCfg.Type)) {
+	case AdapterTypeDiscord:
+		return discord.NewAdapter(adapterCfg.URL), nil
+	case AdapterTypeEmail:
+		adapter := email.NewAdapter(adapterCfg.URL)
+		adapter.From = adapterutil.OptionString(adapterCfg.Options, email.OptionFrom)
+		adapter.To = adapterutil.OptionStrings(adapterCfg.Options, email.OptionTo)
+		adapter.Cc = adapterutil.OptionStrings(adapterCfg.Options, email.OptionCc)
+		return adapter, nil
+	case AdapterTypeGlip:
+		glipCfg := GlipConfig()
+		if len(adapterCfg.Options) > 0 {
+			var err error
+			if glipCfg, err = glipCfg.UpsertMSI(adapterCfg.Options); err != nil {
+				return nil, err
+			}
+		}
+		return newGlipAdapter(adapterCfg.URL, glipCfg), nil
+	case AdapterTypeGoogleChat:
+		return googlechat.NewAdapter(adapterCfg.URL), nil
+	case AdapterTypeHTTP:
+		httpCfg, err := generichttp.NewConfig(adapterCfg.Options)
+		if err != nil {
+			return nil, err
+		}
+		return generichttp.NewAdapterConfig(adapterCfg.URL, httpCfg)
+	case AdapterTypeMatrix:
+		adapter := matrix.NewAdapter(adapterCfg.URL)
+		adapter.RoomID = adapterutil.OptionString(adapterCfg.Options, matrix.OptionRoomID)
+		adapter.AccessToken = adapterutil.OptionString(adapterCfg.Options, matrix.OptionAccessToken)
+		return adapter, nil
+	case AdapterTypeMattermost:
+		adapter := mattermost.NewAdapter(adapterCfg.URL)
+		adapter.Overrides = slackcompat.NewOverrides(adapterCfg.Options)
+		return adapter, nil
+	case AdapterTypeRingCentral:
+		return ringcentral.NewAdapter(adapterCfg.URL), nil
+	case AdapterTypeRocketChat:
+		adapter := rocketchat.NewAdapter(adapterCfg.URL)
+		adapter.Overrides = slackcompat.NewOverrides(adapterCfg.Options)
+		return adapter, nil
+	case AdapterTypeSlack:
+		return ccslack.NewSlackAdapter(adapterCfg.URL)
+	case AdapterTypeTeams:
+		return teams.NewAdapter(adapterCfg.URL), nil
+	case AdapterTypeTelegram:
+		adapter := telegram.NewAdapter(adapterCfg.URL)
+		adapter.ChatID = adapterutil.OptionString(adapterCfg.Options, telegram.OptionChatID)
+		adapter.ParseMode = adapterutil.OptionString(adapterCfg.Options, telegram.OptionParseMode)
+		return adapter, nil
+	case AdapterTypeWebex:
+		adapter := webex.NewAdapter(adapterCfg.URL)
+		adapter.RoomID = adapterutil.OptionString(adapterCfg.Options, webex.OptionRoomID)
+		adapter.AccessToken = adapterutil.OptionString(adapterCfg.Options, webex.OptionAccessToken)
+		return adapter, nil
+	case AdapterTypeZulip:
+		adapter := zulip.NewAdapter(adapterCfg.URL)
+		adapter.Email = adapterutil.OptionString(adapterCfg.Options, zulip.OptionEmail)
+		adapter.APIKey = adapterutil.OptionString(adapterCfg.Options, zulip.OptionAPIKey)
+		adapter.Stream = adapterutil.OptionString(adapterCfg.Options, config.ParamNameStream)
+		if topic := adapterutil.OptionString(adapterCfg.Options, config.ParamNameTopic); len(topic) > 0 {
+			adapter.Topic = topic
+		}
+		return adapter, nil
+	default:
+		return nil, fmt.Errorf("unknown adapter type [%s]", adapterCfg.Type)
+	}
+}
+
+// ConvertMessage returns the payload an adapter type posts for a message,
+// without sending it. `opts` are the adapter options, as used for
+// `outputFormat`.
+func ConvertMessage(adapterType string, ccMsg commonchat.Message, opts map[string]any) (any, error) {
+	switch strings.ToLower(strings.TrimSpace(adapterType)) {
+	case AdapterTypeDiscord:
+		return discord.Convert(ccMsg, adapterutil.OutputFormat(opts)), nil
+	case AdapterTypeEmail:
+		return email.Convert(ccMsg), nil
+	case AdapterTypeGlip:
+		glipCfg := GlipConfig()
+		if len(opts) > 0 {
+			var err error
+			if glipCfg, err = glipCfg.UpsertMSI(opts); err != nil {
+				return nil, err
+			}
+		}
+		return ccglip.NewGlipAdapter("", glipCfg).CommonConverter.ConvertCommonMessage(ccMsg), nil
+	case AdapterTypeGoogleChat:
+		return googlechat.Convert(ccMsg, adapterutil.OutputFormat(opts)), nil
+	case AdapterTypeHTTP:
+		return generichttp.NewAdapter("").Request(ccMsg, opts)
+	case AdapterTypeMatrix:
+		return matrix.Convert(ccMsg, adapterutil.OutputFormat(opts)), nil
+	case AdapterTypeMattermost:
+		return mattermost.Convert(ccMsg, adapterutil.OutputFormat(opts), slackcompat.NewOverrides(opts)), nil
+	case AdapterTypeRingCentral:
+		return ringcentral.Convert(ccMsg, adapterutil.OutputFormat(opts)), nil
+	case AdapterTypeRocketChat:
+		return rocketchat.Convert(ccMsg, adapterutil.OutputFormat(opts), slackcompat.NewOverrides(opts)), nil
+	case AdapterTypeSlack:
+		return ccslack.ConvertCommonMessage(ccMsg), nil
+	case AdapterTypeTeams:
+		return teams.Convert(ccMsg, adapterutil.OutputFormat(opts)), nil
+	case AdapterTypeTelegram:
+		return telegram.Convert(ccMsg, "", adapterutil.OptionString(opts, telegram.OptionParseMode)), nil
+	case AdapterTypeWebex:
+		return webex.Convert(ccMsg, adapterutil.OutputFormat(opts)), nil
+	case AdapterTypeZulip:
+		return zulip.NewAdapter("").Message(ccMsg, opts)
+	default:
+		return nil, fmt.Errorf("unknown adapter type [%s]", adapterType)
+	}
+}
@@ -0,0 +1,151 @@
+package adapters
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/grokify/commonchat"
+
+	"github.com/grokify/chathooks/pkg/adapters/adapterutil"
+	"github.com/grokify/chathooks/pkg/adapters/webex"
+	"github.com/grokify/chathooks/pkg/config"
+	"github.com/grokify/chathooks/pkg/models"
+)
+
+// SendWebhooksRetryTests are responses to a first request. Rate limited
+// requests are retried once the requested delay passes.
+var SendWebhooksRetryTests = []struct {
+	header      string
+	value       string
+	body        string
+	wantStatus  int
+	wantRetries int
+}{
+	{"Retry-After", "0", "", http.StatusNoContent, 1},
+	{"X-RateLimit-Reset-After", "0.01", "", http.StatusNoContent, 1},
+	{"", "", `{"message": "You are being rate limited.", "retry_after": 0.01, "global": false}`, http.StatusNoContent, 1},
+	{"", "", `{"ok": false, "error_code": 429, "parameters": {"retry_after": 0}}`, http.StatusNoContent, 1},
+	{"Retry-After", "60", "", http.StatusTooManyRequests, 0}}
+
+func TestSendWebhooksRetry(t *testing.T) {
+	for _, tt := range SendWebhooksRetryTests {
+		calls := 0
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			calls++
+			if calls == 1 {
+				if len(tt.header) > 0 {
+					w.Header().Set(tt.header, tt.value)
+				}
+				w.WriteHeader(http.StatusTooManyRequests)
+				_, _ = w.Write([]byte(tt.body))
+				return
+			}
+			w.WriteHeader(http.StatusNoContent)
+		}))
+		set, err := NewAdapterSetConfig(config.Configuration{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		dels := set.SendWebhooks(models.HookData{
+			OutputType:       AdapterTypeDiscord,
+			OutputURL:        srv.URL,
+			CanonicalMessage: commonchat.Message{Activity: "Build passed"}})
+		srv.Close()
+		if len(dels) != 1 || dels[0].StatusCode != tt.wantStatus || dels[0].Retries != tt.wantRetries {
+			t.Errorf("SendWebhooks(%s: %s): want status [%d] retries [%d], got [%v]",
+				tt.header, tt.value, tt.wantStatus, tt.wantRetries, dels)
+		}
+	}
+}
+
+func TestSendWebhooksOptionParams(t *testing.T) {
+	got := map[string]any{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(&got)
+	}))
+	defer srv.Close()
+	set, err := NewAdapterSetConfig(config.Configuration{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	set.SendWebhooks(models.HookData{
+		OutputType:        AdapterTypeMattermost,
+		OutputURL:         srv.URL,
+		CustomQueryParams: url.Values{config.ParamNameChannel: {"ops"}, "other": {"x"}},
+		CanonicalMessage:  commonchat.Message{Activity: "Build passed"}})
+	if got["channel"] != "ops" {
+		t.Errorf("SendWebhooks(channel=ops): want channel [ops], got [%v]", got["channel"])
+	}
+}
+
+var SendWebhooksSuccessCodesTests = []struct {
+	status       int
+	wantOK       bool
+	wantAccepted bool
+}{
+	{http.StatusConflict, true, true},
+	{http.StatusCreated, true, false},
+	{http.StatusOK, false, false}}
+
+func TestSendWebhooksSuccessCodes(t *testing.T) {
+	for _, tt := range SendWebhooksSuccessCodesTests {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(tt.status)
+		}))
+		set, err := NewAdapterSetConfig(config.Configuration{Adapters: []config.AdapterConfig{{
+			Name:    "api",
+			Type:    AdapterTypeHTTP,
+			URL:     srv.URL,
+			Options: map[string]any{"successCodes": []any{201, 409}}}}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		dels := set.SendWebhooks(models.HookData{
+			OutputNames:      []string{"api"},
+			CanonicalMessage: commonchat.Message{Activity: "Build passed"}})
+		srv.Close()
+		if len(dels) != 1 || dels[0].OK() != tt.wantOK || dels[0].Accepted != tt.wantAccepted {
+			t.Errorf("SendWebhooks(%d): want ok [%v] accepted [%v], got [%v]", tt.status, tt.wantOK, tt.wantAccepted, dels)
+		}
+	}
+}
+
+// TestSendWebhooksNamedOutputType checks a request's output URL is not
+// sent with a named adapter, which may have credentials.
+func TestSendWebhooksNamedOutputType(t *testing.T) {
+	calls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+	}))
+	defer srv.Close()
+	set, err := NewAdapterSetConfig(config.Configuration{Adapters: []config.AdapterConfig{
+		{Name: "ops", Type: AdapterTypeHTTP, URL: "https://example.com/hook"},
+		{Name: AdapterTypeWebex, Type: AdapterTypeWebex, URL: webex.MessagesURL, Options: map[string]any{webex.OptionAccessToken: "secret"}}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, outputType := range []string{"ops", AdapterTypeWebex} {
+		set.SendWebhooks(models.HookData{
+			OutputType:       outputType,
+			OutputURL:        srv.URL + "/v1/messages?roomId=room",
+			CanonicalMessage: commonchat.Message{Activity: "Build passed"}})
+	}
+	if calls != 0 {
+		t.Errorf("SendWebhooks(named outputType): want no requests to the output URL, got [%d]", calls)
+	}
+}
+
+func TestHookDataOptionsParams(t *testing.T) {
+	opts := HookDataOptions(models.HookData{CustomQueryParams: url.Values{
+		config.ParamNameToken:     {"secret"},
+		config.ParamNameOutputURL: {"https://hooks.slack.com/services/T0/B0/secret"},
+		"repo":                    {"chathooks"}}})
+	params := adapterutil.OptionParamsMap(opts)
+	if params["token"] == "secret" || strings.Contains(params["outputURL"], "secret") || params["repo"] != "chathooks" {
+		t.Errorf("HookDataOptions(): want token and outputURL redacted, got [%v]", params)
+	}
+}
@@ -0,0 +1,326 @@
+// Package adapterutil has helpers shared by the output adapters.
+package adapterutil
+
+import (
+	"encoding/json"
+	"fmt"
+	"html"
+	"net/http"
+	neturl "net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/grokify/commonchat"
+	hum "github.com/grokify/mogo/net/http/httputilmore"
+	"github.com/valyala/fasthttp"
+
+	"github.com/grokify/chathooks/pkg/config"
+	"github.com/grokify/chathooks/pkg/models"
+)
+
+const (
+	ClientTimeout = 30 * time.Second
+
+	OptionUseAttachments = "useAttachments"
+	// OptionParams are the request's custom parameters, a
+	// `map[string]string`.
+	OptionParams = "params"
+	// OptionHookData is the request's `models.HookData`.
+	OptionHookData = "hookData"
+
+	HeaderRateLimitResetAfter = "X-RateLimit-Reset-After"
+	HeaderRetryAfter          = "Retry-After"
+)
+
+// NewClient returns the fasthttp client used by adapters.
+func NewClient() *fasthttp.Client {
+	return &fasthttp.Client{
+		ReadTimeout:  ClientTimeout,
+		WriteTimeout: ClientTimeout}
+}
+
+// OutputFormat returns the `outputFormat` in adapter options, e.g. from
+// `adapters.HookOptions`. It returns `nocard` when `useAttachments` is
+// false and `card` when neither is set.
+func OutputFormat(opts map[string]any) string {
+	if format, ok := opts[config.ParamNameOutputFormat].(string); ok {
+		if format = config.MustParseOutputFormat(format); len(format) > 0 {
+			return format
+		}
+	}
+	if useAttachments, ok := opts[OptionUseAttachments].(bool); ok && !useAttachments {
+		return config.ParamNameOutputFormatNocard
+	}
+	return config.ParamNameOutputFormatCard
+}
+
+// OptionString returns a string adapter option.
+func OptionString(opts map[string]any, name string) string {
+	switch v := opts[name].(type) {
+	case string:
+		return v
+	case fmt.Stringer:
+		return v.String()
+	default:
+		return ""
+	}
+}
+
+// OptionParamsMap returns the custom parameters in adapter options.
+func OptionParamsMap(opts map[string]any) map[string]string {
+	if params, ok := opts[OptionParams].(map[string]string); ok {
+		return params
+	}
+	return map[string]string{}
+}
+
+// OptionHookDataValue returns the `models.HookData` in adapter options.
+func OptionHookDataValue(opts map[string]any) models.HookData {
+	if hookData, ok := opts[OptionHookData].(models.HookData); ok {
+		return hookData
+	}
+	return models.HookData{}
+}
+
+// OptionStrings returns a list adapter option, from a list or a
+// comma-delimited string.
+func OptionStrings(opts map[string]any, name string) []string {
+	var items []string
+	switch v := opts[name].(type) {
+	case string:
+		items = strings.Split(v, ",")
+	case []string:
+		items = v
+	case []any:
+		for _, item := range v {
+			items = append(items, fmt.Sprint(item))
+		}
+	}
+	return appendLines([]string{}, items...)
+}
+
+// ColorNames maps Slack attachment color names, which not every service
+// supports, to hex colors.
+var ColorNames = map[string]string{
+	"good":    "#2eb886",
+	"warning": "#daa038",
+	"danger":  "#a30200"}
+
+// HexColor returns the hex color for a color name, e.g. `good`. Other
+// colors are returned as is.
+func HexColor(color string) string {
+	color = strings.TrimSpace(color)
+	if hex, ok := ColorNames[strings.ToLower(color)]; ok {
+		return hex
+	}
+	return color
+}
+
+// ParseHex returns the RGB value of a `#rrggbb` or `#rgb` hex color or a
+// color name. The `#` is optional.
+func ParseHex(color string) (uint32, bool) {
+	color = strings.TrimPrefix(HexColor(color), "#")
+	if len(color) == 3 {
+		color = string([]byte{color[0], color[0], color[1], color[1], color[2], color[2]})
+	}
+	if len(color) != 6 {
+		return 0, false
+	}
+	v, err := strconv.ParseUint(color, 16, 32)
+	if err != nil {
+		return 0, false
+	}
+	return uint32(v), true
+}
+
+// Chathooks markdown patterns: `[text](url)` links capturing the text and
+// URL, `**bold**` capturing the text, and code fence lines.
+var (
+	RxLink      = regexp.MustCompile(`\[([^\[\]]+)\]\(([^()\s]+)\)`)
+	RxBold      = regexp.MustCompile(`\*\*([^*]+?)\*\*`)
+	RxCodeFence = regexp.MustCompile("(?m)^[ \t]*```[A-Za-z0-9_+-]*[ \t]*$\n?")
+)
+
+// MarkdownHTML converts chathooks markdown to HTML: bold, `http`,
+// `https` and `mailto` links and line breaks. Other text is escaped.
+func MarkdownHTML(s string) string {
+	return MarkdownHTMLBold(s, "strong")
+}
+
+// MarkdownHTMLBold is `MarkdownHTML` with bold text in a `boldTag`
+// element, e.g. `b` for services that do not support `strong`.
+func MarkdownHTMLBold(s, boldTag string) string {
+	s = html.EscapeString(strings.ReplaceAll(s, "\r\n", "\n"))
+	s = RxCodeFence.ReplaceAllString(s, "")
+	s = RxLink.ReplaceAllStringFunc(s, func(link string) string {
+		m := RxLink.FindStringSubmatch(link)
+		if !IsLinkURL(m[2]) {
+			return m[1] + " (" + m[2] + ")"
+		}
+		return `<a href="` + m[2] + `">` + m[1] + "</a>"
+	})
+	s = RxBold.ReplaceAllString(s, "<"+boldTag+">$1</"+boldTag+">")
+	return strings.ReplaceAll(strings.TrimSpace(s), "\n", "<br>")
+}
+
+// linkSchemes are the URL schemes rendered as HTML links. Other links,
+// e.g. `javascript:`, are rendered as text.
+var linkSchemes = []string{"http://", "https://", "mailto:"}
+
+// IsLinkURL returns true if `u` is a URL safe to render as an HTML link.
+func IsLinkURL(u string) bool {
+	u = strings.ToLower(u)
+	for _, scheme := range linkSchemes {
+		if strings.HasPrefix(u, scheme) {
+			return true
+		}
+	}
+	return false
+}
+
+// MarkdownText converts chathooks markdown to plain text, with links as
+// `text (url)`.
+func MarkdownText(s string) string {
+	s = RxCodeFence.ReplaceAllString(s, "")
+	s = RxLink.ReplaceAllString(s, "$1 ($2)")
+	return strings.TrimSpace(RxBold.ReplaceAllString(s, "$1"))
+}
+
+// MarkdownLines returns the message as chathooks markdown lines: the
+// activity in bold, the title and text, and each attachment's lines with
+// fields.
+func MarkdownLines(ccMsg commonchat.Message) []string {
+	lines := appendLines([]string{}, Bold(ccMsg.Activity), ccMsg.Title, ccMsg.Text)
+	for _, att := range ccMsg.Attachments {
+		lines = append(lines, AttachmentLines(att, true)...)
+	}
+	return lines
+}
+
+// AttachmentLines returns the attachment pretext, title in bold and text
+// lines, followed by its fields as `**title**: value` lines if
+// `withFields` is true.
+func AttachmentLines(att commonchat.Attachment, withFields bool) []string {
+	lines := appendLines([]string{}, att.Pretext, Bold(att.Title), att.Text)
+	if !withFields {
+		return lines
+	}
+	for _, field := range att.Fields {
+		if title := strings.TrimSpace(field.Title); len(title) > 0 {
+			lines = appendLines(lines, Bold(title)+": "+field.Value)
+		} else {
+			lines = appendLines(lines, field.Value)
+		}
+	}
+	return lines
+}
+
+// Bold returns `s` as chathooks markdown bold, or an empty string.
+func Bold(s string) string {
+	if s = strings.TrimSpace(s); len(s) > 0 {
+		return "**" + s + "**"
+	}
+	return ""
+}
+
+// appendLines appends the non-empty trimmed `items` to `lines`.
+func appendLines(lines []string, items ...string) []string {
+	for _, item := range items {
+		if item = strings.TrimSpace(item); len(item) > 0 {
+			lines = append(lines, item)
+		}
+	}
+	return lines
+}
+
+// PostForm posts `values` URL encoded to `url`. The caller releases the
+// request and response.
+func PostForm(client *fasthttp.Client, url string, values neturl.Values, headers map[string]string) (*fasthttp.Request, *fasthttp.Response, error) {
+	req := fasthttp.AcquireRequest()
+	res := fasthttp.AcquireResponse()
+	req.SetBodyString(values.Encode())
+	req.Header.SetRequestURI(url)
+	req.Header.SetMethod(http.MethodPost)
+	req.Header.Set(hum.HeaderContentType, hum.ContentTypeAppFormURLEncodedUtf8)
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	if client == nil {
+		client = NewClient()
+	}
+	return req, res, client.Do(req, res)
+}
+
+// PostJSON posts `body` as JSON to `url`. The caller releases the
+// request and response.
+func PostJSON(client *fasthttp.Client, url string, body any, headers map[string]string) (*fasthttp.Request, *fasthttp.Response, error) {
+	return DoJSON(client, http.MethodPost, url, body, headers)
+}
+
+// DoJSON sends `body` as JSON to `url` with `method`. The caller releases
+// the request and response.
+func DoJSON(client *fasthttp.Client, method, url string, body any, headers map[string]string) (*fasthttp.Request, *fasthttp.Response, error) {
+	req := fasthttp.AcquireRequest()
+	res := fasthttp.AcquireResponse()
+	bytes, err := json.Marshal(body)
+	if err != nil {
+		return req, res, err
+	}
+	req.SetBody(bytes)
+	req.Header.SetRequestURI(url)
+	req.Header.SetMethod(method)
+	req.Header.Set(hum.HeaderContentType, hum.ContentTypeAppJSONUtf8)
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	if client == nil {
+		client = NewClient()
+	}
+	return req, res, client.Do(req, res)
+}
+
+// SameOrigin returns true if the URLs have the same scheme and host.
+// Adapters send their stored credentials only to their own origin, not to
+// URLs supplied by requests.
+func SameOrigin(url1, url2 string) bool {
+	u1, err1 := neturl.Parse(strings.TrimSpace(url1))
+	u2, err2 := neturl.Parse(strings.TrimSpace(url2))
+	return err1 == nil && err2 == nil && len(u1.Host) > 0 &&
+		strings.EqualFold(u1.Scheme, u2.Scheme) && strings.EqualFold(u1.Host, u2.Host)
+}
+
+// WebhookUID returns the `webhookuid` router parameter.
+func WebhookUID(ctx *fasthttp.RequestCtx) (string, error) {
+	return fmt.Sprintf("%s", ctx.UserValue("webhookuid")), nil
+}
+
+// RetryAfter returns the delay requested by a rate limited, `429`,
+// response from the `X-RateLimit-Reset-After` or `Retry-After` header
+// in seconds, or the `retry_after` JSON body property, as Discord and
+// Slack send, or `parameters.retry_after`, as Telegram sends.
+func RetryAfter(res *fasthttp.Response) (time.Duration, bool) {
+	for _, header := range []string{HeaderRateLimitResetAfter, HeaderRetryAfter} {
+		if v := strings.TrimSpace(string(res.Header.Peek(header))); len(v) > 0 {
+			if seconds, err := strconv.ParseFloat(v, 64); err == nil && seconds >= 0 {
+				return time.Duration(seconds * float64(time.Second)), true
+			}
+		}
+	}
+	body := struct {
+		RetryAfter *float64 `json:"retry_after"`
+		Parameters struct {
+			RetryAfter *float64 `json:"retry_after"`
+		} `json:"parameters"`
+	}{}
+	if err := json.Unmarshal(res.Body(), &body); err != nil {
+		return 0, false
+	}
+	for _, seconds := range []*float64{body.RetryAfter, body.Parameters.RetryAfter} {
+		if seconds != nil && *seconds >= 0 {
+			return time.Duration(*seconds * float64(time.Second)), true
+		}
+	}
+	return 0, false
+}
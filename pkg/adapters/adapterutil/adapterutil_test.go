@@ -0,0 +1,103 @@
+package adapterutil
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/grokify/commonchat"
+)
+
+var HexColorTests = []struct {
+	v    string
+	want string
+}{
+	{"good", "#2eb886"},
+	{"Danger", "#a30200"},
+	{"#ff0000", "#ff0000"},
+	{"", ""}}
+
+func TestHexColor(t *testing.T) {
+	for _, tt := range HexColorTests {
+		try := HexColor(tt.v)
+		if try != tt.want {
+			t.Errorf("HexColor(%v): want [%v], got [%v]", tt.v, tt.want, try)
+		}
+	}
+}
+
+var ParseHexTests = []struct {
+	v      string
+	want   uint32
+	wantOk bool
+}{
+	{"#00ff00", 0x00ff00, true},
+	{"F00", 0xff0000, true},
+	{"warning", 0xdaa038, true},
+	{"blue", 0, false},
+	{"#12345", 0, false},
+	{"", 0, false}}
+
+func TestParseHex(t *testing.T) {
+	for _, tt := range ParseHexTests {
+		try, ok := ParseHex(tt.v)
+		if try != tt.want || ok != tt.wantOk {
+			t.Errorf("ParseHex(%v): want [%v, %v], got [%v, %v]", tt.v, tt.want, tt.wantOk, try, ok)
+		}
+	}
+}
+
+func TestMarkdownLines(t *testing.T) {
+	ccMsg := commonchat.Message{
+		Activity: "Build passed",
+		Text:     " [Build #1](https://example.com/builds/1) ",
+		Attachments: []commonchat.Attachment{{
+			Title: "minimal",
+			Fields: []commonchat.Field{
+				{Title: "Branch", Value: "master"},
+				{Value: "no title"},
+				{}}}}}
+	want := "**Build passed**\n[Build #1](https://example.com/builds/1)\n**minimal**\n**Branch**: master\nno title"
+	if try := strings.Join(MarkdownLines(ccMsg), "\n"); try != want {
+		t.Errorf("MarkdownLines(): want [%v], got [%v]", want, try)
+	}
+}
+
+var MarkdownHTMLTests = []struct {
+	v    string
+	want string
+}{
+	{"**Build** [#1](https://example.com/builds/1?a=1&b=2)", `<strong>Build</strong> <a href="https://example.com/builds/1?a=1&amp;b=2">#1</a>`},
+	{"[mail](mailto:ops@example.com)", `<a href="mailto:ops@example.com">mail</a>`},
+	{"[x](javascript:alert(1))", "[x](javascript:alert(1))"},
+	{"[x](JavaScript:alert`1`)", "x (JavaScript:alert`1`)"},
+	{"[x](data:text/html;base64,PHNjcmlwdD4=)", "x (data:text/html;base64,PHNjcmlwdD4=)"},
+	{"<b>\nline", "&lt;b&gt;<br>line"}}
+
+func TestMarkdownHTML(t *testing.T) {
+	for _, tt := range MarkdownHTMLTests {
+		try := MarkdownHTML(tt.v)
+		if try != tt.want {
+			t.Errorf("MarkdownHTML(%v): want [%v], got [%v]", tt.v, tt.want, try)
+		}
+	}
+}
+
+var SameOriginTests = []struct {
+	url1 string
+	url2 string
+	want bool
+}{
+	{"https://matrix.example.org", "https://Matrix.example.org/_matrix/client/v3/rooms/x/send", true},
+	{"https://matrix.example.org", "http://matrix.example.org", false},
+	{"https://matrix.example.org", "https://matrix.example.org:8448", false},
+	{"https://matrix.example.org", "https://evil.example.com", false},
+	{"", "", false}}
+
+func TestSameOrigin(t *testing.T) {
+	for _, tt := range SameOriginTests {
+		try := SameOrigin(tt.url1, tt.url2)
+		if try != tt.want {
+			t.Errorf("SameOrigin(%v, %v): want [%v], got [%v]", tt.url1, tt.url2, tt.want, try)
+		}
+	}
+}
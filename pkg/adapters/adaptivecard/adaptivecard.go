@@ -0,0 +1,333 @@
+// Package adaptivecard renders a `commonchat.Message` as an Adaptive
+// Card, as used by Microsoft Teams, Webex and RingCentral.
+package adaptivecard
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/grokify/commonchat"
+
+	"github.com/grokify/chathooks/pkg/adapters/adapterutil"
+)
+
+const (
+	ContentType = "application/vnd.microsoft.card.adaptive"
+	Schema      = "http://adaptivecards.io/schemas/adaptive-card.json"
+	TypeCard    = "AdaptiveCard"
+	Version     = "1.3"
+
+	TypeColumn    = "Column"
+	TypeColumnSet = "ColumnSet"
+	TypeContainer = "Container"
+	TypeFactSet   = "FactSet"
+	TypeImage     = "Image"
+	TypeTextBlock = "TextBlock"
+
+	StyleAccent    = "accent"
+	StyleAttention = "attention"
+	StyleEmphasis  = "emphasis"
+	StyleGood      = "good"
+	StyleWarning   = "warning"
+)
+
+// Card is an Adaptive Card.
+type Card struct {
+	Schema  string    `json:"$schema"`
+	Type    string    `json:"type"`
+	Version string    `json:"version"`
+	Body    []Element `json:"body"`
+	MSTeams *MSTeams  `json:"msteams,omitempty"`
+}
+
+// MSTeams holds Microsoft Teams card properties.
+type MSTeams struct {
+	Width string `json:"width,omitempty"`
+}
+
+// Element is a card element. Only the properties of its `Type` are set.
+type Element struct {
+	Type                     string    `json:"type"`
+	Text                     string    `json:"text,omitempty"`
+	Size                     string    `json:"size,omitempty"`
+	Weight                   string    `json:"weight,omitempty"`
+	IsSubtle                 bool      `json:"isSubtle,omitempty"`
+	Wrap                     bool      `json:"wrap,omitempty"`
+	URL                      string    `json:"url,omitempty"`
+	AltText                  string    `json:"altText,omitempty"`
+	Style                    string    `json:"style,omitempty"`
+	Width                    string    `json:"width,omitempty"`
+	Spacing                  string    `json:"spacing,omitempty"`
+	Separator                bool      `json:"separator,omitempty"`
+	VerticalContentAlignment string    `json:"verticalContentAlignment,omitempty"`
+	Items                    []Element `json:"items,omitempty"`
+	Columns                  []Element `json:"columns,omitempty"`
+	Facts                    []Fact    `json:"facts,omitempty"`
+}
+
+// Fact is a `FactSet` entry.
+type Fact struct {
+	Title string `json:"title"`
+	Value string `json:"value"`
+}
+
+// NewCard returns an empty card.
+func NewCard() Card {
+	return Card{
+		Schema:  Schema,
+		Type:    TypeCard,
+		Version: Version,
+		Body:    []Element{}}
+}
+
+// TextBlock returns a wrapping text block.
+func TextBlock(text string) Element {
+	return Element{Type: TypeTextBlock, Text: text, Wrap: true}
+}
+
+// Convert returns the card for a message. When `textOnly` is true, as for
+// `outputFormat=nocard`, the card has text blocks only and attachments
+// are rendered as text without colors, columns or images.
+func Convert(ccMsg commonchat.Message, textOnly bool) Card {
+	card := NewCard()
+	if header, ok := header(ccMsg, textOnly); ok {
+		card.Body = append(card.Body, header)
+	}
+	if title := strings.TrimSpace(ccMsg.Title); len(title) > 0 {
+		tb := TextBlock(Markdown(title))
+		tb.Size = "Medium"
+		tb.Weight = "Bolder"
+		card.Body = append(card.Body, tb)
+	}
+	if text := strings.TrimSpace(ccMsg.Text); len(text) > 0 {
+		card.Body = append(card.Body, TextBlock(Markdown(text)))
+	}
+	for _, att := range ccMsg.Attachments {
+		if textOnly {
+			card.Body = append(card.Body, attachmentText(att)...)
+		} else if container, ok := attachmentContainer(att); ok {
+			card.Body = append(card.Body, container)
+		}
+	}
+	return card
+}
+
+// header returns the activity with the message icon.
+func header(ccMsg commonchat.Message, textOnly bool) (Element, bool) {
+	activity := strings.TrimSpace(ccMsg.Activity)
+	if len(activity) == 0 {
+		return Element{}, false
+	}
+	tb := TextBlock(Markdown(activity))
+	tb.Weight = "Bolder"
+	iconURL := strings.TrimSpace(ccMsg.IconURL)
+	if textOnly || len(iconURL) == 0 {
+		return tb, true
+	}
+	return Element{
+		Type: TypeColumnSet,
+		Columns: []Element{
+			{Type: TypeColumn, Width: "auto", Items: []Element{
+				{Type: TypeImage, URL: iconURL, Size: "Small", AltText: activity}}},
+			{Type: TypeColumn, Width: "stretch", VerticalContentAlignment: "Center", Items: []Element{tb}}}}, true
+}
+
+// attachmentContainer returns a container styled by the attachment color
+// with its text, fields and thumbnail.
+func attachmentContainer(att commonchat.Attachment) (Element, bool) {
+	items := []Element{}
+	if pretext := strings.TrimSpace(att.Pretext); len(pretext) > 0 {
+		items = append(items, TextBlock(Markdown(pretext)))
+	}
+	if author := authorText(att); len(author) > 0 {
+		tb := TextBlock(author)
+		tb.IsSubtle = true
+		tb.Size = "Small"
+		items = append(items, tb)
+	}
+	if title := strings.TrimSpace(att.Title); len(title) > 0 {
+		tb := TextBlock(Markdown(title))
+		tb.Weight = "Bolder"
+		items = append(items, tb)
+	}
+	if text := strings.TrimSpace(att.Text); len(text) > 0 {
+		items = append(items, TextBlock(Markdown(text)))
+	}
+	if thumbnail := strings.TrimSpace(att.ThumbnailURL); len(thumbnail) > 0 {
+		items = []Element{{
+			Type: TypeColumnSet,
+			Columns: []Element{
+				{Type: TypeColumn, Width: "stretch", Items: items},
+				{Type: TypeColumn, Width: "auto", Items: []Element{
+					{Type: TypeImage, URL: thumbnail, Size: "Medium"}}}}}}
+	}
+	items = append(items, fieldElements(att.Fields)...)
+	if len(items) == 0 {
+		return Element{}, false
+	}
+	return Element{
+		Type:      TypeContainer,
+		Style:     ColorStyle(att.Color),
+		Separator: true,
+		Items:     items}, true
+}
+
+// fieldElements lays out consecutive short fields in pairs of columns and
+// other fields full width, as Slack and Glip do.
+func fieldElements(fields []commonchat.Field) []Element {
+	elements := []Element{}
+	var short []Element
+	flush := func() {
+		if len(short) > 0 {
+			elements = append(elements, Element{Type: TypeColumnSet, Columns: short})
+			short = nil
+		}
+	}
+	for _, field := range fields {
+		items := fieldItems(field)
+		if len(items) == 0 {
+			continue
+		}
+		if !field.Short {
+			flush()
+			elements = append(elements, items...)
+			continue
+		}
+		short = append(short, Element{Type: TypeColumn, Width: "stretch", Items: items})
+		if len(short) == 2 {
+			flush()
+		}
+	}
+	flush()
+	return elements
+}
+
+func fieldItems(field commonchat.Field) []Element {
+	items := []Element{}
+	if title := strings.TrimSpace(field.Title); len(title) > 0 {
+		tb := TextBlock(Markdown(title))
+		tb.Weight = "Bolder"
+		tb.Spacing = "Small"
+		items = append(items, tb)
+	}
+	if value := strings.TrimSpace(field.Value); len(value) > 0 {
+		tb := TextBlock(Markdown(value))
+		tb.Spacing = "None"
+		items = append(items, tb)
+	}
+	return items
+}
+
+// attachmentText returns an attachment as text blocks, with fields as
+// `**title**: value` lines.
+func attachmentText(att commonchat.Attachment) []Element {
+	lines := []string{}
+	for _, s := range []string{att.Pretext, authorText(att), boldText(att.Title), att.Text} {
+		if s = strings.TrimSpace(s); len(s) > 0 {
+			lines = append(lines, s)
+		}
+	}
+	for _, field := range att.Fields {
+		title, value := strings.TrimSpace(field.Title), strings.TrimSpace(field.Value)
+		switch {
+		case len(title) > 0 && len(value) > 0:
+			lines = append(lines, "**"+title+"**: "+value)
+		case len(title) > 0:
+			lines = append(lines, boldText(title))
+		case len(value) > 0:
+			lines = append(lines, value)
+		}
+	}
+	if len(lines) == 0 {
+		return nil
+	}
+	tb := TextBlock(Markdown(strings.Join(lines, "\n")))
+	tb.Separator = true
+	return []Element{tb}
+}
+
+func authorText(att commonchat.Attachment) string {
+	name := strings.TrimSpace(att.AuthorName)
+	if len(name) == 0 {
+		return ""
+	}
+	if link := strings.TrimSpace(att.AuthorLink); len(link) > 0 {
+		return "[" + name + "](" + link + ")"
+	}
+	return name
+}
+
+func boldText(s string) string {
+	if s = strings.TrimSpace(s); len(s) == 0 {
+		return ""
+	}
+	return "**" + s + "**"
+}
+
+var rxNewlines = regexp.MustCompile(`\n+`)
+
+// Markdown converts chathooks markdown to the subset supported by
+// Adaptive Card text blocks, which do not support code blocks and need
+// blank lines between lines.
+func Markdown(s string) string {
+	s = strings.ReplaceAll(s, "\r\n", "\n")
+	s = adapterutil.RxCodeFence.ReplaceAllString(s, "")
+	return strings.TrimSpace(rxNewlines.ReplaceAllString(s, "\n\n"))
+}
+
+// ColorStyle returns the container style closest to an attachment color,
+// which is a hex color or a Slack color name. Adaptive Cards only
+// support a fixed set of styles.
+func ColorStyle(color string) string {
+	color = strings.ToLower(strings.TrimSpace(color))
+	switch color {
+	case "":
+		return ""
+	case StyleGood:
+		return StyleGood
+	case StyleWarning:
+		return StyleWarning
+	case "danger":
+		return StyleAttention
+	}
+	rgb, ok := adapterutil.ParseHex(color)
+	if !ok {
+		return StyleEmphasis
+	}
+	hue, saturation := hueSaturation(float64(rgb>>16&0xff)/255,
+		float64(rgb>>8&0xff)/255, float64(rgb&0xff)/255)
+	switch {
+	case saturation < 0.25:
+		return StyleEmphasis
+	case hue < 20 || hue >= 330:
+		return StyleAttention
+	case hue < 70:
+		return StyleWarning
+	case hue < 170:
+		return StyleGood
+	default:
+		return StyleAccent
+	}
+}
+
+// hueSaturation returns the HSV hue in degrees and the saturation.
+func hueSaturation(r, g, b float64) (float64, float64) {
+	maxC, minC := max(r, g, b), min(r, g, b)
+	delta := maxC - minC
+	if maxC == 0 || delta == 0 {
+		return 0, 0
+	}
+	var hue float64
+	switch maxC {
+	case r:
+		hue = 60 * (g - b) / delta
+	case g:
+		hue = 60 * ((b-r)/delta + 2)
+	default:
+		hue = 60 * ((r-g)/delta + 4)
+	}
+	if hue < 0 {
+		hue += 360
+	}
+	return hue, delta / maxC
+}
@@ -0,0 +1,93 @@
+package adaptivecard
+
+import (
+	"testing"
+
+	"github.com/grokify/commonchat"
+)
+
+var ColorStyleTests = []struct {
+	v    string
+	want string
+}{
+	{"", ""},
+	{"good", StyleGood},
+	{"danger", StyleAttention},
+	{"#00ff00", StyleGood},
+	{"#36a64f", StyleGood},
+	{"#ff0000", StyleAttention},
+	{"#f00", StyleAttention},
+	{"#ffa500", StyleWarning},
+	{"#0000ff", StyleAccent},
+	{"#808080", StyleEmphasis},
+	{"blue", StyleEmphasis}}
+
+func TestColorStyle(t *testing.T) {
+	for _, tt := range ColorStyleTests {
+		try := ColorStyle(tt.v)
+		if try != tt.want {
+			t.Errorf("ColorStyle(%v): want [%v], got [%v]", tt.v, tt.want, try)
+		}
+	}
+}
+
+var MarkdownTests = []struct {
+	v    string
+	want string
+}{
+	{"**bold** [link](https://example.com)", "**bold** [link](https://example.com)"},
+	{"line 1\nline 2", "line 1\n\nline 2"},
+	{"```\ncode\n```", "code"},
+	{"a\r\n\r\n\r\nb", "a\n\nb"}}
+
+func TestMarkdown(t *testing.T) {
+	for _, tt := range MarkdownTests {
+		try := Markdown(tt.v)
+		if try != tt.want {
+			t.Errorf("Markdown(%q): want [%q], got [%q]", tt.v, tt.want, try)
+		}
+	}
+}
+
+func TestConvertFields(t *testing.T) {
+	ccMsg := commonchat.Message{
+		Activity: "Build passed",
+		IconURL:  "https://example.com/icon.png",
+		Attachments: []commonchat.Attachment{{
+			Color: "#00ff00",
+			Text:  "Build #1",
+			Fields: []commonchat.Field{
+				{Title: "Message", Value: "commit"},
+				{Title: "Branch", Value: "master", Short: true},
+				{Title: "Type", Value: "push", Short: true},
+				{Title: "Author", Value: "Sven", Short: true}}}}}
+
+	card := Convert(ccMsg, false)
+	if len(card.Body) != 2 || card.Body[0].Type != TypeColumnSet || card.Body[1].Type != TypeContainer {
+		t.Fatalf("Convert(): want header and container, got [%v]", card.Body)
+	}
+	container := card.Body[1]
+	if container.Style != StyleGood {
+		t.Errorf("Convert(): want style [%s], got [%s]", StyleGood, container.Style)
+	}
+	// text, long field title and value, two short field rows.
+	wantTypes := []string{TypeTextBlock, TypeTextBlock, TypeTextBlock, TypeColumnSet, TypeColumnSet}
+	if len(container.Items) != len(wantTypes) {
+		t.Fatalf("Convert(): want [%d] items, got [%v]", len(wantTypes), container.Items)
+	}
+	for i, want := range wantTypes {
+		if container.Items[i].Type != want {
+			t.Errorf("Convert(): item [%d] want [%s], got [%s]", i, want, container.Items[i].Type)
+		}
+	}
+	if n := len(container.Items[3].Columns); n != 2 {
+		t.Errorf("Convert(): want [2] short fields in a row, got [%d]", n)
+	}
+
+	card = Convert(ccMsg, true)
+	for _, el := range card.Body {
+		if el.Type != TypeTextBlock {
+			t.Errorf("Convert(textOnly): want only text blocks, got [%s]", el.Type)
+		}
+	}
+}
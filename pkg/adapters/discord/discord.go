@@ -0,0 +1,226 @@
+// Package discord is a Discord webhook output adapter. Messages are
+// posted as embeds within Discord's length and count limits.
+package discord
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"github.com/grokify/commonchat"
+	"github.com/valyala/fasthttp"
+
+	"github.com/grokify/chathooks/pkg/adapters/adapterutil"
+	"github.com/grokify/chathooks/pkg/config"
+)
+
+// Discord limits, in characters. The title, description, field names and
+// values, footer and author name of all embeds count towards
+// `MaxEmbedsTotal`.
+const (
+	MaxContent          = 2000
+	MaxEmbeds           = 10
+	MaxEmbedTitle       = 256
+	MaxEmbedDescription = 4096
+	MaxEmbedFields      = 25
+	MaxFieldName        = 256
+	MaxFieldValue       = 1024
+	MaxAuthorName       = 256
+	MaxFooterText       = 2048
+	MaxEmbedsTotal      = 6000
+
+	// emptyValue is a zero width space, as field names and values cannot
+	// be empty.
+	emptyValue = "\u200b"
+	ellipsis   = "…"
+)
+
+// Message is the webhook request body.
+type Message struct {
+	Content string  `json:"content,omitempty"`
+	Embeds  []Embed `json:"embeds,omitempty"`
+}
+
+// Embed is a message embed.
+type Embed struct {
+	Author      *Author `json:"author,omitempty"`
+	Title       string  `json:"title,omitempty"`
+	Description string  `json:"description,omitempty"`
+	Color       int     `json:"color,omitempty"`
+	Fields      []Field `json:"fields,omitempty"`
+	Thumbnail   *Image  `json:"thumbnail,omitempty"`
+	Footer      *Footer `json:"footer,omitempty"`
+}
+
+type Author struct {
+	Name    string `json:"name"`
+	IconURL string `json:"icon_url,omitempty"`
+}
+
+type Field struct {
+	Name   string `json:"name"`
+	Value  string `json:"value"`
+	Inline bool   `json:"inline,omitempty"`
+}
+
+type Image struct {
+	URL string `json:"url"`
+}
+
+type Footer struct {
+	Text string `json:"text"`
+}
+
+// Convert returns the Discord message for an `outputFormat`. The first
+// embed has the activity as its author and the icon as its thumbnail,
+// followed by an embed per attachment with short fields inline. `nocard`
+// returns the message as text content.
+func Convert(ccMsg commonchat.Message, outputFormat string) Message {
+	if outputFormat == config.ParamNameOutputFormatNocard {
+		return Message{Content: truncate(Text(ccMsg), MaxContent)}
+	}
+	embeds := []Embed{}
+	first := Embed{
+		Title:       strings.TrimSpace(ccMsg.Title),
+		Description: strings.TrimSpace(ccMsg.Text)}
+	if len(first.Title) > 0 || len(first.Description) > 0 || len(ccMsg.Attachments) == 0 {
+		embeds = append(embeds, first)
+	}
+	for _, att := range ccMsg.Attachments {
+		embeds = append(embeds, convertAttachment(att))
+	}
+	if activity := strings.TrimSpace(ccMsg.Activity); len(activity) > 0 {
+		embeds[0].Author = &Author{Name: activity, IconURL: strings.TrimSpace(ccMsg.IconURL)}
+	}
+	if iconURL := strings.TrimSpace(ccMsg.IconURL); len(iconURL) > 0 && embeds[0].Thumbnail == nil {
+		embeds[0].Thumbnail = &Image{URL: iconURL}
+	}
+	return Message{Embeds: Limit(embeds)}
+}
+
+func convertAttachment(att commonchat.Attachment) Embed {
+	desc := []string{}
+	for _, s := range []string{att.Pretext, att.Text} {
+		if s = strings.TrimSpace(s); len(s) > 0 {
+			desc = append(desc, s)
+		}
+	}
+	embed := Embed{
+		Title:       strings.TrimSpace(att.Title),
+		Description: strings.Join(desc, "\n"),
+		Color:       Color(att.Color)}
+	if name := strings.TrimSpace(att.AuthorName); len(name) > 0 {
+		embed.Footer = &Footer{Text: name}
+	}
+	if thumbnail := strings.TrimSpace(att.ThumbnailURL); len(thumbnail) > 0 {
+		embed.Thumbnail = &Image{URL: thumbnail}
+	}
+	for _, field := range att.Fields {
+		name, value := strings.TrimSpace(field.Title), strings.TrimSpace(field.Value)
+		if len(name) == 0 && len(value) == 0 {
+			continue
+		}
+		embed.Fields = append(embed.Fields, Field{Name: name, Value: value, Inline: field.Short})
+	}
+	return embed
+}
+
+// Limit truncates embeds to Discord's limits. Text over a limit is cut
+// with an ellipsis, and fields and embeds past the count limits or the
+// total character limit are dropped.
+func Limit(embeds []Embed) []Embed {
+	if len(embeds) > MaxEmbeds {
+		embeds = embeds[:MaxEmbeds]
+	}
+	budget := MaxEmbedsTotal
+	take := func(s string, maxLen int) string {
+		s = truncate(s, min(maxLen, budget))
+		budget -= utf8.RuneCountInString(s)
+		return s
+	}
+	limited := []Embed{}
+	for _, embed := range embeds {
+		if budget <= 0 {
+			break
+		}
+		if embed.Author != nil {
+			embed.Author.Name = take(embed.Author.Name, MaxAuthorName)
+		}
+		embed.Title = take(embed.Title, MaxEmbedTitle)
+		embed.Description = take(embed.Description, MaxEmbedDescription)
+		if embed.Footer != nil {
+			embed.Footer.Text = take(embed.Footer.Text, MaxFooterText)
+		}
+		if len(embed.Fields) > MaxEmbedFields {
+			embed.Fields = embed.Fields[:MaxEmbedFields]
+		}
+		fields := []Field{}
+		for _, field := range embed.Fields {
+			if budget < 2 {
+				break
+			}
+			field.Name = nonEmpty(take(field.Name, MaxFieldName))
+			field.Value = nonEmpty(take(field.Value, MaxFieldValue))
+			fields = append(fields, field)
+		}
+		embed.Fields = fields
+		limited = append(limited, embed)
+	}
+	return limited
+}
+
+// Text returns the message as Discord markdown text.
+func Text(ccMsg commonchat.Message) string {
+	return strings.Join(adapterutil.MarkdownLines(ccMsg), "\n")
+}
+
+// Color returns the embed color for a hex color or Slack color name.
+func Color(color string) int {
+	rgb, _ := adapterutil.ParseHex(color)
+	return int(rgb)
+}
+
+func truncate(s string, maxLen int) string {
+	if maxLen <= 0 {
+		return ""
+	}
+	if utf8.RuneCountInString(s) <= maxLen {
+		return s
+	}
+	runes := []rune(s)
+	return string(runes[:maxLen-1]) + ellipsis
+}
+
+func nonEmpty(s string) string {
+	if len(s) == 0 {
+		return emptyValue
+	}
+	return s
+}
+
+// Adapter is a `commonchat.Adapter` for Discord webhooks. Rate limited
+// requests, with status `429`, are retried by `adapters.AdapterSet`.
+type Adapter struct {
+	Client     *fasthttp.Client
+	WebhookURL string
+}
+
+// NewAdapter returns an adapter. `webhookURL` is used by `SendMessage`.
+func NewAdapter(webhookURL string) *Adapter {
+	return &Adapter{Client: adapterutil.NewClient(), WebhookURL: webhookURL}
+}
+
+// SendWebhook posts a message to a webhook URL. Discord returns `204 No
+// Content`.
+func (adapter *Adapter) SendWebhook(url string, ccMsg commonchat.Message, discordMsg any, opts map[string]any) (*fasthttp.Request, *fasthttp.Response, error) {
+	return adapterutil.PostJSON(adapter.Client, url,
+		Convert(ccMsg, adapterutil.OutputFormat(opts)), nil)
+}
+
+// SendMessage posts a message to the adapter's webhook URL.
+func (adapter *Adapter) SendMessage(ccMsg commonchat.Message, discordMsg any, opts map[string]any) (*fasthttp.Request, *fasthttp.Response, error) {
+	return adapter.SendWebhook(adapter.WebhookURL, ccMsg, discordMsg, opts)
+}
+
+func (adapter *Adapter) WebhookUID(ctx *fasthttp.RequestCtx) (string, error) {
+	return adapterutil.WebhookUID(ctx)
+}
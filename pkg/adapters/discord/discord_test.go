@@ -0,0 +1,100 @@
+package discord
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/grokify/commonchat"
+
+	"github.com/grokify/chathooks/pkg/config"
+)
+
+var ColorTests = []struct {
+	v    string
+	want int
+}{
+	{"", 0},
+	{"#00ff00", 0x00ff00},
+	{"#F00", 0xff0000},
+	{"danger", 0xa30200},
+	{"blue", 0}}
+
+func TestColor(t *testing.T) {
+	for _, tt := range ColorTests {
+		try := Color(tt.v)
+		if try != tt.want {
+			t.Errorf("Color(%v): want [%v], got [%v]", tt.v, tt.want, try)
+		}
+	}
+}
+
+func TestConvert(t *testing.T) {
+	ccMsg := commonchat.Message{
+		Activity: "Build passed",
+		IconURL:  "https://example.com/icon.png",
+		Attachments: []commonchat.Attachment{{
+			Color: "#00ff00",
+			Text:  "Build #1",
+			Fields: []commonchat.Field{
+				{Title: "Branch", Value: "master", Short: true},
+				{Title: "Message", Value: ""}}}}}
+	msg := Convert(ccMsg, "")
+	if len(msg.Embeds) != 1 {
+		t.Fatalf("Convert(): want [1] embed, got [%v]", msg.Embeds)
+	}
+	embed := msg.Embeds[0]
+	if embed.Author == nil || embed.Author.Name != "Build passed" {
+		t.Errorf("Convert(): want author [Build passed], got [%v]", embed.Author)
+	}
+	if embed.Thumbnail == nil || embed.Thumbnail.URL != ccMsg.IconURL {
+		t.Errorf("Convert(): want thumbnail [%s], got [%v]", ccMsg.IconURL, embed.Thumbnail)
+	}
+	if embed.Color != 0x00ff00 || len(embed.Fields) != 2 || !embed.Fields[0].Inline {
+		t.Errorf("Convert(): want color and inline field, got [%v]", embed)
+	}
+	if embed.Fields[1].Value != emptyValue {
+		t.Errorf("Convert(): want empty value [%q], got [%q]", emptyValue, embed.Fields[1].Value)
+	}
+
+	msg = Convert(ccMsg, config.ParamNameOutputFormatNocard)
+	if len(msg.Embeds) != 0 || !strings.Contains(msg.Content, "**Branch**: master") {
+		t.Errorf("Convert(nocard): want content only, got [%v]", msg)
+	}
+}
+
+func TestLimit(t *testing.T) {
+	long := strings.Repeat("x", 5000)
+	att := commonchat.Attachment{Title: long, Text: long}
+	for i := 0; i < 30; i++ {
+		att.Fields = append(att.Fields, commonchat.Field{Title: "f", Value: long})
+	}
+	ccMsg := commonchat.Message{Activity: "Activity"}
+	for i := 0; i < 12; i++ {
+		ccMsg.Attachments = append(ccMsg.Attachments, att)
+	}
+	msg := Convert(ccMsg, "")
+	total := 0
+	for _, embed := range msg.Embeds {
+		if n := utf8.RuneCountInString(embed.Title); n > MaxEmbedTitle {
+			t.Errorf("Limit(): title [%d] over [%d]", n, MaxEmbedTitle)
+		}
+		if n := utf8.RuneCountInString(embed.Description); n > MaxEmbedDescription {
+			t.Errorf("Limit(): description [%d] over [%d]", n, MaxEmbedDescription)
+		}
+		if len(embed.Fields) > MaxEmbedFields {
+			t.Errorf("Limit(): [%d] fields over [%d]", len(embed.Fields), MaxEmbedFields)
+		}
+		total += utf8.RuneCountInString(embed.Title) + utf8.RuneCountInString(embed.Description)
+		if embed.Author != nil {
+			total += utf8.RuneCountInString(embed.Author.Name)
+		}
+		for _, field := range embed.Fields {
+			total += utf8.RuneCountInString(field.Name) + utf8.RuneCountInString(field.Value)
+		}
+	}
+	if len(msg.Embeds) > MaxEmbeds || total > MaxEmbedsTotal {
+		t.Errorf("Limit(): want at most [%d] embeds and [%d] characters, got [%d] and [%d]",
+			MaxEmbeds, MaxEmbedsTotal, len(msg.Embeds), total)
+	}
+}
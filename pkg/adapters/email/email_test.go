@@ -0,0 +1,201 @@
+package email
+
+import (
+	"bufio"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/http"
+	"net/mail"
+	"net/textproto"
+	"strings"
+	"testing"
+
+	"github.com/grokify/commonchat"
+
+	"github.com/grokify/chathooks/pkg/adapters/adapterutil"
+	"github.com/grokify/chathooks/pkg/models"
+)
+
+var SubjectTests = []struct {
+	v    commonchat.Message
+	want string
+}{
+	{commonchat.Message{Activity: "Build passed", Title: "[Build #1](https://example.com) for **minimal**"}, "Build passed: Build #1 (https://example.com) for minimal"},
+	{commonchat.Message{Activity: "Alert\nopened"}, "Alert opened"},
+	{commonchat.Message{Attachments: []commonchat.Attachment{{Title: "Incident"}}}, "Incident"}}
+
+func TestSubject(t *testing.T) {
+	for _, tt := range SubjectTests {
+		try := Subject(tt.v)
+		if try != tt.want {
+			t.Errorf("Subject(%v): want [%v], got [%v]", tt.v, tt.want, try)
+		}
+	}
+}
+
+func TestHTML(t *testing.T) {
+	ccMsg := commonchat.Message{
+		Activity: "Build <passed>",
+		Attachments: []commonchat.Attachment{{
+			Color:  "good",
+			Fields: []commonchat.Field{{Title: "Branch", Value: "master"}}}}}
+	try := HTML(ccMsg)
+	for _, want := range []string{
+		`<div style="height:6px;background-color:#2eb886;"></div>`,
+		"Build &lt;passed&gt;",
+		">Branch</th>",
+		">master</td>"} {
+		if !strings.Contains(try, want) {
+			t.Errorf("HTML(): want [%v], got [%v]", want, try)
+		}
+	}
+}
+
+// smtpServer is an in-process SMTP server that accepts one email.
+type smtpServer struct {
+	listener net.Listener
+	from     string
+	rcpts    []string
+	data     string
+	done     chan struct{}
+}
+
+func newSMTPServer(t *testing.T) *smtpServer {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := &smtpServer{listener: listener, done: make(chan struct{})}
+	go srv.serve()
+	return srv
+}
+
+func (srv *smtpServer) URL() string {
+	return "smtp://" + srv.listener.Addr().String()
+}
+
+func (srv *smtpServer) serve() {
+	defer close(srv.done)
+	conn, err := srv.listener.Accept()
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+	tp := textproto.NewConn(conn)
+	_ = tp.PrintfLine("220 localhost ESMTP")
+	for {
+		line, err := tp.ReadLine()
+		if err != nil {
+			return
+		}
+		cmd, arg, _ := strings.Cut(line, " ")
+		switch strings.ToUpper(cmd) {
+		case "EHLO", "HELO":
+			_ = tp.PrintfLine("250-localhost\r\n250 8BITMIME")
+		case "MAIL":
+			from, _, _ := strings.Cut(strings.TrimPrefix(arg, "FROM:"), " ")
+			srv.from = strings.Trim(from, "<>")
+			_ = tp.PrintfLine("250 OK")
+		case "RCPT":
+			srv.rcpts = append(srv.rcpts, strings.Trim(strings.TrimPrefix(arg, "TO:"), "<>"))
+			_ = tp.PrintfLine("250 OK")
+		case "DATA":
+			_ = tp.PrintfLine("354 Go ahead")
+			data, err := tp.ReadDotBytes()
+			if err != nil {
+				return
+			}
+			srv.data = string(data)
+			_ = tp.PrintfLine("250 OK")
+		case "QUIT":
+			_ = tp.PrintfLine("221 Bye")
+			return
+		default:
+			_ = tp.PrintfLine("502 Not implemented")
+		}
+	}
+}
+
+func TestSendMessage(t *testing.T) {
+	srv := newSMTPServer(t)
+	defer srv.listener.Close()
+
+	adapter := NewAdapter(srv.URL())
+	adapter.From = "Chathooks <chathooks@example.com>"
+	adapter.To = []string{"eng@example.com"}
+	adapter.Cc = []string{"Leads <leads@example.com>"}
+	ccMsg := commonchat.Message{
+		Activity: "Build passed",
+		Title:    "minimal/master",
+		Attachments: []commonchat.Attachment{{
+			Color:  "#ff0000",
+			Fields: []commonchat.Field{{Title: "Branch", Value: "master"}}}}}
+	_, res, err := adapter.SendMessage(ccMsg, nil, map[string]any{
+		adapterutil.OptionHookData: models.HookData{Recipients: []string{"ops@example.com, support@example.com"}}})
+	if err != nil {
+		t.Fatalf("SendMessage(): error [%v]", err)
+	}
+	<-srv.done
+	if res.StatusCode() != http.StatusAccepted {
+		t.Errorf("SendMessage(): want status [%d], got [%d]", http.StatusAccepted, res.StatusCode())
+	}
+	wantRcpts := "ops@example.com,support@example.com,leads@example.com"
+	if srv.from != "chathooks@example.com" || strings.Join(srv.rcpts, ",") != wantRcpts {
+		t.Errorf("SendMessage(): want from [chathooks@example.com] recipients [%v], got [%v] [%v]", wantRcpts, srv.from, srv.rcpts)
+	}
+
+	msg, err := mail.ReadMessage(strings.NewReader(srv.data))
+	if err != nil {
+		t.Fatalf("SendMessage(): invalid email [%v]", err)
+	}
+	subject, _ := new(mime.WordDecoder).DecodeHeader(msg.Header.Get("Subject"))
+	if subject != "Build passed: minimal/master" || msg.Header.Get("To") != "<ops@example.com>, <support@example.com>" {
+		t.Errorf("SendMessage(): want subject and route recipients, got [%v] [%v]", subject, msg.Header.Get("To"))
+	}
+	mediaType, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	if err != nil || mediaType != "multipart/alternative" {
+		t.Fatalf("SendMessage(): want multipart/alternative, got [%v] [%v]", mediaType, err)
+	}
+	parts := map[string]string{}
+	mr := multipart.NewReader(msg.Body, params["boundary"])
+	for {
+		part, err := mr.NextPart()
+		if err != nil {
+			break
+		}
+		body, _ := io.ReadAll(quotedprintable.NewReader(bufio.NewReader(part)))
+		contentType, _, _ := mime.ParseMediaType(part.Header.Get("Content-Type"))
+		parts[contentType] = string(body)
+	}
+	if !strings.Contains(parts["text/plain"], "Branch: master") ||
+		!strings.Contains(parts["text/html"], "background-color:#ff0000") {
+		t.Errorf("SendMessage(): want text and html parts, got [%v]", parts)
+	}
+}
+
+var SendMessageErrorTests = []struct {
+	serverURL string
+	from      string
+	to        []string
+	want      error
+}{
+	{"", "chathooks@example.com", []string{"ops@example.com"}, ErrServerNotSet},
+	{"smtp://127.0.0.1:1", "", []string{"ops@example.com"}, ErrFromNotSet},
+	{"smtp://127.0.0.1:1", "chathooks@example.com", nil, ErrRecipientsNotSet}}
+
+func TestSendMessageErrors(t *testing.T) {
+	for _, tt := range SendMessageErrorTests {
+		adapter := NewAdapter(tt.serverURL)
+		adapter.From, adapter.To = tt.from, tt.to
+		if _, _, err := adapter.SendMessage(commonchat.Message{}, nil, nil); err != tt.want {
+			t.Errorf("SendMessage(%v): want error [%v], got [%v]", tt.serverURL, tt.want, err)
+		}
+	}
+	adapter := NewAdapter("smtp://127.0.0.1:1")
+	if _, _, err := adapter.SendWebhook("smtp://relay.example.com:25", commonchat.Message{}, nil, nil); err != ErrServerNotSet {
+		t.Errorf("SendWebhook(): want error [%v], got [%v]", ErrServerNotSet, err)
+	}
+}
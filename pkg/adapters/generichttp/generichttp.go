@@ -0,0 +1,338 @@
+// Package generichttp is an output adapter for any HTTP service, such as
+// an internal API. The method, headers and body are rendered from
+// templates over the message and hook data, and the body can be signed
+// with an HMAC.
+package generichttp
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha1" //nolint:gosec // supported for services that verify SHA-1 signatures
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"hash"
+	"net/http"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/grokify/commonchat"
+	hum "github.com/grokify/mogo/net/http/httputilmore"
+	"github.com/valyala/fasthttp"
+
+	"github.com/grokify/chathooks/pkg/adapters/adapterutil"
+	"github.com/grokify/chathooks/pkg/config"
+	"github.com/grokify/chathooks/pkg/models"
+	"github.com/grokify/chathooks/pkg/redact"
+)
+
+// Adapter configuration options.
+const (
+	OptionMethod        = "method"
+	OptionHeaders       = "headers"
+	OptionBody          = "body"
+	OptionHMACSecret    = "hmacSecret"
+	OptionHMACHeader    = "hmacHeader"
+	OptionHMACAlgorithm = "hmacAlgorithm"
+	OptionSuccessCodes  = "successCodes"
+)
+
+const (
+	// DefaultBody posts the message as JSON.
+	DefaultBody       = "{{json .Message}}"
+	DefaultHMACHeader = "X-Chathooks-Signature"
+
+	AlgorithmSHA1   = "sha1"
+	AlgorithmSHA256 = "sha256"
+	AlgorithmSHA512 = "sha512"
+)
+
+// Data is the data templates are evaluated against. `HookData` and
+// `Params` are redacted so templates cannot forward the token or output
+// URLs.
+type Data struct {
+	Message   commonchat.Message
+	HookData  models.HookData
+	InputType string
+	Params    map[string]string
+}
+
+// Request is a rendered request.
+type Request struct {
+	Method  string            `json:"method"`
+	Headers map[string]string `json:"headers,omitempty"`
+	Body    string            `json:"body"`
+}
+
+// Config is the adapter configuration. `Method`, `Headers` values and
+// `Body` are `text/template` templates with a `json` function.
+type Config struct {
+	Method        string
+	Headers       map[string]string
+	Body          string
+	HMACSecret    string
+	HMACHeader    string
+	HMACAlgorithm string
+	// SuccessCodes are the status codes treated as success. Empty means
+	// `2xx`.
+	SuccessCodes []int
+}
+
+// NewConfig returns the configuration for adapter options.
+func NewConfig(opts map[string]any) (Config, error) {
+	cfg := Config{
+		Method:        adapterutil.OptionString(opts, OptionMethod),
+		Headers:       map[string]string{},
+		Body:          adapterutil.OptionString(opts, OptionBody),
+		HMACSecret:    adapterutil.OptionString(opts, OptionHMACSecret),
+		HMACHeader:    adapterutil.OptionString(opts, OptionHMACHeader),
+		HMACAlgorithm: adapterutil.OptionString(opts, OptionHMACAlgorithm)}
+	switch headers := opts[OptionHeaders].(type) {
+	case nil:
+	case map[string]string:
+		for k, v := range headers {
+			cfg.Headers[k] = v
+		}
+	case map[string]any:
+		for k, v := range headers {
+			cfg.Headers[k] = fmt.Sprint(v)
+		}
+	default:
+		return cfg, fmt.Errorf("option `%s` is not a map", OptionHeaders)
+	}
+	codes, err := statusCodes(opts[OptionSuccessCodes])
+	if err != nil {
+		return cfg, err
+	}
+	cfg.SuccessCodes = codes
+	return cfg, nil
+}
+
+// statusCodes parses a list of status codes, as decoded from YAML or
+// JSON, or a comma-delimited string.
+func statusCodes(v any) ([]int, error) {
+	var items []any
+	switch t := v.(type) {
+	case nil:
+		return nil, nil
+	case string:
+		for _, s := range strings.Split(t, ",") {
+			if s = strings.TrimSpace(s); len(s) > 0 {
+				items = append(items, s)
+			}
+		}
+	case []int:
+		return t, nil
+	case []any:
+		items = t
+	default:
+		return nil, fmt.Errorf("option `%s` is not a list", OptionSuccessCodes)
+	}
+	codes := []int{}
+	for _, item := range items {
+		code := 0
+		switch t := item.(type) {
+		case int:
+			code = t
+		case float64:
+			code = int(t)
+		case string:
+			code, _ = strconv.Atoi(strings.TrimSpace(t))
+		}
+		if code < 100 || code > 599 {
+			return nil, fmt.Errorf("option `%s` has invalid status code [%v]", OptionSuccessCodes, item)
+		}
+		codes = append(codes, code)
+	}
+	return codes, nil
+}
+
+var funcMap = template.FuncMap{
+	"json": func(v any) (string, error) {
+		bytes, err := json.Marshal(v)
+		return string(bytes), err
+	}}
+
+func parse(name, text string) (*template.Template, error) {
+	t, err := template.New(name).Funcs(funcMap).Option("missingkey=zero").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("http adapter %s template: %w", name, err)
+	}
+	return t, nil
+}
+
+func execute(t *template.Template, data Data) (string, error) {
+	var buf bytes.Buffer
+	if err := t.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("http adapter %s template: %w", t.Name(), err)
+	}
+	return buf.String(), nil
+}
+
+// Sign returns the HMAC of `body` as `<algorithm>=<hex>`, as GitHub
+// signs webhooks.
+func Sign(algorithm, secret string, body []byte) (string, error) {
+	algorithm = strings.ToLower(strings.TrimSpace(algorithm))
+	var fn func() hash.Hash
+	switch algorithm {
+	case "", AlgorithmSHA256:
+		algorithm, fn = AlgorithmSHA256, sha256.New
+	case AlgorithmSHA1:
+		fn = sha1.New
+	case AlgorithmSHA512:
+		fn = sha512.New
+	default:
+		return "", fmt.Errorf("unknown hmac algorithm [%s]", algorithm)
+	}
+	mac := hmac.New(fn, []byte(secret))
+	mac.Write(body)
+	return algorithm + "=" + hex.EncodeToString(mac.Sum(nil)), nil
+}
+
+// Adapter is a `commonchat.Adapter` for HTTP services.
+type Adapter struct {
+	Client     *fasthttp.Client
+	WebhookURL string
+	Config     Config
+	method     *template.Template
+	headers    map[string]*template.Template
+	body       *template.Template
+}
+
+// NewAdapter returns an adapter posting the message as JSON. `webhookURL`
+// is used by `SendMessage`.
+func NewAdapter(webhookURL string) *Adapter {
+	adapter, _ := NewAdapterConfig(webhookURL, Config{})
+	return adapter
+}
+
+// NewAdapterConfig returns an adapter for a configuration. Templates are
+// parsed and the signing algorithm checked.
+func NewAdapterConfig(webhookURL string, cfg Config) (*Adapter, error) {
+	if len(strings.TrimSpace(cfg.Method)) == 0 {
+		cfg.Method = http.MethodPost
+	}
+	if len(strings.TrimSpace(cfg.Body)) == 0 {
+		cfg.Body = DefaultBody
+	}
+	if len(strings.TrimSpace(cfg.HMACHeader)) == 0 {
+		cfg.HMACHeader = DefaultHMACHeader
+	}
+	if _, err := Sign(cfg.HMACAlgorithm, "", nil); err != nil {
+		return nil, err
+	}
+	adapter := &Adapter{
+		Client:     adapterutil.NewClient(),
+		WebhookURL: webhookURL,
+		Config:     cfg,
+		headers:    map[string]*template.Template{}}
+	var err error
+	if adapter.method, err = parse(OptionMethod, cfg.Method); err != nil {
+		return nil, err
+	}
+	if adapter.body, err = parse(OptionBody, cfg.Body); err != nil {
+		return nil, err
+	}
+	for name, text := range cfg.Headers {
+		if adapter.headers[name], err = parse("header "+name, text); err != nil {
+			return nil, err
+		}
+	}
+	return adapter, nil
+}
+
+// redactParams returns a copy of `params` with secret values redacted.
+func redactParams(params map[string]string) map[string]string {
+	out := map[string]string{}
+	for name, value := range params {
+		if redact.IsParam(name) {
+			value = redact.String(value)
+		}
+		out[name] = value
+	}
+	return out
+}
+
+// Request renders the request for a message and adapter options, from
+// `adapters.HookDataOptions`. The body is signed when `HMACSecret` is set.
+func (adapter *Adapter) Request(ccMsg commonchat.Message, opts map[string]any) (Request, error) {
+	data := Data{
+		Message:   ccMsg,
+		HookData:  adapterutil.OptionHookDataValue(opts).Redacted(),
+		InputType: adapterutil.OptionString(opts, config.ParamNameInputType),
+		Params:    redactParams(adapterutil.OptionParamsMap(opts))}
+	req := Request{Headers: map[string]string{}}
+	method, err := execute(adapter.method, data)
+	if err != nil {
+		return req, err
+	}
+	req.Method = strings.ToUpper(strings.TrimSpace(method))
+	if req.Body, err = execute(adapter.body, data); err != nil {
+		return req, err
+	}
+	hasContentType := false
+	for name, t := range adapter.headers {
+		if req.Headers[name], err = execute(t, data); err != nil {
+			return req, err
+		}
+		hasContentType = hasContentType || strings.EqualFold(name, hum.HeaderContentType)
+	}
+	if !hasContentType {
+		req.Headers[hum.HeaderContentType] = hum.ContentTypeAppJSONUtf8
+	}
+	if len(adapter.Config.HMACSecret) > 0 {
+		signature, err := Sign(adapter.Config.HMACAlgorithm, adapter.Config.HMACSecret, []byte(req.Body))
+		if err != nil {
+			return req, err
+		}
+		req.Headers[adapter.Config.HMACHeader] = signature
+	}
+	return req, nil
+}
+
+// SuccessStatus returns true if the status is in `SuccessCodes`, or is
+// `2xx` when none are configured.
+func (adapter *Adapter) SuccessStatus(statusCode int) bool {
+	if len(adapter.Config.SuccessCodes) == 0 {
+		return statusCode >= 200 && statusCode < 300
+	}
+	for _, code := range adapter.Config.SuccessCodes {
+		if code == statusCode {
+			return true
+		}
+	}
+	return false
+}
+
+// SendWebhook sends the rendered request to a URL.
+func (adapter *Adapter) SendWebhook(url string, ccMsg commonchat.Message, httpMsg any, opts map[string]any) (*fasthttp.Request, *fasthttp.Response, error) {
+	rendered, err := adapter.Request(ccMsg, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+	req := fasthttp.AcquireRequest()
+	res := fasthttp.AcquireResponse()
+	req.Header.SetRequestURI(url)
+	req.Header.SetMethod(rendered.Method)
+	for name, value := range rendered.Headers {
+		req.Header.Set(name, value)
+	}
+	req.SetBodyString(rendered.Body)
+	client := adapter.Client
+	if client == nil {
+		client = adapterutil.NewClient()
+	}
+	return req, res, client.Do(req, res)
+}
+
+// SendMessage sends the rendered request to the adapter's URL.
+func (adapter *Adapter) SendMessage(ccMsg commonchat.Message, httpMsg any, opts map[string]any) (*fasthttp.Request, *fasthttp.Response, error) {
+	return adapter.SendWebhook(adapter.WebhookURL, ccMsg, httpMsg, opts)
+}
+
+func (adapter *Adapter) WebhookUID(ctx *fasthttp.RequestCtx) (string, error) {
+	return adapterutil.WebhookUID(ctx)
+}
@@ -0,0 +1,111 @@
+package generichttp
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/grokify/commonchat"
+
+	"github.com/grokify/chathooks/pkg/adapters/adapterutil"
+	"github.com/grokify/chathooks/pkg/config"
+	"github.com/grokify/chathooks/pkg/models"
+	"github.com/grokify/chathooks/pkg/redact"
+)
+
+var SignTests = []struct {
+	algorithm string
+	want      string
+}{
+	{"", "sha256=f7bc83f430538424b13298e6aa6fb143ef4d59a14946175997479dbc2d1a3cd8"},
+	{"sha1", "sha1=de7c9b85b8b78aa6bc8a7a36f70a90701c9db4d9"}}
+
+func TestSign(t *testing.T) {
+	for _, tt := range SignTests {
+		try, err := Sign(tt.algorithm, "key", []byte("The quick brown fox jumps over the lazy dog"))
+		if err != nil {
+			t.Errorf("Sign(%v): error [%v]", tt.algorithm, err)
+		}
+		if try != tt.want {
+			t.Errorf("Sign(%v): want [%v], got [%v]", tt.algorithm, tt.want, try)
+		}
+	}
+	if _, err := Sign("md5", "key", nil); err == nil {
+		t.Errorf("Sign(md5): want error, got [nil]")
+	}
+}
+
+var NewConfigTests = []struct {
+	successCodes any
+	want         []int
+	wantErr      bool
+}{
+	{nil, nil, false},
+	{[]any{200, 409}, []int{200, 409}, false},
+	{[]any{float64(201)}, []int{201}, false},
+	{"200, 202", []int{200, 202}, false},
+	{[]any{"abc"}, nil, true}}
+
+func TestNewConfig(t *testing.T) {
+	for _, tt := range NewConfigTests {
+		cfg, err := NewConfig(map[string]any{OptionSuccessCodes: tt.successCodes})
+		if (err != nil) != tt.wantErr {
+			t.Errorf("NewConfig(%v): want error [%v], got [%v]", tt.successCodes, tt.wantErr, err)
+		}
+		if !tt.wantErr && len(cfg.SuccessCodes) != len(tt.want) {
+			t.Errorf("NewConfig(%v): want [%v], got [%v]", tt.successCodes, tt.want, cfg.SuccessCodes)
+		}
+	}
+}
+
+func TestRequest(t *testing.T) {
+	adapter, err := NewAdapterConfig("", Config{
+		Method:  "{{.Params.method}}",
+		Headers: map[string]string{"X-Source": "{{.InputType}}"},
+		Body:    `{"title": {{json .Message.Activity}}, "route": {{json .HookData.Route}}, "token": {{json .HookData.Token}}, "param": {{json .Params.token}}}`})
+	if err != nil {
+		t.Fatal(err)
+	}
+	req, err := adapter.Request(commonchat.Message{Activity: `Build "1" passed`}, map[string]any{
+		config.ParamNameInputType:  "travisci",
+		adapterutil.OptionParams:   map[string]string{"method": "put", "token": "secret-token"},
+		adapterutil.OptionHookData: models.HookData{Route: "builds", Token: "secret-token"}})
+	if err != nil {
+		t.Fatalf("Request(): error [%v]", err)
+	}
+	wantBody := `{"title": "Build \"1\" passed", "route": "builds", "token": "` + redact.String("secret-token") +
+		`", "param": "` + redact.String("secret-token") + `"}`
+	if req.Method != http.MethodPut || req.Headers["X-Source"] != "travisci" || req.Body != wantBody {
+		t.Errorf("Request(): want [PUT] [travisci] [%v], got [%v]", wantBody, req)
+	}
+	if _, err := NewAdapterConfig("", Config{Body: "{{.Message"}); err == nil {
+		t.Errorf("NewAdapterConfig({{.Message): want error, got [nil]")
+	}
+}
+
+func TestSendMessage(t *testing.T) {
+	var gotSignature, gotBody string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		gotSignature, gotBody = r.Header.Get(DefaultHMACHeader), string(body)
+		w.WriteHeader(http.StatusConflict)
+	}))
+	defer srv.Close()
+
+	adapter, err := NewAdapterConfig(srv.URL, Config{HMACSecret: "secret", SuccessCodes: []int{200, 409}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, res, err := adapter.SendMessage(commonchat.Message{Activity: "Build passed"}, nil, nil)
+	if err != nil {
+		t.Fatalf("SendMessage(): error [%v]", err)
+	}
+	if !adapter.SuccessStatus(res.StatusCode()) {
+		t.Errorf("SendMessage(): want success status, got [%d]", res.StatusCode())
+	}
+	wantSignature, _ := Sign(AlgorithmSHA256, "secret", []byte(gotBody))
+	if gotSignature != wantSignature || gotBody != `{"activity":"Build passed"}` {
+		t.Errorf("SendMessage(): want signed message, got [%v] [%v]", gotSignature, gotBody)
+	}
+}
@@ -0,0 +1,38 @@
+package adapters
+
+import (
+	"github.com/grokify/commonchat"
+	ccglip "github.com/grokify/commonchat/glip"
+	"github.com/grokify/commonchat/glip/config"
+	"github.com/valyala/fasthttp"
+)
+
+// glipAdapter is a `ccglip.GlipAdapter` that keeps its fasthttp client
+// when options are applied. `GlipAdapter.SendWebhook` applies options by
+// building a new adapter, which `NewGlipAdapter` leaves without a client.
+type glipAdapter struct {
+	*ccglip.GlipAdapter
+}
+
+func newGlipAdapter(webhookURLOrUID string, cfg *config.ConverterConfig) glipAdapter {
+	adapter := ccglip.NewGlipAdapter(webhookURLOrUID, cfg)
+	adapter.GlipClient.FastClient = &fasthttp.Client{}
+	return glipAdapter{GlipAdapter: adapter}
+}
+
+func (adapter glipAdapter) SendWebhook(urlOrUID string, ccMsg commonchat.Message, glipmsg any, opts map[string]any) (*fasthttp.Request, *fasthttp.Response, error) {
+	if len(opts) == 0 {
+		return adapter.GlipAdapter.SendWebhook(urlOrUID, ccMsg, glipmsg, opts)
+	}
+	cfg, err := adapter.CommonConverter.Config.UpsertMSI(opts)
+	if err != nil {
+		return nil, nil, err
+	}
+	withOpts := ccglip.NewGlipAdapter(adapter.WebhookURLOrUID, cfg)
+	withOpts.GlipClient.FastClient = adapter.GlipClient.FastClient
+	return withOpts.SendWebhook(urlOrUID, ccMsg, glipmsg, nil)
+}
+
+func (adapter glipAdapter) SendMessage(ccMsg commonchat.Message, glipmsg any, opts map[string]any) (*fasthttp.Request, *fasthttp.Response, error) {
+	return adapter.SendWebhook(adapter.WebhookURLOrUID, ccMsg, glipmsg, opts)
+}
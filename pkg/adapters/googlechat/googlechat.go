@@ -0,0 +1,224 @@
+// Package googlechat is a Google Chat incoming webhook output adapter.
+// Messages are posted as Cards v2.
+package googlechat
+
+import (
+	"html"
+	"strings"
+
+	"github.com/grokify/commonchat"
+	"github.com/valyala/fasthttp"
+
+	"github.com/grokify/chathooks/pkg/adapters/adapterutil"
+	"github.com/grokify/chathooks/pkg/config"
+)
+
+const (
+	CardID          = "chathooks"
+	ImageTypeCircle = "CIRCLE"
+)
+
+// Message is the webhook request body.
+type Message struct {
+	Text    string   `json:"text,omitempty"`
+	CardsV2 []CardV2 `json:"cardsV2,omitempty"`
+}
+
+type CardV2 struct {
+	CardID string `json:"cardId"`
+	Card   Card   `json:"card"`
+}
+
+type Card struct {
+	Header   *CardHeader `json:"header,omitempty"`
+	Sections []Section   `json:"sections,omitempty"`
+}
+
+type CardHeader struct {
+	Title     string `json:"title"`
+	Subtitle  string `json:"subtitle,omitempty"`
+	ImageURL  string `json:"imageUrl,omitempty"`
+	ImageType string `json:"imageType,omitempty"`
+}
+
+type Section struct {
+	Header  string   `json:"header,omitempty"`
+	Widgets []Widget `json:"widgets"`
+}
+
+// Widget is a card widget. One of its properties is set.
+type Widget struct {
+	TextParagraph *TextParagraph `json:"textParagraph,omitempty"`
+	DecoratedText *DecoratedText `json:"decoratedText,omitempty"`
+	ButtonList    *ButtonList    `json:"buttonList,omitempty"`
+	Image         *Image         `json:"image,omitempty"`
+}
+
+type TextParagraph struct {
+	Text string `json:"text"`
+}
+
+type DecoratedText struct {
+	TopLabel string `json:"topLabel,omitempty"`
+	Text     string `json:"text"`
+	WrapText bool   `json:"wrapText"`
+}
+
+type ButtonList struct {
+	Buttons []Button `json:"buttons"`
+}
+
+type Button struct {
+	Text    string  `json:"text"`
+	OnClick OnClick `json:"onClick"`
+}
+
+type OnClick struct {
+	OpenLink OpenLink `json:"openLink"`
+}
+
+type OpenLink struct {
+	URL string `json:"url"`
+}
+
+type Image struct {
+	ImageURL string `json:"imageUrl"`
+	AltText  string `json:"altText,omitempty"`
+}
+
+// Convert returns the Google Chat message for an `outputFormat`. The card
+// header has the icon and activity, with a section per attachment with
+// its text, a decorated text widget per field and a button per link.
+// `nocard` returns text with Google Chat markup.
+func Convert(ccMsg commonchat.Message, outputFormat string) Message {
+	if outputFormat == config.ParamNameOutputFormatNocard {
+		return Message{Text: Text(ccMsg)}
+	}
+	card := Card{Sections: []Section{}}
+	activity, title := strings.TrimSpace(ccMsg.Activity), strings.TrimSpace(ccMsg.Title)
+	if len(activity) == 0 {
+		activity, title = title, ""
+	}
+	if len(activity) > 0 {
+		card.Header = &CardHeader{
+			Title:    activity,
+			Subtitle: title,
+			ImageURL: strings.TrimSpace(ccMsg.IconURL)}
+		if len(card.Header.ImageURL) > 0 {
+			card.Header.ImageType = ImageTypeCircle
+		}
+	}
+	if text := strings.TrimSpace(ccMsg.Text); len(text) > 0 {
+		card.Sections = append(card.Sections, Section{Widgets: []Widget{
+			{TextParagraph: &TextParagraph{Text: HTML(text)}}}})
+	}
+	for _, att := range ccMsg.Attachments {
+		if section, ok := attachmentSection(att); ok {
+			card.Sections = append(card.Sections, section)
+		}
+	}
+	return Message{CardsV2: []CardV2{{CardID: CardID, Card: card}}}
+}
+
+func attachmentSection(att commonchat.Attachment) (Section, bool) {
+	section := Section{
+		Header:  HTML(strings.TrimSpace(att.Title)),
+		Widgets: []Widget{}}
+	for _, s := range []string{att.Pretext, att.Text} {
+		if s = strings.TrimSpace(s); len(s) > 0 {
+			section.Widgets = append(section.Widgets, Widget{TextParagraph: &TextParagraph{Text: HTML(s)}})
+		}
+	}
+	for _, field := range att.Fields {
+		label, value := strings.TrimSpace(field.Title), strings.TrimSpace(field.Value)
+		if len(label) == 0 && len(value) == 0 {
+			continue
+		}
+		section.Widgets = append(section.Widgets, Widget{DecoratedText: &DecoratedText{
+			TopLabel: html.EscapeString(label),
+			Text:     HTML(value),
+			WrapText: true}})
+	}
+	if thumbnail := strings.TrimSpace(att.ThumbnailURL); len(thumbnail) > 0 {
+		section.Widgets = append(section.Widgets, Widget{Image: &Image{ImageURL: thumbnail}})
+	}
+	if buttons := linkButtons(att); len(buttons) > 0 {
+		section.Widgets = append(section.Widgets, Widget{ButtonList: &ButtonList{Buttons: buttons}})
+	}
+	if len(section.Widgets) == 0 {
+		return section, false
+	}
+	return section, true
+}
+
+// linkButtons returns a button for the author link and each markdown link
+// in the attachment text.
+func linkButtons(att commonchat.Attachment) []Button {
+	buttons := []Button{}
+	seen := map[string]bool{}
+	add := func(text, url string) {
+		text, url = strings.TrimSpace(text), strings.TrimSpace(url)
+		if len(url) == 0 || seen[url] {
+			return
+		}
+		if len(text) == 0 {
+			text = url
+		}
+		seen[url] = true
+		buttons = append(buttons, Button{Text: text, OnClick: OnClick{OpenLink: OpenLink{URL: url}}})
+	}
+	add(att.AuthorName, att.AuthorLink)
+	for _, m := range adapterutil.RxLink.FindAllStringSubmatch(att.Text, -1) {
+		add(strings.ReplaceAll(m[1], "**", ""), m[2])
+	}
+	return buttons
+}
+
+// HTML converts chathooks markdown to the HTML supported by card text:
+// bold, links and line breaks. Cards do not support `<strong>`.
+func HTML(s string) string {
+	return adapterutil.MarkdownHTMLBold(s, "b")
+}
+
+// Markup converts chathooks markdown to Google Chat text markup, which
+// uses `*bold*` and `<url|text>` links.
+func Markup(s string) string {
+	s = adapterutil.RxLink.ReplaceAllString(s, "<$2|$1>")
+	return adapterutil.RxBold.ReplaceAllString(s, "*$1*")
+}
+
+// Text returns the message as Google Chat text.
+func Text(ccMsg commonchat.Message) string {
+	lines := adapterutil.MarkdownLines(ccMsg)
+	for i, line := range lines {
+		lines[i] = Markup(line)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Adapter is a `commonchat.Adapter` for Google Chat incoming webhooks.
+type Adapter struct {
+	Client     *fasthttp.Client
+	WebhookURL string
+}
+
+// NewAdapter returns an adapter. `webhookURL` is used by `SendMessage`.
+func NewAdapter(webhookURL string) *Adapter {
+	return &Adapter{Client: adapterutil.NewClient(), WebhookURL: webhookURL}
+}
+
+// SendWebhook posts a message to a webhook URL, which includes the space
+// key and token.
+func (adapter *Adapter) SendWebhook(url string, ccMsg commonchat.Message, chatMsg any, opts map[string]any) (*fasthttp.Request, *fasthttp.Response, error) {
+	return adapterutil.PostJSON(adapter.Client, url,
+		Convert(ccMsg, adapterutil.OutputFormat(opts)), nil)
+}
+
+// SendMessage posts a message to the adapter's webhook URL.
+func (adapter *Adapter) SendMessage(ccMsg commonchat.Message, chatMsg any, opts map[string]any) (*fasthttp.Request, *fasthttp.Response, error) {
+	return adapter.SendWebhook(adapter.WebhookURL, ccMsg, chatMsg, opts)
+}
+
+func (adapter *Adapter) WebhookUID(ctx *fasthttp.RequestCtx) (string, error) {
+	return adapterutil.WebhookUID(ctx)
+}
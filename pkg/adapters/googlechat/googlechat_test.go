@@ -0,0 +1,76 @@
+package googlechat
+
+import (
+	"testing"
+
+	"github.com/grokify/commonchat"
+
+	"github.com/grokify/chathooks/pkg/config"
+)
+
+var HTMLTests = []struct {
+	v    string
+	want string
+}{
+	{"**minimal/master** passed", "<b>minimal/master</b> passed"},
+	{"[Build #1](https://example.com/builds/1?a=1&b=2)", `<a href="https://example.com/builds/1?a=1&amp;b=2">Build #1</a>`},
+	{"a < b\nc", "a &lt; b<br>c"}}
+
+func TestHTML(t *testing.T) {
+	for _, tt := range HTMLTests {
+		try := HTML(tt.v)
+		if try != tt.want {
+			t.Errorf("HTML(%v): want [%v], got [%v]", tt.v, tt.want, try)
+		}
+	}
+}
+
+var MarkupTests = []struct {
+	v    string
+	want string
+}{
+	{"**bold** [link](https://example.com)", "*bold* <https://example.com|link>"},
+	{"plain", "plain"}}
+
+func TestMarkup(t *testing.T) {
+	for _, tt := range MarkupTests {
+		try := Markup(tt.v)
+		if try != tt.want {
+			t.Errorf("Markup(%v): want [%v], got [%v]", tt.v, tt.want, try)
+		}
+	}
+}
+
+func TestConvert(t *testing.T) {
+	ccMsg := commonchat.Message{
+		Activity: "Build passed",
+		IconURL:  "https://example.com/icon.png",
+		Attachments: []commonchat.Attachment{{
+			Text: "[Build #1](https://example.com/builds/1) passed",
+			Fields: []commonchat.Field{
+				{Title: "Branch", Value: "master", Short: true}}}}}
+	msg := Convert(ccMsg, "")
+	if len(msg.CardsV2) != 1 {
+		t.Fatalf("Convert(): want [1] card, got [%v]", msg)
+	}
+	card := msg.CardsV2[0].Card
+	if card.Header == nil || card.Header.Title != "Build passed" || card.Header.ImageURL != ccMsg.IconURL {
+		t.Errorf("Convert(): want header with activity and icon, got [%v]", card.Header)
+	}
+	if len(card.Sections) != 1 || len(card.Sections[0].Widgets) != 3 {
+		t.Fatalf("Convert(): want [1] section with text, field and button widgets, got [%v]", card.Sections)
+	}
+	widgets := card.Sections[0].Widgets
+	if widgets[1].DecoratedText == nil || widgets[1].DecoratedText.TopLabel != "Branch" {
+		t.Errorf("Convert(): want field widget, got [%v]", widgets[1])
+	}
+	if widgets[2].ButtonList == nil || widgets[2].ButtonList.Buttons[0].OnClick.OpenLink.URL != "https://example.com/builds/1" {
+		t.Errorf("Convert(): want link button, got [%v]", widgets[2])
+	}
+
+	msg = Convert(ccMsg, config.ParamNameOutputFormatNocard)
+	want := "*Build passed*\n<https://example.com/builds/1|Build #1> passed\n*Branch*: master"
+	if len(msg.CardsV2) != 0 || msg.Text != want {
+		t.Errorf("Convert(nocard): want [%q], got [%q]", want, msg.Text)
+	}
+}
@@ -0,0 +1,222 @@
+// Package matrix is a Matrix output adapter. Messages are sent to a room
+// with the client-server API as `m.notice` events with a plain text
+// `body` and an HTML `formatted_body`.
+package matrix
+
+import (
+	"errors"
+	"fmt"
+	"html"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/grokify/commonchat"
+	hum "github.com/grokify/mogo/net/http/httputilmore"
+	"github.com/valyala/fasthttp"
+
+	"github.com/grokify/chathooks/pkg/adapters/adapterutil"
+	"github.com/grokify/chathooks/pkg/config"
+)
+
+const (
+	MsgTypeNotice = "m.notice"
+	FormatHTML    = "org.matrix.custom.html"
+
+	// OptionRoomID and OptionAccessToken are adapter configuration
+	// options. The access token can also be set with the `access_token`
+	// URL query parameter.
+	OptionRoomID      = "roomId"
+	OptionAccessToken = "accessToken"
+	ParamAccessToken  = "access_token"
+
+	PathRooms       = "/_matrix/client/v3/rooms/"
+	PathSendMessage = "/send/m.room.message"
+)
+
+var (
+	ErrAccessTokenNotSet = errors.New("matrix access token not set")
+	ErrRoomNotSet        = errors.New("matrix room not set: set option `roomId` or use a URL ending in `" + PathSendMessage + "`")
+
+	txnCounter uint64
+)
+
+// Message is the `m.room.message` event content.
+type Message struct {
+	MsgType       string `json:"msgtype"`
+	Body          string `json:"body"`
+	Format        string `json:"format,omitempty"`
+	FormattedBody string `json:"formatted_body,omitempty"`
+}
+
+// Convert returns the Matrix message for an `outputFormat`. `card` renders
+// attachments as quotes with a colored title and a table of fields.
+// `nocard` renders the text lines only.
+func Convert(ccMsg commonchat.Message, outputFormat string) Message {
+	msg := Message{
+		MsgType: MsgTypeNotice,
+		Body:    Plain(Markdown(ccMsg)),
+		Format:  FormatHTML}
+	if outputFormat == config.ParamNameOutputFormatNocard {
+		msg.FormattedBody = HTML(Markdown(ccMsg))
+		return msg
+	}
+	parts := []string{}
+	lines := []string{}
+	if activity := strings.TrimSpace(ccMsg.Activity); len(activity) > 0 {
+		lines = append(lines, "<strong>"+html.EscapeString(activity)+"</strong>")
+	}
+	for _, s := range []string{ccMsg.Title, ccMsg.Text} {
+		if s = strings.TrimSpace(s); len(s) > 0 {
+			lines = append(lines, HTML(s))
+		}
+	}
+	if len(lines) > 0 {
+		parts = append(parts, "<p>"+strings.Join(lines, "<br>")+"</p>")
+	}
+	for _, att := range ccMsg.Attachments {
+		if s := attachmentHTML(att); len(s) > 0 {
+			parts = append(parts, s)
+		}
+	}
+	msg.FormattedBody = strings.Join(parts, "")
+	return msg
+}
+
+func attachmentHTML(att commonchat.Attachment) string {
+	lines := []string{}
+	if s := strings.TrimSpace(att.Pretext); len(s) > 0 {
+		lines = append(lines, HTML(s))
+	}
+	if title := strings.TrimSpace(att.Title); len(title) > 0 {
+		title = "<strong>" + HTML(title) + "</strong>"
+		if rgb, ok := adapterutil.ParseHex(att.Color); ok {
+			title = fmt.Sprintf(`<font data-mx-color="#%06x">`, rgb) + title + "</font>"
+		}
+		lines = append(lines, title)
+	}
+	if s := strings.TrimSpace(att.Text); len(s) > 0 {
+		lines = append(lines, HTML(s))
+	}
+	if name := strings.TrimSpace(att.AuthorName); len(name) > 0 {
+		name = html.EscapeString(name)
+		if link := strings.TrimSpace(att.AuthorLink); adapterutil.IsLinkURL(link) {
+			name = `<a href="` + html.EscapeString(link) + `">` + name + "</a>"
+		}
+		lines = append(lines, "<em>"+name+"</em>")
+	}
+	rows := []string{}
+	for _, field := range att.Fields {
+		title, value := strings.TrimSpace(field.Title), strings.TrimSpace(field.Value)
+		if len(title) == 0 && len(value) == 0 {
+			continue
+		}
+		rows = append(rows, "<tr><th>"+html.EscapeString(title)+"</th><td>"+HTML(value)+"</td></tr>")
+	}
+	if len(lines) == 0 && len(rows) == 0 {
+		return ""
+	}
+	s := "<blockquote>"
+	if len(lines) > 0 {
+		s += "<p>" + strings.Join(lines, "<br>") + "</p>"
+	}
+	if len(rows) > 0 {
+		s += "<table>" + strings.Join(rows, "") + "</table>"
+	}
+	return s + "</blockquote>"
+}
+
+// HTML converts chathooks markdown to Matrix HTML: bold, links and line
+// breaks.
+func HTML(s string) string {
+	return adapterutil.MarkdownHTML(s)
+}
+
+// Plain converts chathooks markdown to plain text, with links as
+// `text (url)`.
+func Plain(s string) string {
+	return adapterutil.MarkdownText(s)
+}
+
+// Markdown returns the message as chathooks markdown lines, with fields
+// as `**title**: value`.
+func Markdown(ccMsg commonchat.Message) string {
+	return strings.Join(adapterutil.MarkdownLines(ccMsg), "\n")
+}
+
+// SendURL returns the URL to send a message event with transaction ID
+// `txnID` and the access token in the URL. `webhookURL` is the homeserver
+// URL when `roomID` is set, or the room's send URL, e.g.
+// `https://matrix.example.org/_matrix/client/v3/rooms/%21room:example.org/send/m.room.message`.
+func SendURL(webhookURL, roomID, txnID string) (string, string, error) {
+	u, err := url.Parse(strings.TrimSpace(webhookURL))
+	if err != nil {
+		return "", "", err
+	} else if len(u.Scheme) == 0 || len(u.Host) == 0 {
+		return "", "", fmt.Errorf("matrix URL not absolute [%s]", webhookURL)
+	}
+	query := u.Query()
+	accessToken := query.Get(ParamAccessToken)
+	query.Del(ParamAccessToken)
+	path := strings.TrimRight(u.EscapedPath(), "/")
+	if roomID = strings.TrimSpace(roomID); len(roomID) > 0 {
+		path += PathRooms + url.PathEscape(roomID) + PathSendMessage
+	} else if !strings.HasSuffix(path, PathSendMessage) {
+		return "", "", ErrRoomNotSet
+	}
+	sendURL := u.Scheme + "://" + u.Host + path + "/" + url.PathEscape(txnID)
+	if len(query) > 0 {
+		sendURL += "?" + query.Encode()
+	}
+	return sendURL, accessToken, nil
+}
+
+// NewTxnID returns a transaction ID, which the homeserver uses to
+// deduplicate retried requests.
+func NewTxnID() string {
+	return fmt.Sprintf("chathooks.%d.%d", time.Now().UnixNano(), atomic.AddUint64(&txnCounter, 1))
+}
+
+// Adapter is a `commonchat.Adapter` for Matrix rooms. `RoomID` and
+// `AccessToken` are used when not set in the URL.
+type Adapter struct {
+	Client      *fasthttp.Client
+	WebhookURL  string
+	RoomID      string
+	AccessToken string
+}
+
+// NewAdapter returns an adapter. `webhookURL` is used by `SendMessage`.
+func NewAdapter(webhookURL string) *Adapter {
+	return &Adapter{Client: adapterutil.NewClient(), WebhookURL: webhookURL}
+}
+
+// SendWebhook sends a message to a room with an access token. The
+// homeserver returns the event ID. The adapter's access token is used only
+// for its own homeserver. Other URLs must include their own.
+func (adapter *Adapter) SendWebhook(url string, ccMsg commonchat.Message, matrixMsg any, opts map[string]any) (*fasthttp.Request, *fasthttp.Response, error) {
+	sendURL, accessToken, err := SendURL(url, adapter.RoomID, NewTxnID())
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(accessToken) == 0 && adapterutil.SameOrigin(adapter.WebhookURL, sendURL) {
+		accessToken = adapter.AccessToken
+	}
+	if len(accessToken) == 0 {
+		return nil, nil, ErrAccessTokenNotSet
+	}
+	return adapterutil.DoJSON(adapter.Client, http.MethodPut, sendURL,
+		Convert(ccMsg, adapterutil.OutputFormat(opts)),
+		map[string]string{hum.HeaderAuthorization: "Bearer " + accessToken})
+}
+
+// SendMessage sends a message to the adapter's room.
+func (adapter *Adapter) SendMessage(ccMsg commonchat.Message, matrixMsg any, opts map[string]any) (*fasthttp.Request, *fasthttp.Response, error) {
+	return adapter.SendWebhook(adapter.WebhookURL, ccMsg, matrixMsg, opts)
+}
+
+func (adapter *Adapter) WebhookUID(ctx *fasthttp.RequestCtx) (string, error) {
+	return adapterutil.WebhookUID(ctx)
+}
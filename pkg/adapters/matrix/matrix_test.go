@@ -0,0 +1,160 @@
+package matrix
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/grokify/commonchat"
+
+	"github.com/grokify/chathooks/pkg/config"
+)
+
+var SendURLTests = []struct {
+	webhookURL      string
+	roomID          string
+	wantURL         string
+	wantAccessToken string
+	wantErr         bool
+}{
+	{"https://matrix.example.org/", "!abc:example.org", "https://matrix.example.org/_matrix/client/v3/rooms/%21abc:example.org/send/m.room.message/t1", "", false},
+	{"https://matrix.example.org/_matrix/client/v3/rooms/%21abc:example.org/send/m.room.message?access_token=secret", "", "https://matrix.example.org/_matrix/client/v3/rooms/%21abc:example.org/send/m.room.message/t1", "secret", false},
+	{"https://matrix.example.org", "", "", "", true},
+	{"matrix.example.org", "!abc:example.org", "", "", true}}
+
+func TestSendURL(t *testing.T) {
+	for _, tt := range SendURLTests {
+		tryURL, tryAccessToken, err := SendURL(tt.webhookURL, tt.roomID, "t1")
+		if (err != nil) != tt.wantErr {
+			t.Errorf("SendURL(%v, %v): want error [%v], got [%v]", tt.webhookURL, tt.roomID, tt.wantErr, err)
+		}
+		if tryURL != tt.wantURL || tryAccessToken != tt.wantAccessToken {
+			t.Errorf("SendURL(%v, %v): want [%v] [%v], got [%v] [%v]",
+				tt.webhookURL, tt.roomID, tt.wantURL, tt.wantAccessToken, tryURL, tryAccessToken)
+		}
+	}
+}
+
+var HTMLTests = []struct {
+	v    string
+	want string
+}{
+	{"**minimal/master** passed", "<strong>minimal/master</strong> passed"},
+	{"[Build #1](https://example.com/builds/1?a=1&b=2)", `<a href="https://example.com/builds/1?a=1&amp;b=2">Build #1</a>`},
+	{"a < b\nc", "a &lt; b<br>c"}}
+
+func TestHTML(t *testing.T) {
+	for _, tt := range HTMLTests {
+		try := HTML(tt.v)
+		if try != tt.want {
+			t.Errorf("HTML(%v): want [%v], got [%v]", tt.v, tt.want, try)
+		}
+	}
+}
+
+var ConvertTests = []struct {
+	outputFormat string
+	wantHTML     []string
+}{
+	{"", []string{"<strong>Build passed</strong>", `<font data-mx-color="#2eb886"><strong>minimal</strong></font>`, "<tr><th>Branch</th><td>master</td></tr>"}},
+	{config.ParamNameOutputFormatNocard, []string{"<strong>Build passed</strong>", "<strong>Branch</strong>: master"}}}
+
+func TestConvert(t *testing.T) {
+	ccMsg := commonchat.Message{
+		Activity: "Build passed",
+		Attachments: []commonchat.Attachment{{
+			Color:  "good",
+			Title:  "minimal",
+			Text:   "[Build #1](https://example.com/builds/1) passed",
+			Fields: []commonchat.Field{{Title: "Branch", Value: "master", Short: true}}}}}
+	for _, tt := range ConvertTests {
+		msg := Convert(ccMsg, tt.outputFormat)
+		wantBody := "Build passed\nminimal\nBuild #1 (https://example.com/builds/1) passed\nBranch: master"
+		if msg.MsgType != MsgTypeNotice || msg.Format != FormatHTML || msg.Body != wantBody {
+			t.Errorf("Convert(%s): want body [%v], got [%v]", tt.outputFormat, wantBody, msg)
+		}
+		for _, want := range tt.wantHTML {
+			if !strings.Contains(msg.FormattedBody, want) {
+				t.Errorf("Convert(%s): want HTML with [%v], got [%v]", tt.outputFormat, want, msg.FormattedBody)
+			}
+		}
+	}
+}
+
+// homeserver is a stand-in for the client-server API send endpoint.
+func homeserver(t *testing.T, accessToken string, got *Message) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer "+accessToken {
+			w.WriteHeader(http.StatusUnauthorized)
+			_, _ = w.Write([]byte(`{"errcode":"M_UNKNOWN_TOKEN","error":"Invalid access token"}`))
+			return
+		}
+		if r.Method != http.MethodPut ||
+			!strings.HasPrefix(r.URL.EscapedPath(), "/_matrix/client/v3/rooms/%21abc:example.org/send/m.room.message/chathooks.") {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"errcode":"M_UNRECOGNIZED","error":"Unrecognized request"}`))
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		if err := json.Unmarshal(body, got); err != nil {
+			t.Errorf("homeserver: invalid body [%s]", body)
+		}
+		_, _ = w.Write([]byte(`{"event_id":"$event:example.org"}`))
+	}))
+}
+
+var SendMessageTests = []struct {
+	accessToken string
+	wantStatus  int
+}{
+	{"secret", http.StatusOK},
+	{"wrong", http.StatusUnauthorized}}
+
+func TestSendMessage(t *testing.T) {
+	for _, tt := range SendMessageTests {
+		got := Message{}
+		srv := homeserver(t, "secret", &got)
+		adapter := NewAdapter(srv.URL)
+		adapter.RoomID = "!abc:example.org"
+		adapter.AccessToken = tt.accessToken
+		_, res, err := adapter.SendMessage(commonchat.Message{Activity: "Build passed"}, nil, nil)
+		srv.Close()
+		if err != nil {
+			t.Fatalf("SendMessage(%s): error [%v]", tt.accessToken, err)
+		}
+		if res.StatusCode() != tt.wantStatus {
+			t.Errorf("SendMessage(%s): want status [%d], got [%d]", tt.accessToken, tt.wantStatus, res.StatusCode())
+		}
+		if tt.wantStatus == http.StatusOK && got.Body != "Build passed" {
+			t.Errorf("SendMessage(%s): want body [Build passed], got [%v]", tt.accessToken, got)
+		}
+	}
+}
+
+func TestSendMessageAccessTokenNotSet(t *testing.T) {
+	adapter := NewAdapter("https://matrix.example.org")
+	adapter.RoomID = "!abc:example.org"
+	if _, _, err := adapter.SendMessage(commonchat.Message{}, nil, nil); err != ErrAccessTokenNotSet {
+		t.Errorf("SendMessage(): want error [%v], got [%v]", ErrAccessTokenNotSet, err)
+	}
+}
+
+// TestSendWebhookOtherHost checks the adapter's access token is not sent
+// to a URL supplied by a request.
+func TestSendWebhookOtherHost(t *testing.T) {
+	got := Message{}
+	srv := homeserver(t, "secret", &got)
+	defer srv.Close()
+	adapter := NewAdapter("https://matrix.example.org")
+	adapter.RoomID = "!abc:example.org"
+	adapter.AccessToken = "secret"
+	if _, _, err := adapter.SendWebhook(srv.URL, commonchat.Message{Activity: "Build passed"}, nil, nil); err != ErrAccessTokenNotSet {
+		t.Errorf("SendWebhook(%s): want error [%v], got [%v]", srv.URL, ErrAccessTokenNotSet, err)
+	}
+	if len(got.Body) > 0 {
+		t.Errorf("SendWebhook(%s): want no request, got [%v]", srv.URL, got)
+	}
+}
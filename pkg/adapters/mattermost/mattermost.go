@@ -0,0 +1,74 @@
+// Package mattermost is a Mattermost incoming webhook output adapter. It
+// uses the Slack-compatible conversion with chathooks markdown, which
+// Mattermost renders, and supports channel and sender overrides.
+package mattermost
+
+import (
+	"strings"
+
+	"github.com/grokify/commonchat"
+	"github.com/valyala/fasthttp"
+
+	"github.com/grokify/chathooks/pkg/adapters/adapterutil"
+	"github.com/grokify/chathooks/pkg/adapters/slackcompat"
+	"github.com/grokify/chathooks/pkg/config"
+)
+
+// Message is the webhook request body.
+type Message struct {
+	Text        string                   `json:"text,omitempty"`
+	Channel     string                   `json:"channel,omitempty"`
+	Username    string                   `json:"username,omitempty"`
+	IconURL     string                   `json:"icon_url,omitempty"`
+	IconEmoji   string                   `json:"icon_emoji,omitempty"`
+	Attachments []slackcompat.Attachment `json:"attachments,omitempty"`
+}
+
+// Convert returns the Mattermost message for an `outputFormat`.
+// Attachments keep their fields, with short fields side by side. `nocard`
+// appends attachments to the text. The icon override replaces the
+// message icon. Username and icon overrides require the server to enable
+// integrations to override them.
+func Convert(ccMsg commonchat.Message, outputFormat string, overrides slackcompat.Overrides) Message {
+	text, atts := slackcompat.Converter{}.Convert(ccMsg, outputFormat != config.ParamNameOutputFormatNocard)
+	msg := Message{
+		Text:        text,
+		Channel:     overrides.Channel,
+		Username:    overrides.Username,
+		IconURL:     strings.TrimSpace(ccMsg.IconURL),
+		IconEmoji:   strings.TrimSpace(ccMsg.IconEmoji),
+		Attachments: atts}
+	if len(overrides.IconURL) > 0 {
+		msg.IconURL, msg.IconEmoji = overrides.IconURL, ""
+	}
+	return msg
+}
+
+// Adapter is a `commonchat.Adapter` for Mattermost incoming webhooks.
+// `Overrides` are the defaults, replaced by those in request options.
+type Adapter struct {
+	Client     *fasthttp.Client
+	WebhookURL string
+	Overrides  slackcompat.Overrides
+}
+
+// NewAdapter returns an adapter. `webhookURL` is used by `SendMessage`.
+func NewAdapter(webhookURL string) *Adapter {
+	return &Adapter{Client: adapterutil.NewClient(), WebhookURL: webhookURL}
+}
+
+// SendWebhook posts a message to a webhook URL.
+func (adapter *Adapter) SendWebhook(url string, ccMsg commonchat.Message, mattermostMsg any, opts map[string]any) (*fasthttp.Request, *fasthttp.Response, error) {
+	return adapterutil.PostJSON(adapter.Client, url,
+		Convert(ccMsg, adapterutil.OutputFormat(opts),
+			adapter.Overrides.Merge(slackcompat.NewOverrides(opts))), nil)
+}
+
+// SendMessage posts a message to the adapter's webhook URL.
+func (adapter *Adapter) SendMessage(ccMsg commonchat.Message, mattermostMsg any, opts map[string]any) (*fasthttp.Request, *fasthttp.Response, error) {
+	return adapter.SendWebhook(adapter.WebhookURL, ccMsg, mattermostMsg, opts)
+}
+
+func (adapter *Adapter) WebhookUID(ctx *fasthttp.RequestCtx) (string, error) {
+	return adapterutil.WebhookUID(ctx)
+}
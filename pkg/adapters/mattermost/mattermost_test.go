@@ -0,0 +1,63 @@
+package mattermost
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/grokify/commonchat"
+
+	"github.com/grokify/chathooks/pkg/adapters/slackcompat"
+	"github.com/grokify/chathooks/pkg/config"
+)
+
+var ConvertTests = []struct {
+	overrides     slackcompat.Overrides
+	wantIconURL   string
+	wantIconEmoji string
+}{
+	{slackcompat.Overrides{}, "", ":rocket:"},
+	{slackcompat.Overrides{IconURL: "https://example.com/icon.png"}, "https://example.com/icon.png", ""}}
+
+func TestConvert(t *testing.T) {
+	ccMsg := commonchat.Message{
+		Activity:  "Build passed",
+		IconEmoji: ":rocket:",
+		Text:      "**minimal** [Build #1](https://example.com/builds/1)"}
+	for _, tt := range ConvertTests {
+		msg := Convert(ccMsg, "", tt.overrides)
+		if msg.IconURL != tt.wantIconURL || msg.IconEmoji != tt.wantIconEmoji {
+			t.Errorf("Convert(%v): want icon [%v] [%v], got [%v] [%v]",
+				tt.overrides, tt.wantIconURL, tt.wantIconEmoji, msg.IconURL, msg.IconEmoji)
+		}
+		if msg.Text != "Build passed\n"+ccMsg.Text {
+			t.Errorf("Convert(%v): want markdown kept, got [%v]", tt.overrides, msg.Text)
+		}
+	}
+}
+
+func TestSendMessage(t *testing.T) {
+	got := Message{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		_ = json.Unmarshal(body, &got)
+		_, _ = w.Write([]byte("ok"))
+	}))
+	defer srv.Close()
+
+	adapter := NewAdapter(srv.URL)
+	adapter.Overrides = slackcompat.Overrides{Channel: "dev", Username: "chathooks"}
+	_, res, err := adapter.SendMessage(commonchat.Message{Activity: "Build passed"}, nil,
+		map[string]any{config.ParamNameChannel: "ops"})
+	if err != nil {
+		t.Fatalf("SendMessage(): error [%v]", err)
+	}
+	if res.StatusCode() != http.StatusOK {
+		t.Errorf("SendMessage(): want status [%d], got [%d]", http.StatusOK, res.StatusCode())
+	}
+	if got.Channel != "ops" || got.Username != "chathooks" {
+		t.Errorf("SendMessage(): want channel [ops] username [chathooks], got [%v] [%v]", got.Channel, got.Username)
+	}
+}
@@ -0,0 +1,84 @@
+// Package ringcentral is a RingCentral Team Messaging output adapter for
+// the current, v2, incoming webhooks, which accept Adaptive Cards. The
+// `glip` adapter posts the legacy Glip webhook format.
+package ringcentral
+
+import (
+	"strings"
+
+	"github.com/grokify/commonchat"
+	"github.com/valyala/fasthttp"
+
+	"github.com/grokify/chathooks/pkg/adapters/adapterutil"
+	"github.com/grokify/chathooks/pkg/adapters/adaptivecard"
+	"github.com/grokify/chathooks/pkg/adapters/slackcompat"
+	"github.com/grokify/chathooks/pkg/config"
+)
+
+// WebhookURLPrefix is prefixed to a webhook UID to build its URL.
+const WebhookURLPrefix = "https://hooks.ringcentral.com/webhook/v2/"
+
+// Message is the webhook request body. The activity and icon are shown as
+// the sender, above the text and cards.
+type Message struct {
+	Activity    string              `json:"activity,omitempty"`
+	IconURI     string              `json:"iconUri,omitempty"`
+	Text        string              `json:"text,omitempty"`
+	Attachments []adaptivecard.Card `json:"attachments,omitempty"`
+}
+
+// Convert returns the RingCentral message for an `outputFormat`. `card`
+// and `adaptivecard` render the title, text and attachments as an
+// Adaptive Card with attachments in containers styled by their color,
+// short fields in columns and images. `nocard` renders markdown text with
+// fields as `**title**: value` lines.
+func Convert(ccMsg commonchat.Message, outputFormat string) Message {
+	msg := Message{
+		Activity: strings.TrimSpace(ccMsg.Activity),
+		IconURI:  strings.TrimSpace(ccMsg.IconURL)}
+	body := ccMsg
+	body.Activity = ""
+	body.IconURL = ""
+	if outputFormat == config.ParamNameOutputFormatNocard {
+		msg.Text, _ = slackcompat.Converter{}.Convert(body, false)
+	} else if card := adaptivecard.Convert(body, false); len(card.Body) > 0 {
+		msg.Attachments = []adaptivecard.Card{card}
+	}
+	return msg
+}
+
+// WebhookURL returns the URL for a webhook URL or UID.
+func WebhookURL(urlOrUID string) string {
+	urlOrUID = strings.TrimSpace(urlOrUID)
+	if strings.Contains(urlOrUID, "://") {
+		return urlOrUID
+	}
+	return WebhookURLPrefix + urlOrUID
+}
+
+// Adapter is a `commonchat.Adapter` for RingCentral v2 webhooks.
+type Adapter struct {
+	Client     *fasthttp.Client
+	WebhookURL string
+}
+
+// NewAdapter returns an adapter. `webhookURLOrUID` is used by
+// `SendMessage`.
+func NewAdapter(webhookURLOrUID string) *Adapter {
+	return &Adapter{Client: adapterutil.NewClient(), WebhookURL: webhookURLOrUID}
+}
+
+// SendWebhook posts a message to a webhook URL or UID.
+func (adapter *Adapter) SendWebhook(urlOrUID string, ccMsg commonchat.Message, rcMsg any, opts map[string]any) (*fasthttp.Request, *fasthttp.Response, error) {
+	return adapterutil.PostJSON(adapter.Client, WebhookURL(urlOrUID),
+		Convert(ccMsg, adapterutil.OutputFormat(opts)), nil)
+}
+
+// SendMessage posts a message to the adapter's webhook.
+func (adapter *Adapter) SendMessage(ccMsg commonchat.Message, rcMsg any, opts map[string]any) (*fasthttp.Request, *fasthttp.Response, error) {
+	return adapter.SendWebhook(adapter.WebhookURL, ccMsg, rcMsg, opts)
+}
+
+func (adapter *Adapter) WebhookUID(ctx *fasthttp.RequestCtx) (string, error) {
+	return adapterutil.WebhookUID(ctx)
+}
@@ -0,0 +1,87 @@
+package ringcentral
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/grokify/commonchat"
+
+	"github.com/grokify/chathooks/pkg/adapters/adaptivecard"
+	"github.com/grokify/chathooks/pkg/config"
+)
+
+var testMessage = commonchat.Message{
+	Activity: "Build passed",
+	IconURL:  "https://example.com/icon.png",
+	Text:     "Build #1 passed",
+	Attachments: []commonchat.Attachment{{
+		Color:  "#00ff00",
+		Fields: []commonchat.Field{{Title: "Branch", Value: "master", Short: true}}}}}
+
+var ConvertTests = []struct {
+	outputFormat string
+	wantText     string
+	wantCard     bool
+}{
+	{"", "", true},
+	{config.ParamNameOutputFormatAdaptivecard, "", true},
+	{config.ParamNameOutputFormatNocard, "Build #1 passed\n**Branch**: master", false}}
+
+func TestConvert(t *testing.T) {
+	for _, tt := range ConvertTests {
+		msg := Convert(testMessage, tt.outputFormat)
+		if msg.Activity != testMessage.Activity || msg.IconURI != testMessage.IconURL || msg.Text != tt.wantText {
+			t.Errorf("Convert(%s): want activity, icon and text [%v], got [%v]", tt.outputFormat, tt.wantText, msg)
+		}
+		hasCard := len(msg.Attachments) == 1 && msg.Attachments[0].Type == adaptivecard.TypeCard
+		if hasCard != tt.wantCard {
+			t.Errorf("Convert(%s): want card [%v], got [%v]", tt.outputFormat, tt.wantCard, msg.Attachments)
+		}
+	}
+	if msg := Convert(commonchat.Message{Activity: "Build passed"}, ""); len(msg.Attachments) > 0 {
+		t.Errorf("Convert(activity only): want no card, got [%v]", msg.Attachments)
+	}
+}
+
+var WebhookURLTests = []struct {
+	v    string
+	want string
+}{
+	{"11112222-3333-4444-5555-666677778888", "https://hooks.ringcentral.com/webhook/v2/11112222-3333-4444-5555-666677778888"},
+	{"https://hooks.ringcentral.com/webhook/v2/abc", "https://hooks.ringcentral.com/webhook/v2/abc"}}
+
+func TestWebhookURL(t *testing.T) {
+	for _, tt := range WebhookURLTests {
+		try := WebhookURL(tt.v)
+		if try != tt.want {
+			t.Errorf("WebhookURL(%v): want [%v], got [%v]", tt.v, tt.want, try)
+		}
+	}
+}
+
+func TestSendWebhook(t *testing.T) {
+	var got map[string]any
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if err := json.Unmarshal(body, &got); err != nil {
+			t.Errorf("SendWebhook(): invalid JSON [%s]", string(body))
+		}
+		_, _ = w.Write([]byte(`{"status":"OK"}`))
+	}))
+	defer srv.Close()
+
+	adapter := NewAdapter(srv.URL)
+	_, res, err := adapter.SendMessage(testMessage, nil, nil)
+	if err != nil {
+		t.Fatalf("SendMessage(): error [%v]", err)
+	}
+	if res.StatusCode() != http.StatusOK {
+		t.Errorf("SendMessage(): want status [%d], got [%d]", http.StatusOK, res.StatusCode())
+	}
+	if got["activity"] != testMessage.Activity || got["iconUri"] != testMessage.IconURL {
+		t.Errorf("SendMessage(): want activity [%s], got [%v]", testMessage.Activity, got)
+	}
+}
@@ -0,0 +1,85 @@
+// Package rocketchat is a Rocket.Chat incoming webhook output adapter. It
+// uses the Slack-compatible conversion with Rocket.Chat markdown and
+// supports channel and sender overrides.
+package rocketchat
+
+import (
+	"strings"
+
+	"github.com/grokify/commonchat"
+	"github.com/valyala/fasthttp"
+
+	"github.com/grokify/chathooks/pkg/adapters/adapterutil"
+	"github.com/grokify/chathooks/pkg/adapters/slackcompat"
+	"github.com/grokify/chathooks/pkg/config"
+)
+
+// Message is the webhook request body. Rocket.Chat names the sender
+// overrides `alias`, `avatar` and `emoji`.
+type Message struct {
+	Text        string                   `json:"text,omitempty"`
+	Channel     string                   `json:"channel,omitempty"`
+	Alias       string                   `json:"alias,omitempty"`
+	Avatar      string                   `json:"avatar,omitempty"`
+	Emoji       string                   `json:"emoji,omitempty"`
+	Attachments []slackcompat.Attachment `json:"attachments,omitempty"`
+}
+
+// Markdown converts chathooks markdown to Rocket.Chat markdown, which
+// uses `*bold*`. Links are kept as `[text](url)`.
+func Markdown(s string) string {
+	return adapterutil.RxBold.ReplaceAllString(s, "*$1*")
+}
+
+// Convert returns the Rocket.Chat message for an `outputFormat`. `nocard`
+// appends attachments to the text. The icon override replaces the
+// message icon.
+func Convert(ccMsg commonchat.Message, outputFormat string, overrides slackcompat.Overrides) Message {
+	text, atts := slackcompat.Converter{Markdown: Markdown}.Convert(
+		ccMsg, outputFormat != config.ParamNameOutputFormatNocard)
+	msg := Message{
+		Text:        text,
+		Channel:     overrides.Channel,
+		Alias:       overrides.Username,
+		Avatar:      strings.TrimSpace(ccMsg.IconURL),
+		Emoji:       strings.TrimSpace(ccMsg.IconEmoji),
+		Attachments: atts}
+	if len(overrides.IconURL) > 0 {
+		msg.Avatar = overrides.IconURL
+	}
+	if len(msg.Avatar) > 0 {
+		// Rocket.Chat prefers `emoji` over `avatar`.
+		msg.Emoji = ""
+	}
+	return msg
+}
+
+// Adapter is a `commonchat.Adapter` for Rocket.Chat incoming webhooks.
+// `Overrides` are the defaults, replaced by those in request options.
+type Adapter struct {
+	Client     *fasthttp.Client
+	WebhookURL string
+	Overrides  slackcompat.Overrides
+}
+
+// NewAdapter returns an adapter. `webhookURL` is used by `SendMessage`.
+func NewAdapter(webhookURL string) *Adapter {
+	return &Adapter{Client: adapterutil.NewClient(), WebhookURL: webhookURL}
+}
+
+// SendWebhook posts a message to a webhook URL, which includes the
+// integration ID and token.
+func (adapter *Adapter) SendWebhook(url string, ccMsg commonchat.Message, rocketchatMsg any, opts map[string]any) (*fasthttp.Request, *fasthttp.Response, error) {
+	return adapterutil.PostJSON(adapter.Client, url,
+		Convert(ccMsg, adapterutil.OutputFormat(opts),
+			adapter.Overrides.Merge(slackcompat.NewOverrides(opts))), nil)
+}
+
+// SendMessage posts a message to the adapter's webhook URL.
+func (adapter *Adapter) SendMessage(ccMsg commonchat.Message, rocketchatMsg any, opts map[string]any) (*fasthttp.Request, *fasthttp.Response, error) {
+	return adapter.SendWebhook(adapter.WebhookURL, ccMsg, rocketchatMsg, opts)
+}
+
+func (adapter *Adapter) WebhookUID(ctx *fasthttp.RequestCtx) (string, error) {
+	return adapterutil.WebhookUID(ctx)
+}
@@ -0,0 +1,54 @@
+package rocketchat
+
+import (
+	"testing"
+
+	"github.com/grokify/commonchat"
+
+	"github.com/grokify/chathooks/pkg/adapters/slackcompat"
+	"github.com/grokify/chathooks/pkg/config"
+)
+
+var MarkdownTests = []struct {
+	v    string
+	want string
+}{
+	{"**bold** [link](https://example.com)", "*bold* [link](https://example.com)"},
+	{"plain", "plain"}}
+
+func TestMarkdown(t *testing.T) {
+	for _, tt := range MarkdownTests {
+		try := Markdown(tt.v)
+		if try != tt.want {
+			t.Errorf("Markdown(%v): want [%v], got [%v]", tt.v, tt.want, try)
+		}
+	}
+}
+
+var ConvertTests = []struct {
+	outputFormat string
+	overrides    slackcompat.Overrides
+	wantAtts     int
+	wantAvatar   string
+	wantEmoji    string
+}{
+	{"", slackcompat.Overrides{}, 1, "", ":rocket:"},
+	{config.ParamNameOutputFormatNocard, slackcompat.Overrides{IconURL: "https://example.com/icon.png"}, 0, "https://example.com/icon.png", ""}}
+
+func TestConvert(t *testing.T) {
+	ccMsg := commonchat.Message{
+		Activity:    "Build passed",
+		IconEmoji:   ":rocket:",
+		Attachments: []commonchat.Attachment{{Title: "**minimal**", Color: "good"}}}
+	for _, tt := range ConvertTests {
+		msg := Convert(ccMsg, tt.outputFormat, tt.overrides)
+		if len(msg.Attachments) != tt.wantAtts || msg.Avatar != tt.wantAvatar || msg.Emoji != tt.wantEmoji {
+			t.Errorf("Convert(%s): want [%d] attachments avatar [%v] emoji [%v], got [%v]",
+				tt.outputFormat, tt.wantAtts, tt.wantAvatar, tt.wantEmoji, msg)
+		}
+	}
+	msg := Convert(ccMsg, "", slackcompat.Overrides{Username: "chathooks"})
+	if msg.Alias != "chathooks" || msg.Attachments[0].Title != "*minimal*" {
+		t.Errorf("Convert(): want alias and Rocket.Chat markdown, got [%v]", msg)
+	}
+}
@@ -0,0 +1,129 @@
+// Package slackcompat converts a `commonchat.Message` for chat services
+// that accept Slack-compatible incoming webhooks, such as Mattermost and
+// Rocket.Chat, which differ from Slack in markdown and field names.
+package slackcompat
+
+import (
+	"strings"
+
+	"github.com/grokify/commonchat"
+	ccslack "github.com/grokify/commonchat/slack"
+	"github.com/grokify/mogo/encoding/jsonutil"
+
+	"github.com/grokify/chathooks/pkg/adapters/adapterutil"
+	"github.com/grokify/chathooks/pkg/config"
+)
+
+// Attachment is a Slack-compatible message attachment, which has the
+// thumbnail in `thumb_url`.
+type Attachment struct {
+	ccslack.Attachment
+	ThumbURL string `json:"thumb_url,omitempty"`
+}
+
+// Overrides are the sender and channel overrides supported by
+// Slack-compatible webhooks. Empty values use the webhook's defaults.
+type Overrides struct {
+	Channel  string
+	Username string
+	IconURL  string
+}
+
+// NewOverrides returns the overrides in adapter options, from adapter
+// configuration or the `channel`, `username` and `icon_url` parameters.
+func NewOverrides(opts map[string]any) Overrides {
+	return Overrides{
+		Channel:  strings.TrimSpace(adapterutil.OptionString(opts, config.ParamNameChannel)),
+		Username: strings.TrimSpace(adapterutil.OptionString(opts, config.ParamNameUsername)),
+		IconURL:  strings.TrimSpace(adapterutil.OptionString(opts, config.ParamNameIconURL))}
+}
+
+// Merge returns the overrides with the non-empty values of `o2` replacing
+// those of `o`.
+func (o Overrides) Merge(o2 Overrides) Overrides {
+	if len(o2.Channel) > 0 {
+		o.Channel = o2.Channel
+	}
+	if len(o2.Username) > 0 {
+		o.Username = o2.Username
+	}
+	if len(o2.IconURL) > 0 {
+		o.IconURL = o2.IconURL
+	}
+	return o
+}
+
+// Converter converts messages with a service's markdown.
+type Converter struct {
+	// Markdown converts chathooks markdown, e.g. `ccslack.ConvertMarkdownSlack`.
+	// Markdown is not converted when nil.
+	Markdown func(string) string
+}
+
+// Convert returns the message text and attachments. When
+// `useAttachments` is false, as for `outputFormat=nocard`, attachments are
+// appended to the text with fields as `**title**: value` lines.
+func (c Converter) Convert(ccMsg commonchat.Message, useAttachments bool) (string, []Attachment) {
+	lines := []string{}
+	add := func(s string) {
+		if s = strings.TrimSpace(s); len(s) > 0 {
+			lines = append(lines, s)
+		}
+	}
+	add(ccMsg.Activity)
+	add(ccMsg.Title)
+	add(ccMsg.Text)
+	var atts []Attachment
+	if useAttachments {
+		for i, slackAtt := range ccslack.ConvertCommonMessage(ccMsg).Attachments {
+			atts = append(atts, c.attachment(slackAtt, ccMsg.Attachments[i]))
+		}
+		return c.markdown(strings.Join(lines, "\n")), atts
+	}
+	for _, att := range ccMsg.Attachments {
+		lines = append(lines, adapterutil.AttachmentLines(att, true)...)
+	}
+	return c.markdown(strings.Join(lines, "\n")), atts
+}
+
+// attachment returns the Slack attachment with the service's markdown in
+// place of Slack `mrkdwn`, the author and fallback, which Slack's
+// conversion omits, a hex color and `thumb_url`.
+func (c Converter) attachment(slackAtt ccslack.Attachment, att commonchat.Attachment) Attachment {
+	out := Attachment{Attachment: slackAtt, ThumbURL: slackAtt.ThumbnailURL}
+	out.ThumbnailURL = ""
+	out.MarkdownIn = nil
+	out.Color = adapterutil.HexColor(slackAtt.Color)
+	out.Pretext = c.markdown(att.Pretext)
+	out.Title = c.markdown(att.Title)
+	out.Text = c.markdown(att.Text)
+	out.AuthorName = att.AuthorName
+	out.AuthorLink = att.AuthorLink
+	out.AuthorIcon = att.AuthorIcon
+	if out.Fallback = strings.TrimSpace(att.Fallback); len(out.Fallback) == 0 {
+		for _, s := range []string{att.Title, att.Text, att.Pretext} {
+			if s = strings.TrimSpace(s); len(s) > 0 {
+				out.Fallback = Plain(s)
+				break
+			}
+		}
+	}
+	for i, field := range att.Fields {
+		out.Fields[i].Title = field.Title
+		out.Fields[i].Value = jsonutil.String(c.markdown(field.Value))
+	}
+	return out
+}
+
+func (c Converter) markdown(s string) string {
+	if c.Markdown == nil {
+		return s
+	}
+	return c.Markdown(s)
+}
+
+// Plain returns chathooks markdown as plain text, as used by notifications.
+func Plain(s string) string {
+	s = adapterutil.RxLink.ReplaceAllString(s, "$1")
+	return adapterutil.RxBold.ReplaceAllString(s, "$1")
+}
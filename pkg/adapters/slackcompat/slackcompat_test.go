@@ -0,0 +1,58 @@
+package slackcompat
+
+import (
+	"testing"
+
+	"github.com/grokify/commonchat"
+)
+
+var PlainTests = []struct {
+	v    string
+	want string
+}{
+	{"[Build #1](https://example.com/builds/1) for **minimal/master** passed", "Build #1 for minimal/master passed"},
+	{"plain", "plain"}}
+
+func TestPlain(t *testing.T) {
+	for _, tt := range PlainTests {
+		try := Plain(tt.v)
+		if try != tt.want {
+			t.Errorf("Plain(%v): want [%v], got [%v]", tt.v, tt.want, try)
+		}
+	}
+}
+
+var ConvertTests = []struct {
+	useAttachments bool
+	wantText       string
+	wantAtts       int
+}{
+	{true, "Build passed\n[Build #1](https://example.com/builds/1)", 1},
+	{false, "Build passed\n[Build #1](https://example.com/builds/1)\n**minimal**\n**Branch**: master", 0}}
+
+func TestConvert(t *testing.T) {
+	ccMsg := commonchat.Message{
+		Activity: "Build passed",
+		Text:     "[Build #1](https://example.com/builds/1)",
+		Attachments: []commonchat.Attachment{{
+			Color:  "good",
+			Title:  "minimal",
+			Fields: []commonchat.Field{{Title: "Branch", Value: "master", Short: true}}}}}
+	for _, tt := range ConvertTests {
+		text, atts := Converter{}.Convert(ccMsg, tt.useAttachments)
+		if text != tt.wantText || len(atts) != tt.wantAtts {
+			t.Errorf("Convert(%v): want [%v] [%d] attachments, got [%v] [%v]",
+				tt.useAttachments, tt.wantText, tt.wantAtts, text, atts)
+		}
+		if len(atts) > 0 && (atts[0].Color != "#2eb886" || atts[0].Fallback != "minimal") {
+			t.Errorf("Convert(%v): want color and fallback, got [%v]", tt.useAttachments, atts[0])
+		}
+	}
+}
+
+func TestOverridesMerge(t *testing.T) {
+	o := Overrides{Channel: "dev", Username: "chathooks"}.Merge(Overrides{Channel: "ops"})
+	if o.Channel != "ops" || o.Username != "chathooks" {
+		t.Errorf("Overrides.Merge(): want channel [ops] username [chathooks], got [%v]", o)
+	}
+}
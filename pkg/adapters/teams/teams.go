@@ -0,0 +1,71 @@
+// Package teams is a Microsoft Teams output adapter. It posts Adaptive
+// Cards to Teams Workflows webhooks, created with the "Post to a channel
+// when a webhook request is received" template, and incoming webhooks.
+package teams
+
+import (
+	"github.com/grokify/commonchat"
+	"github.com/valyala/fasthttp"
+
+	"github.com/grokify/chathooks/pkg/adapters/adapterutil"
+	"github.com/grokify/chathooks/pkg/adapters/adaptivecard"
+	"github.com/grokify/chathooks/pkg/config"
+)
+
+const (
+	MessageType = "message"
+	WidthFull   = "Full"
+)
+
+// Message is the webhook request body.
+type Message struct {
+	Type        string       `json:"type"`
+	Attachments []Attachment `json:"attachments"`
+}
+
+// Attachment is a card attachment.
+type Attachment struct {
+	ContentType string            `json:"contentType"`
+	ContentURL  *string           `json:"contentUrl"`
+	Content     adaptivecard.Card `json:"content"`
+}
+
+// Convert returns the Teams message for an `outputFormat`. `card` and
+// `adaptivecard` render attachments as containers styled by their color,
+// with fields in columns and images. `nocard` renders text only.
+func Convert(ccMsg commonchat.Message, outputFormat string) Message {
+	card := adaptivecard.Convert(ccMsg, outputFormat == config.ParamNameOutputFormatNocard)
+	card.MSTeams = &adaptivecard.MSTeams{Width: WidthFull}
+	return Message{
+		Type: MessageType,
+		Attachments: []Attachment{{
+			ContentType: adaptivecard.ContentType,
+			Content:     card}}}
+}
+
+// Adapter is a `commonchat.Adapter` for Teams webhooks.
+type Adapter struct {
+	Client     *fasthttp.Client
+	WebhookURL string
+}
+
+// NewAdapter returns an adapter. `webhookURL` is used by `SendMessage`.
+func NewAdapter(webhookURL string) *Adapter {
+	return &Adapter{Client: adapterutil.NewClient(), WebhookURL: webhookURL}
+}
+
+// SendWebhook posts a message to a webhook URL. Workflows webhooks
+// return `202 Accepted`.
+func (adapter *Adapter) SendWebhook(url string, ccMsg commonchat.Message, teamsMsg any, opts map[string]any) (*fasthttp.Request, *fasthttp.Response, error) {
+	return adapterutil.PostJSON(adapter.Client, url,
+		Convert(ccMsg, adapterutil.OutputFormat(opts)), nil)
+}
+
+// SendMessage posts a message to the adapter's webhook URL.
+func (adapter *Adapter) SendMessage(ccMsg commonchat.Message, teamsMsg any, opts map[string]any) (*fasthttp.Request, *fasthttp.Response, error) {
+	return adapter.SendWebhook(adapter.WebhookURL, ccMsg, teamsMsg, opts)
+}
+
+func (adapter *Adapter) WebhookUID(ctx *fasthttp.RequestCtx) (string, error) {
+	return adapterutil.WebhookUID(ctx)
+}
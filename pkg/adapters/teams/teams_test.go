@@ -0,0 +1,72 @@
+package teams
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/grokify/commonchat"
+
+	"github.com/grokify/chathooks/pkg/adapters/adaptivecard"
+	"github.com/grokify/chathooks/pkg/config"
+)
+
+var ConvertTests = []struct {
+	outputFormat  string
+	wantContainer bool
+}{
+	{"", true},
+	{config.ParamNameOutputFormatCard, true},
+	{config.ParamNameOutputFormatAdaptivecard, true},
+	{config.ParamNameOutputFormatNocard, false}}
+
+func TestConvert(t *testing.T) {
+	ccMsg := commonchat.Message{
+		Activity: "Build passed",
+		Attachments: []commonchat.Attachment{{
+			Color:  "#00ff00",
+			Fields: []commonchat.Field{{Title: "Branch", Value: "master", Short: true}}}}}
+	for _, tt := range ConvertTests {
+		msg := Convert(ccMsg, tt.outputFormat)
+		if msg.Type != MessageType || len(msg.Attachments) != 1 ||
+			msg.Attachments[0].ContentType != adaptivecard.ContentType {
+			t.Errorf("Convert(%s): want one Adaptive Card attachment, got [%v]", tt.outputFormat, msg)
+			continue
+		}
+		hasContainer := false
+		for _, el := range msg.Attachments[0].Content.Body {
+			if el.Type == adaptivecard.TypeContainer {
+				hasContainer = true
+			}
+		}
+		if hasContainer != tt.wantContainer {
+			t.Errorf("Convert(%s): want container [%v], got [%v]", tt.outputFormat, tt.wantContainer, hasContainer)
+		}
+	}
+}
+
+func TestSendWebhook(t *testing.T) {
+	var got map[string]any
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if err := json.Unmarshal(body, &got); err != nil {
+			t.Errorf("SendWebhook(): invalid JSON [%s]", string(body))
+		}
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer srv.Close()
+
+	adapter := NewAdapter(srv.URL)
+	_, res, err := adapter.SendMessage(commonchat.Message{Activity: "Build passed"}, nil, nil)
+	if err != nil {
+		t.Fatalf("SendMessage(): error [%v]", err)
+	}
+	if res.StatusCode() != http.StatusAccepted {
+		t.Errorf("SendMessage(): want status [%d], got [%d]", http.StatusAccepted, res.StatusCode())
+	}
+	if got["type"] != MessageType {
+		t.Errorf("SendMessage(): want type [%s], got [%v]", MessageType, got["type"])
+	}
+}
@@ -0,0 +1,319 @@
+// Package telegram is a Telegram Bot API output adapter. Messages are
+// sent with `sendMessage` as MarkdownV2 or HTML, split to fit Telegram's
+// length limit.
+package telegram
+
+import (
+	"errors"
+	"fmt"
+	"html"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf16"
+
+	"github.com/grokify/commonchat"
+	"github.com/valyala/fasthttp"
+
+	"github.com/grokify/chathooks/pkg/adapters/adapterutil"
+)
+
+const (
+	ParseModeMarkdownV2 = "MarkdownV2"
+	ParseModeHTML       = "HTML"
+
+	MethodSendMessage = "sendMessage"
+
+	// MaxMessage is the message length limit, in UTF-16 code units.
+	MaxMessage = 4096
+	// MaxChunkRetryAfter is the longest delay waited before retrying a
+	// rate limited message after the first.
+	MaxChunkRetryAfter = 5 * time.Second
+
+	// OptionChatID and OptionParseMode are adapter configuration options,
+	// also read from the `chat_id` and `parse_mode` URL query parameters.
+	OptionChatID    = "chatId"
+	OptionParseMode = "parseMode"
+	ParamChatID     = "chat_id"
+	ParamParseMode  = "parse_mode"
+)
+
+var (
+	ErrChatIDNotSet = errors.New("telegram chat ID not set")
+	ErrEmptyMessage = errors.New("telegram message empty")
+)
+
+// Message is the `sendMessage` request body.
+type Message struct {
+	ChatID             string              `json:"chat_id"`
+	Text               string              `json:"text"`
+	ParseMode          string              `json:"parse_mode,omitempty"`
+	LinkPreviewOptions *LinkPreviewOptions `json:"link_preview_options,omitempty"`
+}
+
+type LinkPreviewOptions struct {
+	IsDisabled bool `json:"is_disabled"`
+}
+
+// ParseMode returns `HTML` for `html`, case-insensitively, and
+// `MarkdownV2` otherwise.
+func ParseMode(s string) string {
+	if strings.EqualFold(strings.TrimSpace(s), ParseModeHTML) {
+		return ParseModeHTML
+	}
+	return ParseModeMarkdownV2
+}
+
+// Convert returns the messages for a chat, split to fit `MaxMessage`.
+// Messages are split between lines, and long lines between characters.
+func Convert(ccMsg commonchat.Message, chatID, parseMode string) []Message {
+	parseMode = ParseMode(parseMode)
+	msgs := []Message{}
+	for _, text := range Split(Units(ccMsg), parseMode, MaxMessage) {
+		msgs = append(msgs, Message{
+			ChatID:             chatID,
+			Text:               text,
+			ParseMode:          parseMode,
+			LinkPreviewOptions: &LinkPreviewOptions{IsDisabled: true}})
+	}
+	return msgs
+}
+
+// Units returns the message as chathooks markdown, one unit per part,
+// with fields as `**title**: value`. Units are split only when they do
+// not fit a message on their own.
+func Units(ccMsg commonchat.Message) []string {
+	return adapterutil.MarkdownLines(ccMsg)
+}
+
+// Split renders markdown units and packs them into texts of at most
+// `maxLen` UTF-16 code units, joined by line breaks.
+func Split(units []string, parseMode string, maxLen int) []string {
+	texts := []string{}
+	cur := ""
+	for _, unit := range units {
+		for _, rendered := range renderFit(unit, parseMode, maxLen) {
+			switch {
+			case len(cur) == 0:
+				cur = rendered
+			case length(cur)+1+length(rendered) <= maxLen:
+				cur += "\n" + rendered
+			default:
+				texts = append(texts, cur)
+				cur = rendered
+			}
+		}
+	}
+	if len(cur) > 0 {
+		texts = append(texts, cur)
+	}
+	return texts
+}
+
+// renderFit renders a markdown unit, splitting it by lines and then by
+// characters until each part fits.
+func renderFit(src, parseMode string, maxLen int) []string {
+	if rendered := Render(src, parseMode); length(rendered) <= maxLen {
+		return []string{rendered}
+	}
+	var parts []string
+	if lines := strings.Split(src, "\n"); len(lines) > 1 {
+		half := len(lines) / 2
+		parts = []string{strings.Join(lines[:half], "\n"), strings.Join(lines[half:], "\n")}
+	} else {
+		runes := []rune(src)
+		if len(runes) < 2 {
+			return []string{}
+		}
+		half := len(runes) / 2
+		parts = []string{string(runes[:half]), string(runes[half:])}
+	}
+	rendered := []string{}
+	for _, part := range parts {
+		rendered = append(rendered, renderFit(part, parseMode, maxLen)...)
+	}
+	return rendered
+}
+
+func length(s string) int {
+	return len(utf16.Encode([]rune(s)))
+}
+
+var (
+	rxCodeBlock = regexp.MustCompile("(?s)```([A-Za-z0-9_+-]*)[ \t]*\n?(.*?)\n?```")
+	rxInline    = regexp.MustCompile(`\[([^\[\]]+)\]\(([^()\s]+)\)|\*\*([^*]+?)\*\*|` + "`([^`\n]+)`")
+)
+
+// Render converts chathooks markdown, with links, bold, inline code and
+// code blocks, to a Telegram parse mode, escaping other text.
+func Render(src, parseMode string) string {
+	var b strings.Builder
+	last := 0
+	for _, m := range rxCodeBlock.FindAllStringSubmatchIndex(src, -1) {
+		b.WriteString(renderInline(src[last:m[0]], parseMode))
+		b.WriteString(codeBlock(src[m[2]:m[3]], src[m[4]:m[5]], parseMode))
+		last = m[1]
+	}
+	b.WriteString(renderInline(src[last:], parseMode))
+	return b.String()
+}
+
+func renderInline(src, parseMode string) string {
+	var b strings.Builder
+	last := 0
+	for _, m := range rxInline.FindAllStringSubmatchIndex(src, -1) {
+		b.WriteString(escape(src[last:m[0]], parseMode))
+		switch {
+		case m[2] >= 0:
+			b.WriteString(link(strings.ReplaceAll(src[m[2]:m[3]], "**", ""), src[m[4]:m[5]], parseMode))
+		case m[6] >= 0:
+			b.WriteString(bold(src[m[6]:m[7]], parseMode))
+		default:
+			b.WriteString(code(src[m[8]:m[9]], parseMode))
+		}
+		last = m[1]
+	}
+	b.WriteString(escape(src[last:], parseMode))
+	return b.String()
+}
+
+var markdownV2Escaper = strings.NewReplacer(
+	`\`, `\\`, "_", `\_`, "*", `\*`, "[", `\[`, "]", `\]`, "(", `\(`, ")", `\)`,
+	"~", `\~`, "`", "\\`", ">", `\>`, "#", `\#`, "+", `\+`, "-", `\-`, "=", `\=`,
+	"|", `\|`, "{", `\{`, "}", `\}`, ".", `\.`, "!", `\!`)
+
+var markdownV2CodeEscaper = strings.NewReplacer(`\`, `\\`, "`", "\\`")
+
+var markdownV2URLEscaper = strings.NewReplacer(`\`, `\\`, ")", `\)`)
+
+// escape escapes text for a parse mode. HTML uses the entities Telegram
+// supports.
+func escape(s, parseMode string) string {
+	if parseMode == ParseModeHTML {
+		return html.EscapeString(s)
+	}
+	return markdownV2Escaper.Replace(s)
+}
+
+func bold(s, parseMode string) string {
+	if parseMode == ParseModeHTML {
+		return "<b>" + html.EscapeString(s) + "</b>"
+	}
+	return "*" + markdownV2Escaper.Replace(s) + "*"
+}
+
+func link(text, href, parseMode string) string {
+	if parseMode == ParseModeHTML {
+		return `<a href="` + html.EscapeString(href) + `">` + html.EscapeString(text) + "</a>"
+	}
+	return "[" + markdownV2Escaper.Replace(text) + "](" + markdownV2URLEscaper.Replace(href) + ")"
+}
+
+func code(s, parseMode string) string {
+	if parseMode == ParseModeHTML {
+		return "<code>" + html.EscapeString(s) + "</code>"
+	}
+	return "`" + markdownV2CodeEscaper.Replace(s) + "`"
+}
+
+func codeBlock(language, s, parseMode string) string {
+	if parseMode == ParseModeHTML {
+		if len(language) > 0 {
+			return `<pre><code class="language-` + html.EscapeString(language) + `">` + html.EscapeString(s) + "</code></pre>"
+		}
+		return "<pre>" + html.EscapeString(s) + "</pre>"
+	}
+	return "```" + language + "\n" + markdownV2CodeEscaper.Replace(s) + "\n```"
+}
+
+// SendURL returns the `sendMessage` URL and the chat ID and parse mode in
+// its query string, for a bot URL, e.g.
+// `https://api.telegram.org/bot<token>?chat_id=-1001234567890`.
+func SendURL(botURL string) (string, string, string, error) {
+	u, err := url.Parse(strings.TrimSpace(botURL))
+	if err != nil {
+		return "", "", "", err
+	} else if len(u.Scheme) == 0 || len(u.Host) == 0 {
+		return "", "", "", errors.New("telegram bot URL not absolute")
+	}
+	query := u.Query()
+	path := strings.TrimSuffix(strings.TrimRight(u.EscapedPath(), "/"), "/"+MethodSendMessage)
+	return u.Scheme + "://" + u.Host + path + "/" + MethodSendMessage,
+		query.Get(ParamChatID), query.Get(ParamParseMode), nil
+}
+
+// Adapter is a `commonchat.Adapter` for Telegram bots. `ChatID` and
+// `ParseMode` are used when not set in the URL.
+type Adapter struct {
+	Client     *fasthttp.Client
+	WebhookURL string
+	ChatID     string
+	ParseMode  string
+}
+
+// NewAdapter returns an adapter. `botURL`, used by `SendMessage`, is
+// `https://api.telegram.org/bot<token>`.
+func NewAdapter(botURL string) *Adapter {
+	return &Adapter{Client: adapterutil.NewClient(), WebhookURL: botURL}
+}
+
+// SendWebhook sends a message to a chat, as several messages when it is
+// too long. It returns the first failed response, or the last. Rate
+// limited messages after the first are retried once, so a retry of the
+// delivery does not repeat them.
+func (adapter *Adapter) SendWebhook(botURL string, ccMsg commonchat.Message, telegramMsg any, opts map[string]any) (*fasthttp.Request, *fasthttp.Response, error) {
+	sendURL, chatID, parseMode, err := SendURL(botURL)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(chatID) == 0 {
+		chatID = adapter.ChatID
+	}
+	if len(chatID) == 0 {
+		return nil, nil, ErrChatIDNotSet
+	}
+	if len(parseMode) == 0 {
+		parseMode = adapter.ParseMode
+	}
+	msgs := Convert(ccMsg, chatID, parseMode)
+	if len(msgs) == 0 {
+		return nil, nil, ErrEmptyMessage
+	}
+	var req *fasthttp.Request
+	var res *fasthttp.Response
+	for i, msg := range msgs {
+		if req != nil {
+			fasthttp.ReleaseRequest(req)
+			fasthttp.ReleaseResponse(res)
+		}
+		req, res, err = adapterutil.PostJSON(adapter.Client, sendURL, msg, nil)
+		if i > 0 && err == nil && res.StatusCode() == http.StatusTooManyRequests {
+			if wait, ok := adapterutil.RetryAfter(res); ok && wait <= MaxChunkRetryAfter {
+				fasthttp.ReleaseRequest(req)
+				fasthttp.ReleaseResponse(res)
+				time.Sleep(wait)
+				req, res, err = adapterutil.PostJSON(adapter.Client, sendURL, msg, nil)
+			}
+		}
+		if err != nil {
+			return req, res, err
+		} else if code := res.StatusCode(); code < 200 || code >= 300 {
+			if i > 0 {
+				err = fmt.Errorf("telegram message [%d] failed with status [%d]: %s", i+1, code, res.Body())
+			}
+			return req, res, err
+		}
+	}
+	return req, res, nil
+}
+
+// SendMessage sends a message to the adapter's chat.
+func (adapter *Adapter) SendMessage(ccMsg commonchat.Message, telegramMsg any, opts map[string]any) (*fasthttp.Request, *fasthttp.Response, error) {
+	return adapter.SendWebhook(adapter.WebhookURL, ccMsg, telegramMsg, opts)
+}
+
+func (adapter *Adapter) WebhookUID(ctx *fasthttp.RequestCtx) (string, error) {
+	return adapterutil.WebhookUID(ctx)
+}
@@ -0,0 +1,122 @@
+package telegram
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/grokify/commonchat"
+)
+
+var RenderTests = []struct {
+	v         string
+	parseMode string
+	want      string
+}{
+	{"Build #1 for **minimal/master** passed.", ParseModeMarkdownV2, `Build \#1 for *minimal/master* passed\.`},
+	{"[Build #1](https://example.com/builds/1)", ParseModeMarkdownV2, `[Build \#1](https://example.com/builds/1)`},
+	{"[**Build**](https://example.com/?a=1&b=2)", ParseModeHTML, `<a href="https://example.com/?a=1&amp;b=2">Build</a>`},
+	{"Run `go test` now", ParseModeMarkdownV2, "Run `go test` now"},
+	{"Run `a<b` now", ParseModeHTML, "Run <code>a&lt;b</code> now"},
+	{"Log:\n```go\nfmt.Println(`x`)\n```", ParseModeMarkdownV2, "Log:\n```go\nfmt.Println(\\`x\\`)\n```"},
+	{"Log:\n```\na < b\n```", ParseModeHTML, "Log:\n<pre>a &lt; b</pre>"}}
+
+func TestRender(t *testing.T) {
+	for _, tt := range RenderTests {
+		try := Render(tt.v, tt.parseMode)
+		if try != tt.want {
+			t.Errorf("Render(%v, %s): want [%v], got [%v]", tt.v, tt.parseMode, tt.want, try)
+		}
+	}
+}
+
+var SplitTests = []struct {
+	units  []string
+	maxLen int
+	want   []string
+}{
+	{[]string{"a", "b", "c"}, 10, []string{"a\nb\nc"}},
+	{[]string{"aaaa", "bbbb", "cc"}, 9, []string{"aaaa\nbbbb", "cc"}},
+	{[]string{"aaaa\nbbbb"}, 5, []string{"aaaa", "bbbb"}},
+	{[]string{"abcdefgh"}, 4, []string{"abcd", "efgh"}},
+	{[]string{"ab.cd.ef"}, 8, []string{`ab\.c`, `d\.ef`}}}
+
+func TestSplit(t *testing.T) {
+	for _, tt := range SplitTests {
+		try := Split(tt.units, ParseModeMarkdownV2, tt.maxLen)
+		if strings.Join(try, "|") != strings.Join(tt.want, "|") {
+			t.Errorf("Split(%v, %d): want [%v], got [%v]", tt.units, tt.maxLen, tt.want, try)
+		}
+	}
+}
+
+func TestConvertLong(t *testing.T) {
+	ccMsg := commonchat.Message{Activity: "Build passed", Text: strings.Repeat("line of text.\n", 600)}
+	msgs := Convert(ccMsg, "-100", "")
+	if len(msgs) < 2 {
+		t.Fatalf("Convert(): want several messages, got [%d]", len(msgs))
+	}
+	for _, msg := range msgs {
+		if length(msg.Text) > MaxMessage || msg.ChatID != "-100" || msg.ParseMode != ParseModeMarkdownV2 {
+			t.Errorf("Convert(): want message within limit, got [%d] [%v] [%v]", length(msg.Text), msg.ChatID, msg.ParseMode)
+		}
+	}
+}
+
+var SendURLTests = []struct {
+	botURL        string
+	wantURL       string
+	wantChatID    string
+	wantParseMode string
+}{
+	{"https://api.telegram.org/bot123:ABC", "https://api.telegram.org/bot123:ABC/sendMessage", "", ""},
+	{"https://api.telegram.org/bot123:ABC/sendMessage?chat_id=-100&parse_mode=HTML", "https://api.telegram.org/bot123:ABC/sendMessage", "-100", "HTML"}}
+
+func TestSendURL(t *testing.T) {
+	for _, tt := range SendURLTests {
+		tryURL, tryChatID, tryParseMode, err := SendURL(tt.botURL)
+		if err != nil {
+			t.Errorf("SendURL(%v): error [%v]", tt.botURL, err)
+		}
+		if tryURL != tt.wantURL || tryChatID != tt.wantChatID || tryParseMode != tt.wantParseMode {
+			t.Errorf("SendURL(%v): want [%v] [%v] [%v], got [%v] [%v] [%v]",
+				tt.botURL, tt.wantURL, tt.wantChatID, tt.wantParseMode, tryURL, tryChatID, tryParseMode)
+		}
+	}
+}
+
+func TestSendMessage(t *testing.T) {
+	got := []Message{}
+	calls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls == 2 {
+			w.WriteHeader(http.StatusTooManyRequests)
+			_, _ = w.Write([]byte(`{"ok":false,"error_code":429,"description":"Too Many Requests: retry after 0","parameters":{"retry_after":0}}`))
+			return
+		}
+		msg := Message{}
+		body, _ := io.ReadAll(r.Body)
+		_ = json.Unmarshal(body, &msg)
+		got = append(got, msg)
+		_, _ = w.Write([]byte(`{"ok":true,"result":{"message_id":1}}`))
+	}))
+	defer srv.Close()
+
+	adapter := NewAdapter(srv.URL + "/bot123:ABC")
+	adapter.ChatID = "-100"
+	ccMsg := commonchat.Message{Activity: "Build passed", Text: strings.Repeat("line of text.\n", 400)}
+	_, res, err := adapter.SendMessage(ccMsg, nil, nil)
+	if err != nil {
+		t.Fatalf("SendMessage(): error [%v]", err)
+	}
+	if res.StatusCode() != http.StatusOK || len(got) != 2 || got[0].ChatID != "-100" {
+		t.Errorf("SendMessage(): want [2] messages after a retry, got [%d] [%d]", res.StatusCode(), len(got))
+	}
+	if _, _, err := NewAdapter(srv.URL+"/bot123:ABC").SendMessage(ccMsg, nil, nil); err != ErrChatIDNotSet {
+		t.Errorf("SendMessage(): want error [%v], got [%v]", ErrChatIDNotSet, err)
+	}
+}
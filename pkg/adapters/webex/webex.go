@@ -0,0 +1,171 @@
+// Package webex is a Webex output adapter. Messages are posted as
+// markdown to incoming webhooks, or with a bot's access token to the
+// messages API with an Adaptive Card attachment.
+package webex
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/grokify/commonchat"
+	hum "github.com/grokify/mogo/net/http/httputilmore"
+	"github.com/valyala/fasthttp"
+
+	"github.com/grokify/chathooks/pkg/adapters/adapterutil"
+	"github.com/grokify/chathooks/pkg/adapters/adaptivecard"
+	"github.com/grokify/chathooks/pkg/adapters/slackcompat"
+	"github.com/grokify/chathooks/pkg/config"
+)
+
+const (
+	// MessagesURL is the messages API URL, used by bots.
+	MessagesURL = "https://webexapis.com/v1/messages"
+	// PathIncoming is the path prefix of incoming webhook URLs.
+	PathIncoming = "/v1/webhooks/incoming/"
+
+	// MaxMarkdown is the message length limit, in bytes.
+	MaxMarkdown = 7439
+	ellipsis    = "…"
+
+	// OptionRoomID and OptionAccessToken are adapter configuration
+	// options, also read from the `roomId` and `access_token` URL query
+	// parameters.
+	OptionRoomID      = "roomId"
+	OptionAccessToken = "accessToken"
+	ParamRoomID       = "roomId"
+	ParamAccessToken  = "access_token"
+)
+
+var (
+	ErrAccessTokenNotSet = errors.New("webex access token not set")
+	ErrRoomNotSet        = errors.New("webex room not set")
+)
+
+// Message is the request body for incoming webhooks and the messages API.
+// `Markdown` is shown by clients that do not render cards.
+type Message struct {
+	RoomID      string       `json:"roomId,omitempty"`
+	Markdown    string       `json:"markdown"`
+	Attachments []Attachment `json:"attachments,omitempty"`
+}
+
+// Attachment is a card attachment. Webex supports one per message.
+type Attachment struct {
+	ContentType string            `json:"contentType"`
+	Content     adaptivecard.Card `json:"content"`
+}
+
+// Convert returns the Webex message for an `outputFormat`. `card` and
+// `adaptivecard` add an Adaptive Card with attachments in containers
+// styled by their color and fields in columns. `nocard` renders markdown
+// only. Incoming webhooks do not support cards.
+func Convert(ccMsg commonchat.Message, outputFormat string) Message {
+	msg := Message{Markdown: Markdown(ccMsg)}
+	if outputFormat != config.ParamNameOutputFormatNocard {
+		msg.Attachments = []Attachment{{
+			ContentType: adaptivecard.ContentType,
+			Content:     adaptivecard.Convert(ccMsg, false)}}
+	}
+	return msg
+}
+
+// Markdown returns the message as Webex markdown, with attachment fields
+// as `**title**: value` lines, truncated to `MaxMarkdown`.
+func Markdown(ccMsg commonchat.Message) string {
+	text, _ := slackcompat.Converter{}.Convert(ccMsg, false)
+	return truncate(text, MaxMarkdown)
+}
+
+// truncate cuts `s` to at most `maxBytes`, between characters, ending it
+// with an ellipsis.
+func truncate(s string, maxBytes int) string {
+	if len(s) <= maxBytes {
+		return s
+	}
+	s = s[:maxBytes-len(ellipsis)]
+	for len(s) > 0 && !utf8.ValidString(s) {
+		s = s[:len(s)-1]
+	}
+	return s + ellipsis
+}
+
+// IsIncoming returns true if `webhookURL` is an incoming webhook URL.
+func IsIncoming(webhookURL string) bool {
+	u, err := url.Parse(strings.TrimSpace(webhookURL))
+	return err == nil && strings.HasPrefix(u.Path, PathIncoming)
+}
+
+// SendURL returns the URL to post to, without the room ID and access token
+// in its query string, which are returned.
+func SendURL(webhookURL string) (string, string, string, error) {
+	u, err := url.Parse(strings.TrimSpace(webhookURL))
+	if err != nil {
+		return "", "", "", err
+	} else if len(u.Scheme) == 0 || len(u.Host) == 0 {
+		return "", "", "", fmt.Errorf("webex URL not absolute [%s]", webhookURL)
+	}
+	query := u.Query()
+	roomID, accessToken := query.Get(ParamRoomID), query.Get(ParamAccessToken)
+	query.Del(ParamRoomID)
+	query.Del(ParamAccessToken)
+	u.RawQuery = query.Encode()
+	return u.String(), roomID, accessToken, nil
+}
+
+// Adapter is a `commonchat.Adapter` for Webex. `RoomID` and `AccessToken`
+// are used by bots when not set in the URL.
+type Adapter struct {
+	Client      *fasthttp.Client
+	WebhookURL  string
+	RoomID      string
+	AccessToken string
+}
+
+// NewAdapter returns an adapter. `webhookURL`, used by `SendMessage`, is
+// an incoming webhook URL or `MessagesURL`.
+func NewAdapter(webhookURL string) *Adapter {
+	return &Adapter{Client: adapterutil.NewClient(), WebhookURL: webhookURL}
+}
+
+// SendWebhook posts a message. Incoming webhooks are sent markdown only.
+// Other URLs are the messages API, which requires a room and an access
+// token, and are sent cards. The adapter's access token is used only for
+// `MessagesURL` and the adapter's URL.
+func (adapter *Adapter) SendWebhook(webhookURL string, ccMsg commonchat.Message, webexMsg any, opts map[string]any) (*fasthttp.Request, *fasthttp.Response, error) {
+	sendURL, roomID, accessToken, err := SendURL(webhookURL)
+	if err != nil {
+		return nil, nil, err
+	}
+	if IsIncoming(sendURL) {
+		return adapterutil.PostJSON(adapter.Client, sendURL,
+			Message{Markdown: Markdown(ccMsg)}, nil)
+	}
+	if len(roomID) == 0 {
+		roomID = adapter.RoomID
+	}
+	if len(accessToken) == 0 && (adapterutil.SameOrigin(MessagesURL, sendURL) ||
+		adapterutil.SameOrigin(adapter.WebhookURL, sendURL)) {
+		accessToken = adapter.AccessToken
+	}
+	if len(roomID) == 0 {
+		return nil, nil, ErrRoomNotSet
+	} else if len(accessToken) == 0 {
+		return nil, nil, ErrAccessTokenNotSet
+	}
+	msg := Convert(ccMsg, adapterutil.OutputFormat(opts))
+	msg.RoomID = roomID
+	return adapterutil.PostJSON(adapter.Client, sendURL, msg,
+		map[string]string{hum.HeaderAuthorization: "Bearer " + accessToken})
+}
+
+// SendMessage posts a message to the adapter's URL.
+func (adapter *Adapter) SendMessage(ccMsg commonchat.Message, webexMsg any, opts map[string]any) (*fasthttp.Request, *fasthttp.Response, error) {
+	return adapter.SendWebhook(adapter.WebhookURL, ccMsg, webexMsg, opts)
+}
+
+func (adapter *Adapter) WebhookUID(ctx *fasthttp.RequestCtx) (string, error) {
+	return adapterutil.WebhookUID(ctx)
+}
@@ -0,0 +1,126 @@
+package webex
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/grokify/commonchat"
+
+	"github.com/grokify/chathooks/pkg/adapters/adaptivecard"
+	"github.com/grokify/chathooks/pkg/config"
+)
+
+var testMessage = commonchat.Message{
+	Activity: "Build passed",
+	Text:     "[Build #1](https://example.com/builds/1) for **minimal/master** passed",
+	Attachments: []commonchat.Attachment{{
+		Color:  "#00ff00",
+		Fields: []commonchat.Field{{Title: "Branch", Value: "master", Short: true}}}}}
+
+var ConvertTests = []struct {
+	outputFormat string
+	wantCard     bool
+}{
+	{"", true},
+	{config.ParamNameOutputFormatAdaptivecard, true},
+	{config.ParamNameOutputFormatNocard, false}}
+
+func TestConvert(t *testing.T) {
+	wantMarkdown := "Build passed\n[Build #1](https://example.com/builds/1) for **minimal/master** passed\n**Branch**: master"
+	for _, tt := range ConvertTests {
+		msg := Convert(testMessage, tt.outputFormat)
+		if msg.Markdown != wantMarkdown {
+			t.Errorf("Convert(%s): want markdown [%v], got [%v]", tt.outputFormat, wantMarkdown, msg.Markdown)
+		}
+		hasCard := len(msg.Attachments) == 1 && msg.Attachments[0].ContentType == adaptivecard.ContentType
+		if hasCard != tt.wantCard {
+			t.Errorf("Convert(%s): want card [%v], got [%v]", tt.outputFormat, tt.wantCard, msg.Attachments)
+		}
+	}
+}
+
+func TestMarkdownTruncate(t *testing.T) {
+	try := Markdown(commonchat.Message{Text: strings.Repeat("é", MaxMarkdown)})
+	if len(try) > MaxMarkdown || !utf8.ValidString(try) || !strings.HasSuffix(try, ellipsis) {
+		t.Errorf("Markdown(): want valid text within [%d] bytes, got [%d]", MaxMarkdown, len(try))
+	}
+}
+
+var SendURLTests = []struct {
+	v               string
+	wantURL         string
+	wantRoomID      string
+	wantAccessToken string
+	wantIncoming    bool
+}{
+	{"https://webexapis.com/v1/webhooks/incoming/abc", "https://webexapis.com/v1/webhooks/incoming/abc", "", "", true},
+	{"https://webexapis.com/v1/messages?roomId=r1&access_token=t1", "https://webexapis.com/v1/messages", "r1", "t1", false}}
+
+func TestSendURL(t *testing.T) {
+	for _, tt := range SendURLTests {
+		tryURL, tryRoomID, tryAccessToken, err := SendURL(tt.v)
+		if err != nil {
+			t.Errorf("SendURL(%v): error [%v]", tt.v, err)
+		}
+		if tryURL != tt.wantURL || tryRoomID != tt.wantRoomID || tryAccessToken != tt.wantAccessToken ||
+			IsIncoming(tryURL) != tt.wantIncoming {
+			t.Errorf("SendURL(%v): want [%v] [%v] [%v], got [%v] [%v] [%v]",
+				tt.v, tt.wantURL, tt.wantRoomID, tt.wantAccessToken, tryURL, tryRoomID, tryAccessToken)
+		}
+	}
+}
+
+func TestSendWebhook(t *testing.T) {
+	var got Message
+	var auth string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth = r.Header.Get("Authorization")
+		body, _ := io.ReadAll(r.Body)
+		got = Message{}
+		_ = json.Unmarshal(body, &got)
+	}))
+	defer srv.Close()
+
+	adapter := NewAdapter(srv.URL + "/v1/messages")
+	if _, _, err := adapter.SendMessage(testMessage, nil, nil); err != ErrRoomNotSet {
+		t.Errorf("SendMessage(): want error [%v], got [%v]", ErrRoomNotSet, err)
+	}
+	adapter.RoomID = "r1"
+	adapter.AccessToken = "t1"
+	if _, res, err := adapter.SendMessage(testMessage, nil, nil); err != nil || res.StatusCode() != http.StatusOK {
+		t.Fatalf("SendMessage(): error [%v]", err)
+	}
+	if auth != "Bearer t1" || got.RoomID != "r1" || len(got.Attachments) != 1 {
+		t.Errorf("SendMessage(): want room [r1] with a card, got [%v] [%v]", auth, got)
+	}
+
+	if _, _, err := adapter.SendWebhook(srv.URL+PathIncoming+"abc", testMessage, nil, nil); err != nil {
+		t.Fatalf("SendWebhook(): error [%v]", err)
+	}
+	if len(auth) > 0 || len(got.RoomID) > 0 || len(got.Attachments) > 0 || len(got.Markdown) == 0 {
+		t.Errorf("SendWebhook(incoming): want markdown only, got [%v] [%v]", auth, got)
+	}
+}
+
+// TestSendWebhookOtherHost checks the adapter's access token is not sent
+// to a URL supplied by a request.
+func TestSendWebhookOtherHost(t *testing.T) {
+	calls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+	}))
+	defer srv.Close()
+	adapter := NewAdapter(MessagesURL)
+	adapter.RoomID, adapter.AccessToken = "r1", "t1"
+	if _, _, err := adapter.SendWebhook(srv.URL+"/v1/messages", testMessage, nil, nil); err != ErrAccessTokenNotSet {
+		t.Errorf("SendWebhook(%s): want error [%v], got [%v]", srv.URL, ErrAccessTokenNotSet, err)
+	}
+	if calls != 0 {
+		t.Errorf("SendWebhook(%s): want no request, got [%d]", srv.URL, calls)
+	}
+}
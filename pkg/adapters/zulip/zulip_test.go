@@ -0,0 +1,146 @@
+package zulip
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/grokify/commonchat"
+
+	"github.com/grokify/chathooks/pkg/adapters/adapterutil"
+	"github.com/grokify/chathooks/pkg/config"
+)
+
+var TopicTests = []struct {
+	tmpl string
+	want string
+}{
+	{"", "circleci"},
+	{"CircleCI: {{.Params.repo}}", "CircleCI: my-repo"},
+	{"Opsgenie: {{.Message.Title}}", "Opsgenie: alert 1234"},
+	{"{{.Params.missing}}", FallbackTopic},
+	{strings.Repeat("a", 70), strings.Repeat("a", 59) + "…"}}
+
+func TestTopic(t *testing.T) {
+	data := TopicData{
+		InputType: "circleci",
+		Message:   commonchat.Message{Title: "alert 1234"},
+		Params:    map[string]string{"repo": "my-repo"}}
+	for _, tt := range TopicTests {
+		try, err := Topic(tt.tmpl, data)
+		if err != nil {
+			t.Errorf("Topic(%v): error [%v]", tt.tmpl, err)
+		}
+		if try != tt.want {
+			t.Errorf("Topic(%v): want [%v], got [%v]", tt.tmpl, tt.want, try)
+		}
+	}
+	if _, err := Topic("{{.Params", data); err == nil {
+		t.Errorf("Topic({{.Params): want error, got [nil]")
+	}
+}
+
+var ContentTests = []struct {
+	outputFormat string
+	want         string
+}{
+	{"", "**Build passed**\n\n**minimal**\n\n| Field | Value |\n| --- | --- |\n| Branch | master |"},
+	{config.ParamNameOutputFormatNocard, "**Build passed**\n\n**minimal**\n**Branch**: master"}}
+
+func TestContent(t *testing.T) {
+	ccMsg := commonchat.Message{
+		Activity: "Build passed",
+		Attachments: []commonchat.Attachment{{
+			Title:  "minimal",
+			Fields: []commonchat.Field{{Title: "Branch", Value: "master", Short: true}}}}}
+	for _, tt := range ContentTests {
+		try := Content(ccMsg, tt.outputFormat)
+		if try != tt.want {
+			t.Errorf("Content(%s): want [%v], got [%v]", tt.outputFormat, tt.want, try)
+		}
+	}
+}
+
+var MessagesURLTests = []struct {
+	siteURL    string
+	wantURL    string
+	wantEmail  string
+	wantAPIKey string
+}{
+	{"https://example.zulipchat.com", "https://example.zulipchat.com/api/v1/messages", "", ""},
+	{"https://bot%40example.com:k1@example.zulipchat.com/api/v1/messages", "https://example.zulipchat.com/api/v1/messages", "bot@example.com", "k1"}}
+
+func TestMessagesURL(t *testing.T) {
+	for _, tt := range MessagesURLTests {
+		tryURL, tryEmail, tryAPIKey, err := MessagesURL(tt.siteURL)
+		if err != nil {
+			t.Errorf("MessagesURL(%v): error [%v]", tt.siteURL, err)
+		}
+		if tryURL != tt.wantURL || tryEmail != tt.wantEmail || tryAPIKey != tt.wantAPIKey {
+			t.Errorf("MessagesURL(%v): want [%v] [%v] [%v], got [%v] [%v] [%v]",
+				tt.siteURL, tt.wantURL, tt.wantEmail, tt.wantAPIKey, tryURL, tryEmail, tryAPIKey)
+		}
+	}
+}
+
+func TestSendMessage(t *testing.T) {
+	var got *http.Request
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			t.Errorf("server: invalid form [%v]", err)
+		}
+		got = r
+		_, _ = w.Write([]byte(`{"id":42,"msg":"","result":"success"}`))
+	}))
+	defer srv.Close()
+
+	adapter := NewAdapter(srv.URL)
+	adapter.Email, adapter.APIKey, adapter.Stream = "bot@example.com", "k1", "builds"
+	_, res, err := adapter.SendMessage(commonchat.Message{Activity: "Build passed"}, nil, map[string]any{
+		config.ParamNameInputType: "circleci",
+		config.ParamNameTopic:     "CircleCI: {{.Params.repo}}",
+		adapterutil.OptionParams:  map[string]string{"repo": "my-repo"}})
+	if err != nil {
+		t.Fatalf("SendMessage(): error [%v]", err)
+	}
+	if res.StatusCode() != http.StatusOK {
+		t.Errorf("SendMessage(): want status [%d], got [%d]", http.StatusOK, res.StatusCode())
+	}
+	if email, apiKey, ok := got.BasicAuth(); !ok || email != "bot@example.com" || apiKey != "k1" {
+		t.Errorf("SendMessage(): want basic auth, got [%v] [%v]", email, apiKey)
+	}
+	if got.URL.Path != PathMessages || got.PostForm.Get("type") != MessageTypeStream ||
+		got.PostForm.Get("to") != "builds" || got.PostForm.Get("topic") != "CircleCI: my-repo" {
+		t.Errorf("SendMessage(): want stream message, got [%v] [%v]", got.URL.Path, got.PostForm)
+	}
+}
+
+func TestSendMessageNotSet(t *testing.T) {
+	adapter := NewAdapter("https://example.zulipchat.com")
+	if _, _, err := adapter.SendMessage(commonchat.Message{}, nil, nil); err != ErrCredentialsNotSet {
+		t.Errorf("SendMessage(): want error [%v], got [%v]", ErrCredentialsNotSet, err)
+	}
+	adapter.Email, adapter.APIKey = "bot@example.com", "k1"
+	if _, _, err := adapter.SendMessage(commonchat.Message{}, nil, nil); err != ErrStreamNotSet {
+		t.Errorf("SendMessage(): want error [%v], got [%v]", ErrStreamNotSet, err)
+	}
+}
+
+// TestSendWebhookOtherSite checks the adapter's credentials are not sent
+// to a site supplied by a request.
+func TestSendWebhookOtherSite(t *testing.T) {
+	calls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+	}))
+	defer srv.Close()
+	adapter := NewAdapter("https://example.zulipchat.com")
+	adapter.Email, adapter.APIKey, adapter.Stream = "bot@example.com", "k1", "builds"
+	if _, _, err := adapter.SendWebhook(srv.URL, commonchat.Message{Activity: "Build passed"}, nil, nil); err != ErrCredentialsNotSet {
+		t.Errorf("SendWebhook(%s): want error [%v], got [%v]", srv.URL, ErrCredentialsNotSet, err)
+	}
+	if calls != 0 {
+		t.Errorf("SendWebhook(%s): want no request, got [%d]", srv.URL, calls)
+	}
+}
@@ -0,0 +1,82 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+
+	"github.com/grokify/chathooks/pkg/config"
+)
+
+// urlFlags are the query string parameters of a webhook URL.
+type urlFlags struct {
+	InputType    string
+	OutputType   string
+	OutputURL    string
+	OutputFormat string
+	Adapters     string
+	Token        string
+}
+
+func (uf *urlFlags) register(fs *flag.FlagSet, withInputType bool) {
+	if withInputType {
+		fs.StringVar(&uf.InputType, config.ParamNameInputType, "", "handler input type, e.g. `datadog`")
+	}
+	fs.StringVar(&uf.OutputType, config.ParamNameOutputType, "", "adapter type, e.g. `glip` or `slack`")
+	fs.StringVar(&uf.OutputURL, config.ParamNameOutputURL, "", "adapter webhook `URL`")
+	fs.StringVar(&uf.OutputFormat, config.ParamNameOutputFormat, "", "output format, e.g. `nocard`")
+	fs.StringVar(&uf.Adapters, config.ParamNameAdapters, "", "comma-delimited named adapters")
+	fs.StringVar(&uf.Token, config.ParamNameToken, "", "verification token")
+}
+
+// webhookURL returns `baseURL` with the query string parameters set,
+// followed by any in `extra`.
+func (uf *urlFlags) webhookURL(baseURL, inputType string, extra url.Values) (string, error) {
+	u, err := url.Parse(baseURL)
+	if err != nil {
+		return "", err
+	}
+	qry := u.Query()
+	params := [][]string{
+		{config.ParamNameInputType, inputType},
+		{config.ParamNameOutputType, uf.OutputType},
+		{config.ParamNameOutputURL, uf.OutputURL},
+		{config.ParamNameOutputFormat, uf.OutputFormat},
+		{config.ParamNameAdapters, uf.Adapters},
+		{config.ParamNameToken, uf.Token}}
+	for _, param := range params {
+		if v := strings.TrimSpace(param[1]); len(v) > 0 {
+			qry.Set(param[0], v)
+		}
+	}
+	for k, vals := range extra {
+		for _, v := range vals {
+			qry.Add(k, v)
+		}
+	}
+	u.RawQuery = qry.Encode()
+	return u.String(), nil
+}
+
+func buildURL(args []string, stdout, stderr io.Writer) error {
+	fs := newFlagSet(CommandBuildURL, stderr)
+	baseURL := fs.String("base-url", DefaultChathooksURL, "chathooks webhook `URL`")
+	query := fs.String("query", "", "additional query string parameters, e.g. `defaultIcon=robot_face`")
+	uf := urlFlags{}
+	uf.register(fs, true)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	extra, err := url.ParseQuery(*query)
+	if err != nil {
+		return err
+	}
+	hookURL, err := uf.webhookURL(*baseURL, uf.InputType, extra)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(stdout, hookURL)
+	return err
+}
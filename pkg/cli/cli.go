@@ -0,0 +1,103 @@
+// Package cli implements the `chathooks` command with the `serve`,
+// `render`, `send`, `proxy-send` and `build-url` subcommands.
+package cli
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/grokify/sogo/net/http/httpsimple"
+
+	"github.com/grokify/chathooks/pkg/config"
+	"github.com/grokify/chathooks/pkg/service"
+)
+
+const (
+	CommandServe     = "serve"
+	CommandRender    = "render"
+	CommandSend      = "send"
+	CommandProxySend = "proxy-send"
+	CommandBuildURL  = "build-url"
+	CommandHelp      = "help"
+
+	DefaultChathooksURL = "http://localhost:8080/hook"
+)
+
+const usageText = `Usage: chathooks <command> [flags]
+
+Commands:
+  serve       run the webhook proxy server (default)
+  render      run a handler over an event and print the message or adapter payload
+  send        run a handler over an event and deliver it to an adapter directly
+  proxy-send  post events to a running chathooks server
+  build-url   print a webhook URL for a handler and output
+
+Run "chathooks <command> -h" for the flags of a command.
+`
+
+// Run runs the command named by the first argument. Without a command,
+// or when the first argument is a flag, it runs `serve` so existing
+// server invocations keep working.
+func Run(ctx context.Context, args []string, stdout, stderr io.Writer) error {
+	cmd := CommandServe
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		cmd, args = args[0], args[1:]
+	}
+	var err error
+	switch cmd {
+	case CommandServe:
+		err = serve(ctx, args, stdout, stderr)
+	case CommandRender:
+		err = render(args, stdout, stderr)
+	case CommandSend:
+		err = send(args, stdout, stderr)
+	case CommandProxySend:
+		err = proxySend(args, stdout, stderr)
+	case CommandBuildURL:
+		err = buildURL(args, stdout, stderr)
+	case CommandHelp:
+		fmt.Fprint(stdout, usageText)
+	default:
+		fmt.Fprint(stderr, usageText)
+		return fmt.Errorf("unknown command [%s]", cmd)
+	}
+	if errors.Is(err, flag.ErrHelp) {
+		return nil
+	}
+	return err
+}
+
+func serve(ctx context.Context, args []string, stdout, stderr io.Writer) error {
+	// Parse once up front so flag errors and `-h` are reported.
+	if _, err := config.LoadConfigurationOutput(args, stderr); err != nil {
+		return err
+	}
+	svc, err := service.NewReloader(func() (config.Configuration, error) {
+		return config.LoadConfiguration(args)
+	})
+	if err != nil {
+		return err
+	}
+	go svc.Watch(ctx)
+
+	if svc.HTTPEngine() == httpsimple.EngineAWSLambda {
+		fmt.Fprintln(stdout, "Starting AWS Lambda handler.")
+		lambda.StartWithOptions(svc.HandleLambdaEvent, lambda.WithContext(ctx))
+		return nil
+	}
+	fmt.Fprintf(stdout, "Starting on port [%d] with engine [%s].\n",
+		svc.PortInt(), svc.HTTPEngine())
+	httpsimple.Serve(svc)
+	return nil
+}
+
+func newFlagSet(name string, stderr io.Writer) *flag.FlagSet {
+	fs := flag.NewFlagSet("chathooks "+name, flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	return fs
+}
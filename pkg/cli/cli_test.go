@@ -0,0 +1,64 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"strings"
+	"testing"
+)
+
+var BuildURLTests = []struct {
+	args []string
+	want string
+}{
+	{[]string{"-inputType", "aha", "-outputType", "glip", "-outputURL", "https://hooks.glip.com/webhook/1"},
+		"http://localhost:8080/hook?inputType=aha&outputType=glip&outputURL=https%3A%2F%2Fhooks.glip.com%2Fwebhook%2F1"},
+	{[]string{"-base-url", "https://example.com/hook/dd", "-token", "abc", "-query", "defaultIcon=robot_face"},
+		"https://example.com/hook/dd?defaultIcon=robot_face&token=abc"}}
+
+func TestBuildURL(t *testing.T) {
+	for _, tt := range BuildURLTests {
+		stdout := &bytes.Buffer{}
+		if err := Run(context.Background(), append([]string{CommandBuildURL}, tt.args...), stdout, io.Discard); err != nil {
+			t.Fatalf("Run(build-url %v): error [%v]", tt.args, err)
+		}
+		if got := strings.TrimSpace(stdout.String()); got != tt.want {
+			t.Errorf("Run(build-url %v): want [%s], got [%s]", tt.args, tt.want, got)
+		}
+	}
+}
+
+var RenderTests = []struct {
+	args    []string
+	wantKey string
+}{
+	{[]string{"-inputType", "datadog", "-example", "formatted1"}, "title"},
+	{[]string{"-inputType", "datadog", "-example", "formatted1", "-outputType", "glip"}, "icon"},
+	{[]string{"-inputType", "heroku", "-example", "build", "-outputType", "slack"}, "attachments"}}
+
+func TestRender(t *testing.T) {
+	for _, tt := range RenderTests {
+		stdout := &bytes.Buffer{}
+		if err := Run(context.Background(), append([]string{CommandRender}, tt.args...), stdout, io.Discard); err != nil {
+			t.Fatalf("Run(render %v): error [%v]", tt.args, err)
+		}
+		out := map[string]any{}
+		if err := json.Unmarshal(stdout.Bytes(), &out); err != nil {
+			t.Fatalf("Run(render %v): invalid JSON [%v]", tt.args, err)
+		}
+		if _, ok := out[tt.wantKey]; !ok {
+			t.Errorf("Run(render %v): want key [%s], got [%s]", tt.args, tt.wantKey, stdout.String())
+		}
+	}
+}
+
+func TestSendUnknownOutputType(t *testing.T) {
+	args := []string{CommandSend, "-inputType", "heroku", "-example", "build",
+		"-outputType", "slak", "-outputURL", "http://127.0.0.1:1/hook"}
+	err := Run(context.Background(), args, io.Discard, io.Discard)
+	if err == nil || !strings.Contains(err.Error(), "slak") {
+		t.Errorf("Run(send -outputType slak): want unknown output type error, got [%v]", err)
+	}
+}
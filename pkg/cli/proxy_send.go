@@ -0,0 +1,111 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/grokify/mogo/net/http/httputilmore"
+	"github.com/valyala/fasthttp"
+
+	"github.com/grokify/chathooks/pkg/util"
+)
+
+// proxySend posts events to a running chathooks server. Without `-file`
+// or `-example`, every embedded example for each input type is sent.
+func proxySend(args []string, stdout, stderr io.Writer) error {
+	fs := newFlagSet(CommandProxySend, stderr)
+	chathooksURL := fs.String("chathooks-url", DefaultChathooksURL, "chathooks webhook `URL`")
+	ef := eventFlags{}
+	fs.StringVar(&ef.InputType, "inputType", "", "comma-delimited handler input types, e.g. `datadog,heroku`")
+	fs.StringVar(&ef.File, "file", "", "event body file, or `-` for stdin")
+	fs.StringVar(&ef.Example, "example", "", "embedded example event `slug`")
+	fs.StringVar(&ef.Query, "query", "", "additional query string parameters, e.g. `defaultIcon=robot_face`")
+	uf := urlFlags{}
+	uf.register(fs, false)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	inputTypes := strings.Split(ef.InputType, ",")
+	if len(strings.TrimSpace(ef.InputType)) == 0 {
+		return errors.New("-inputType is required")
+	}
+	if len(ef.File) > 0 && len(inputTypes) > 1 {
+		return errors.New("-file requires a single -inputType")
+	}
+	extra, err := ef.queryValues()
+	if err != nil {
+		return err
+	}
+	exampleData, err := util.NewExampleData()
+	if err != nil {
+		return err
+	}
+
+	failed := 0
+	for _, inputType := range inputTypes {
+		inputType = strings.ToLower(strings.TrimSpace(inputType))
+		hookURL, err := uf.webhookURL(*chathooksURL, inputType, extra)
+		if err != nil {
+			return err
+		}
+		var names []string
+		var bodies [][]byte
+		if len(ef.File) > 0 || len(ef.Example) > 0 {
+			one := ef
+			one.InputType = inputType
+			body, err := one.body(os.Stdin)
+			if err != nil {
+				return err
+			}
+			names = append(names, ef.File+ef.Example)
+			bodies = append(bodies, body)
+		} else {
+			slugs := exampleData.EventSlugs(inputType)
+			if len(slugs) == 0 {
+				return fmt.Errorf("no example events for [%s]", inputType)
+			}
+			for _, slug := range slugs {
+				body, err := exampleData.ExampleMessageBytes(inputType, slug)
+				if err != nil {
+					return err
+				}
+				names = append(names, slug)
+				bodies = append(bodies, body)
+			}
+		}
+		for i, name := range names {
+			status, err := postEvent(hookURL, bodies[i])
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(stdout, "%s %s %d\n", inputType, name, status)
+			if status >= http.StatusMultipleChoices {
+				failed++
+			}
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("[%d] events failed", failed)
+	}
+	return nil
+}
+
+func postEvent(hookURL string, body []byte) (int, error) {
+	req := fasthttp.AcquireRequest()
+	res := fasthttp.AcquireResponse()
+	defer fasthttp.ReleaseRequest(req)
+	defer fasthttp.ReleaseResponse(res)
+
+	req.SetBody(body)
+	req.Header.SetRequestURI(hookURL)
+	req.Header.SetMethod(http.MethodPost)
+	req.Header.Set(httputilmore.HeaderContentType, bodyContentType(body))
+	if err := (&fasthttp.Client{}).Do(req, res); err != nil {
+		return 0, err
+	}
+	return res.StatusCode(), nil
+}
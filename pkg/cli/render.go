@@ -0,0 +1,197 @@
+package cli
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/grokify/commonchat"
+	"github.com/grokify/mogo/net/http/httputilmore"
+
+	"github.com/grokify/chathooks/pkg/adapters"
+	"github.com/grokify/chathooks/pkg/config"
+	"github.com/grokify/chathooks/pkg/handlers"
+	"github.com/grokify/chathooks/pkg/models"
+	"github.com/grokify/chathooks/pkg/service"
+	"github.com/grokify/chathooks/pkg/util"
+)
+
+// eventFlags selects the handler and event for `render`, `send` and
+// `proxy-send`.
+type eventFlags struct {
+	InputType string
+	File      string
+	Example   string
+	Query     string
+}
+
+func (ef *eventFlags) register(fs *flag.FlagSet) {
+	fs.StringVar(&ef.InputType, config.ParamNameInputType, "", "handler input type, e.g. `datadog`")
+	fs.StringVar(&ef.File, "file", "", "event body file, or `-` for stdin")
+	fs.StringVar(&ef.Example, "example", "", "embedded example event `slug`, used when -file is not set")
+	fs.StringVar(&ef.Query, "query", "", "additional query string parameters for the handler, e.g. `defaultIcon=robot_face`")
+}
+
+func (ef *eventFlags) queryValues() (url.Values, error) {
+	return url.ParseQuery(ef.Query)
+}
+
+// body returns the event body from the file or embedded example.
+func (ef *eventFlags) body(stdin io.Reader) ([]byte, error) {
+	switch {
+	case ef.File == "-":
+		return io.ReadAll(stdin)
+	case len(ef.File) > 0:
+		return os.ReadFile(ef.File)
+	case len(ef.Example) > 0:
+		data, err := util.NewExampleData()
+		if err != nil {
+			return nil, err
+		}
+		return data.ExampleMessageBytes(ef.InputType, ef.Example)
+	default:
+		return nil, errors.New("one of -file or -example is required")
+	}
+}
+
+// hookData returns the hook data for the event, as the service builds it
+// for a request with the same body and query string parameters.
+func (ef *eventFlags) hookData(handler handlers.Handler, stdin io.Reader) (models.HookData, error) {
+	body, err := ef.body(stdin)
+	if err != nil {
+		return models.HookData{}, err
+	}
+	qry, err := ef.queryValues()
+	if err != nil {
+		return models.HookData{}, err
+	}
+	return models.HookData{
+		InputType:         ef.InputType,
+		InputBody:         models.BodyToMessageBytes(handler.MessageBodyType, bodyContentType(body), body),
+		CustomQueryParams: qry}, nil
+}
+
+// bodyContentType returns the content type an event body is posted with.
+func bodyContentType(body []byte) string {
+	if json.Valid(body) {
+		return httputilmore.ContentTypeAppJSONUtf8
+	}
+	return httputilmore.ContentTypeAppFormURLEncodedUtf8
+}
+
+// newHandler returns the handler for an input type, configured from the
+// environment and configuration file.
+func newHandler(inputType string) (handlers.Handler, error) {
+	if len(strings.TrimSpace(inputType)) == 0 {
+		return handlers.Handler{}, errors.New("-inputType is required")
+	}
+	cfg, err := config.LoadConfiguration(nil)
+	if err != nil {
+		return handlers.Handler{}, err
+	}
+	if err := cfg.ConfigureLogger(); err != nil {
+		return handlers.Handler{}, err
+	}
+	svc, err := service.NewServiceConfig(cfg)
+	if err != nil {
+		return handlers.Handler{}, err
+	}
+	handler, ok := svc.Handler(inputType)
+	if !ok {
+		return handler, fmt.Errorf("unknown input type [%s]", inputType)
+	}
+	return handler, nil
+}
+
+func render(args []string, stdout, stderr io.Writer) error {
+	fs := newFlagSet(CommandRender, stderr)
+	ef := eventFlags{}
+	ef.register(fs)
+	outputType := fs.String(config.ParamNameOutputType, "", "adapter type to render the payload for, e.g. `glip`; the `commonchat.Message` when empty")
+	outputFormat := fs.String(config.ParamNameOutputFormat, "", "output format, e.g. `nocard`")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	handler, err := newHandler(ef.InputType)
+	if err != nil {
+		return err
+	}
+	hookData, err := ef.hookData(handler, os.Stdin)
+	if err != nil {
+		return err
+	}
+	ccMsg, err := handler.NormalizeHookData(hookData)
+	if err != nil {
+		return err
+	}
+	hookData.OutputFormat = config.MustParseOutputFormat(*outputFormat)
+	return writeJSON(stdout, ccMsg, *outputType, adapters.HookDataOptions(hookData))
+}
+
+func writeJSON(w io.Writer, ccMsg commonchat.Message, outputType string, opts map[string]any) error {
+	var out any = ccMsg
+	if len(strings.TrimSpace(outputType)) > 0 {
+		payload, err := adapters.ConvertMessage(outputType, ccMsg, opts)
+		if err != nil {
+			return err
+		}
+		out = payload
+	}
+	bytes, err := json.MarshalIndent(out, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(w, string(bytes))
+	return err
+}
+
+func send(args []string, stdout, stderr io.Writer) error {
+	fs := newFlagSet(CommandSend, stderr)
+	ef := eventFlags{}
+	ef.register(fs)
+	outputType := fs.String(config.ParamNameOutputType, "", "adapter type, e.g. `glip` or `slack`")
+	outputURL := fs.String(config.ParamNameOutputURL, "", "adapter webhook `URL`")
+	outputFormat := fs.String(config.ParamNameOutputFormat, "", "output format, e.g. `nocard`")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if len(*outputType) == 0 || len(*outputURL) == 0 {
+		return errors.New("-outputType and -outputURL are required")
+	} else if !slices.Contains(adapters.AdapterTypes(), *outputType) {
+		return fmt.Errorf("unknown -outputType [%s], want one of [%s]",
+			*outputType, strings.Join(adapters.AdapterTypes(), ", "))
+	}
+	handler, err := newHandler(ef.InputType)
+	if err != nil {
+		return err
+	}
+	hookData, err := ef.hookData(handler, os.Stdin)
+	if err != nil {
+		return err
+	}
+	hookData.OutputType = *outputType
+	hookData.OutputURL = *outputURL
+	hookData.OutputFormat = config.MustParseOutputFormat(*outputFormat)
+
+	result := handler.HandleCanonical(hookData)
+	if statusCode := result.StatusCode(); statusCode != http.StatusOK {
+		for _, e := range result.Errors {
+			fmt.Fprintf(stderr, "FAILED %s status [%d] message [%s]\n", e.Type, e.StatusCode, e.Message)
+		}
+		for _, del := range result.Failed() {
+			fmt.Fprintf(stderr, "FAILED adapter [%s] host [%s] status [%d] error [%s]\n", del.Adapter, del.Host, del.StatusCode, del.Error)
+		}
+		return fmt.Errorf("delivery failed with status [%d]", statusCode)
+	} else if len(result.Deliveries) == 0 {
+		return fmt.Errorf("no delivery to [%s]", *outputType)
+	}
+	fmt.Fprintf(stdout, "SENT [%s] to [%s]\n", ef.InputType, *outputType)
+	return nil
+}
@@ -1,11 +1,11 @@
 package config
 
 import (
-	"encoding/json"
 	"net/url"
 	"os"
 	"path"
 	"strconv"
+	"strings"
 
 	env "github.com/caarlos0/env/v9"
 	"github.com/rs/zerolog"
@@ -13,7 +13,8 @@ import (
 
 const (
 	DocsHandlersSrcDir = "github.com/grokify/chathooks/docs/handlers"
-	IconBaseURL        = "http://grokify.github.io/chathooks/icons/"
+	IconBaseURL        = "https://grokify.github.io/chathooks/icons/"
+	IconPath           = "/icons/"
 	EmojiURLFormat     = "https://grokify.github.io/emoji/assets/images/%s.png"
 
 	InfoInputMessageParseBegin   = "INFO - Input Message Parse Begin"
@@ -24,37 +25,67 @@ func DocsHandlersDir() string {
 	return path.Join(os.Getenv("GOPATH"), "src", DocsHandlersSrcDir)
 }
 
-// Configuration is the webhook proxy configuration struct.
+// Configuration is the webhook proxy configuration struct. Values are
+// loaded from defaults, a YAML or JSON file, environment variables and
+// command-line flags, in increasing order of precedence.
 type Configuration struct {
-	Port           int      `env:"PORT" envDefault:"3000"`
-	Engine         string   `env:"CHATHOOKS_ENGINE" envDefault:"fasthttp"`
-	HomeURL        string   `env:"CHATHOOKS_HOME_URL"`
-	WebhookURL     string   `env:"CHATHOOKS_WEBHOOK_URL"`
-	Tokens         []string `env:"CHATHOOKS_TOKENS" envSeparator:","`
-	LogFormat      string   `env:"CHATHOOKS_LOG_FORMAT"`
-	EmojiURLFormat string
-	IconBaseURL    string
-	LogLevel       zerolog.Level
+	Port           int                    `json:"port,omitempty" yaml:"port,omitempty" env:"PORT" envDefault:"3000"`
+	Engine         string                 `json:"engine,omitempty" yaml:"engine,omitempty" env:"CHATHOOKS_ENGINE" envDefault:"fasthttp"`
+	HomeURL        string                 `json:"homeURL,omitempty" yaml:"homeURL,omitempty" env:"CHATHOOKS_HOME_URL"`
+	WebhookURL     string                 `json:"webhookURL,omitempty" yaml:"webhookURL,omitempty" env:"CHATHOOKS_WEBHOOK_URL"`
+	Tokens         []string               `json:"tokens,omitempty" yaml:"tokens,omitempty" env:"CHATHOOKS_TOKENS" envSeparator:","`
+	LogFormat      string                 `json:"logFormat,omitempty" yaml:"logFormat,omitempty" env:"CHATHOOKS_LOG_FORMAT" envDefault:"json"` // `json` or `console`
+	LogLevel       zerolog.Level          `json:"logLevel,omitempty" yaml:"logLevel,omitempty" env:"CHATHOOKS_LOG_LEVEL" envDefault:"info"`
+	EmojiURLFormat string                 `json:"emojiURLFormat,omitempty" yaml:"emojiURLFormat,omitempty" env:"CHATHOOKS_EMOJI_URL_FORMAT" envDefault:"https://grokify.github.io/emoji/assets/images/%s.png"`
+	IconBaseURL    string                 `json:"iconBaseURL,omitempty" yaml:"iconBaseURL,omitempty" env:"CHATHOOKS_ICON_BASE_URL"` // defaults to `HomeURL` + `/icons/`
+	CaptureDir     string                 `json:"captureDir,omitempty" yaml:"captureDir,omitempty" env:"CHATHOOKS_CAPTURE_DIR"`
+	CaptureMax     int                    `json:"captureMax,omitempty" yaml:"captureMax,omitempty" env:"CHATHOOKS_CAPTURE_MAX" envDefault:"100"` // requests captured per input type
+	AdminToken     string                 `json:"adminToken,omitempty" yaml:"adminToken,omitempty" env:"CHATHOOKS_ADMIN_TOKEN"`
+	AdminHistory   int                    `json:"adminHistory,omitempty" yaml:"adminHistory,omitempty" env:"CHATHOOKS_ADMIN_HISTORY" envDefault:"100"`
+	ResponseMode   string                 `json:"responseMode,omitempty" yaml:"responseMode,omitempty" env:"CHATHOOKS_RESPONSE_MODE" envDefault:"summary"` // `minimal`, `summary` or `debug`
+	QueueURL       string                 `json:"queueURL,omitempty" yaml:"queueURL,omitempty" env:"CHATHOOKS_QUEUE_URL"`                                  // SQS queue for asynchronous delivery
+	Adapters       []AdapterConfig        `json:"adapters,omitempty" yaml:"adapters,omitempty"`
+	Routes         map[string]RouteConfig `json:"routes,omitempty" yaml:"routes,omitempty"`
+	ConfigFile     string                 `json:"-" yaml:"-" env:"CHATHOOKS_CONFIG_FILE"`
 }
 
+// AdapterConfig is a named output adapter. Named adapters are selected
+// with the `adapters` query string parameter or a route.
+type AdapterConfig struct {
+	Name    string         `json:"name" yaml:"name"`
+	Type    string         `json:"type" yaml:"type"` // e.g. `glip`, `slack`
+	URL     string         `json:"url,omitempty" yaml:"url,omitempty"`
+	Options map[string]any `json:"options,omitempty" yaml:"options,omitempty"`
+}
+
+// RouteConfig presets the parameters for webhooks posted to
+// `/hook/{route}` or with the `route` query string parameter, so
+// secrets such as output URLs do not need to be in the webhook URL.
+// Query string parameters take precedence over route values.
+type RouteConfig struct {
+	InputType    string            `json:"inputType,omitempty" yaml:"inputType,omitempty"`
+	OutputType   string            `json:"outputType,omitempty" yaml:"outputType,omitempty"`
+	OutputURL    string            `json:"outputURL,omitempty" yaml:"outputURL,omitempty"`
+	OutputFormat string            `json:"outputFormat,omitempty" yaml:"outputFormat,omitempty"`
+	Adapters     []string          `json:"adapters,omitempty" yaml:"adapters,omitempty"`
+	Params       map[string]string `json:"params,omitempty" yaml:"params,omitempty"`
+	// Recipients are the email addresses for `email` adapters. They
+	// cannot be set by the request.
+	Recipients []string `json:"recipients,omitempty" yaml:"recipients,omitempty"`
+}
+
+// NewConfigurationEnv returns a configuration from defaults and environment variables.
 func NewConfigurationEnv() (Configuration, error) {
 	cfg := Configuration{}
-	if err := env.Parse(&cfg); err != nil {
-		return cfg, err
-	}
-	cfg.EmojiURLFormat = EmojiURLFormat
-	cfg.IconBaseURL = IconBaseURL
-	cfg.LogLevel = 1
-	return cfg, nil
+	err := env.Parse(&cfg)
+	return cfg, err
 }
 
+// ReadConfigurationFile reads a YAML or JSON configuration file. Fields
+// not in the file are left empty.
 func ReadConfigurationFile(filepath string) (Configuration, error) {
 	var configuration Configuration
-	bytes, err := os.ReadFile(filepath)
-	if err != nil {
-		return configuration, err
-	}
-	err = json.Unmarshal(bytes, &configuration)
+	err := readConfigurationFile(filepath, &configuration)
 	return configuration, err
 }
 
@@ -63,6 +94,28 @@ func (c *Configuration) Address() string {
 	return ":" + strconv.Itoa(c.Port)
 }
 
+// GetAppIconURL returns the icon URL for a handler. Icons are served by
+// chathooks at `/icons/`, so the base URL defaults to the `HomeURL`. It
+// falls back to the public `IconBaseURL` when neither is set.
 func (c *Configuration) GetAppIconURL(appSlug string) (*url.URL, error) {
-	return buildIconURL(c.IconBaseURL, appSlug)
+	return buildIconURL(c.iconBaseURL(), appSlug)
+}
+
+func (c *Configuration) iconBaseURL() string {
+	if len(strings.TrimSpace(c.IconBaseURL)) > 0 {
+		return c.IconBaseURL
+	}
+	if homeURL := strings.TrimSpace(c.HomeURL); len(homeURL) > 0 {
+		return strings.TrimRight(homeURL, "/") + IconPath
+	}
+	return IconBaseURL
+}
+
+// Route returns the route configuration for `name`.
+func (c *Configuration) Route(name string) (RouteConfig, bool) {
+	if len(name) == 0 || c.Routes == nil {
+		return RouteConfig{}, false
+	}
+	route, ok := c.Routes[name]
+	return route, ok
 }
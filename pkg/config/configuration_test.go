@@ -21,3 +21,27 @@ func TestConfigurationAddress(t *testing.T) {
 		}
 	}
 }
+
+var GetAppIconURLTests = []struct {
+	homeURL     string
+	iconBaseURL string
+	appSlug     string
+	want        string
+}{
+	{"https://chathooks.example.com", "", "datadog", "https://chathooks.example.com/icons/icon_datadog_512x512.png"},
+	{"https://example.com/chathooks/", "", "unknown", "https://example.com/chathooks/icons/icon_webhookrc_512x512.png"},
+	{"https://chathooks.example.com", "https://cdn.example.com/i/", "heroku", "https://cdn.example.com/i/icon_heroku_512x512.png"},
+	{"", "", "heroku", IconBaseURL + "icon_heroku_512x512.png"}}
+
+func TestGetAppIconURL(t *testing.T) {
+	for _, tt := range GetAppIconURLTests {
+		cfg := Configuration{HomeURL: tt.homeURL, IconBaseURL: tt.iconBaseURL}
+		iconURL, err := cfg.GetAppIconURL(tt.appSlug)
+		if err != nil {
+			t.Fatalf("Configuration.GetAppIconURL(%s): error [%v]", tt.appSlug, err)
+		}
+		if iconURL.String() != tt.want {
+			t.Errorf("Configuration.GetAppIconURL(%s): want [%s], got [%s]", tt.appSlug, tt.want, iconURL.String())
+		}
+	}
+}
@@ -1,6 +1,9 @@
 package config
 
-import "strings"
+import (
+	"errors"
+	"strings"
+)
 
 const (
 	ParamNameAdapters        = "adapters"
@@ -11,20 +14,54 @@ const (
 	ParamNameOutputFormat    = "outputFormat" // `card`, `adaptivecard`, `nocard`. Default is `card`.
 	ParamNameOutputURL       = "outputURL"
 	ParamNameToken           = "token"
+	ParamNameRoute           = "route"
+	ParamNameChannel         = "channel"  // channel override for adapters that support it
+	ParamNameUsername        = "username" // sender name override
+	ParamNameIconURL         = "icon_url" // sender icon override
+	ParamNameStream          = "stream"   // Zulip stream
+	ParamNameTopic           = "topic"    // Zulip topic template
 	EnvPath                  = "ENV_PATH"
 	EnvEngine                = "CHATHOOKS_ENGINE" // awslambda, nethttp, fasthttp
 	EnvTokens                = "CHATHOOKS_TOKENS"
 	EnvWebhookURL            = "CHATHOOKS_URL"
 	EnvHomeURL               = "CHATHOOKS_HOME_URL"
+	EnvCaptureDir            = "CHATHOOKS_CAPTURE_DIR" // write inbound requests as example events
+	EnvCaptureMax            = "CHATHOOKS_CAPTURE_MAX"
+	EnvAdminToken            = "CHATHOOKS_ADMIN_TOKEN" // enables the admin page
+	EnvAdminHistory          = "CHATHOOKS_ADMIN_HISTORY"
+	EnvConfigFile            = "CHATHOOKS_CONFIG_FILE" // YAML or JSON, reloaded on SIGHUP or change
+	EnvLogFormat             = "CHATHOOKS_LOG_FORMAT"
+	EnvLogLevel              = "CHATHOOKS_LOG_LEVEL"
+	EnvResponseMode          = "CHATHOOKS_RESPONSE_MODE"
 	ErrRequiredTokenNotFound = "401.01 Required Token Not Found"
 	ErrRequiredTokenNotValid = "401.02 Required Token Not Valid"
 	// ParamNameURL             = "url" // legacy. deprecated.
 
+	LogFormatJSON    = "json"
+	LogFormatConsole = "console"
+
 	ParamNameOutputFormatNocard       = "nocard"
 	ParamNameOutputFormatCard         = "card"
 	ParamNameOutputFormatAdaptivecard = "adaptivecard"
+
+	ResponseModeMinimal = "minimal" // status code and error types only
+	ResponseModeSummary = "summary" // errors and delivery results, the default
+	ResponseModeDebug   = "debug"   // also the redacted hook data
 )
 
+// ParseResponseMode returns the response mode, defaulting to `summary`.
+func ParseResponseMode(input string) (string, error) {
+	input = strings.ToLower(strings.TrimSpace(input))
+	switch input {
+	case "":
+		return ResponseModeSummary, nil
+	case ResponseModeMinimal, ResponseModeSummary, ResponseModeDebug:
+		return input, nil
+	default:
+		return "", errors.New("unknown response mode [" + input + "]")
+	}
+}
+
 func MustParseOutputFormat(input string) string {
 	input = strings.ToLower(strings.TrimSpace(input))
 	switch input {
@@ -0,0 +1,194 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+
+	env "github.com/caarlos0/env/v9"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+	"gopkg.in/yaml.v3"
+
+	"github.com/grokify/chathooks/pkg/redact"
+)
+
+const (
+	FlagConfig = "config"
+)
+
+// LoadConfiguration returns a configuration built from, in increasing
+// order of precedence: defaults, the configuration file set with the
+// `-config` flag or `CHATHOOKS_CONFIG_FILE`, environment variables and
+// command-line flags in `args`.
+func LoadConfiguration(args []string) (Configuration, error) {
+	return loadConfiguration(args, envMap(os.Environ()), io.Discard)
+}
+
+// LoadConfigurationOutput is `LoadConfiguration` writing flag usage and
+// errors to `output`.
+func LoadConfigurationOutput(args []string, output io.Writer) (Configuration, error) {
+	return loadConfiguration(args, envMap(os.Environ()), output)
+}
+
+func loadConfiguration(args []string, environ map[string]string, output io.Writer) (Configuration, error) {
+	cfg := Configuration{}
+	// Defaults only.
+	if err := env.ParseWithOptions(&cfg, env.Options{Environment: map[string]string{}}); err != nil {
+		return cfg, err
+	}
+
+	fs, flagCfg := newFlagSet(output)
+	if err := fs.Parse(args); err != nil {
+		return cfg, err
+	}
+
+	cfgFile := strings.TrimSpace(environ[EnvConfigFile])
+	if f := fs.Lookup(FlagConfig); f != nil && len(f.Value.String()) > 0 {
+		cfgFile = f.Value.String()
+	}
+	if len(cfgFile) > 0 {
+		if err := readConfigurationFile(cfgFile, &cfg); err != nil {
+			return cfg, err
+		}
+	}
+
+	if err := overlayEnv(&cfg, environ); err != nil {
+		return cfg, err
+	}
+	cfg.ConfigFile = cfgFile
+
+	var flagErr error
+	fs.Visit(func(f *flag.Flag) {
+		if apply, ok := flagCfg[f.Name]; ok && flagErr == nil {
+			flagErr = apply(&cfg, f.Value.String())
+		}
+	})
+	if flagErr != nil {
+		return cfg, flagErr
+	}
+	mode, err := ParseResponseMode(cfg.ResponseMode)
+	cfg.ResponseMode = mode
+	return cfg, err
+}
+
+// newFlagSet returns the command-line flags and a function per flag
+// that applies its value to a configuration.
+func newFlagSet(output io.Writer) (*flag.FlagSet, map[string]func(*Configuration, string) error) {
+	fs := flag.NewFlagSet("chathooks", flag.ContinueOnError)
+	fs.SetOutput(output)
+	fs.String(FlagConfig, "", "YAML or JSON configuration file")
+	apply := map[string]func(*Configuration, string) error{
+		"port": func(c *Configuration, v string) (err error) {
+			c.Port, err = strconv.Atoi(v)
+			return
+		},
+		"engine":           func(c *Configuration, v string) error { c.Engine = v; return nil },
+		"home-url":         func(c *Configuration, v string) error { c.HomeURL = v; return nil },
+		"webhook-url":      func(c *Configuration, v string) error { c.WebhookURL = v; return nil },
+		"tokens":           func(c *Configuration, v string) error { c.Tokens = strings.Split(v, ","); return nil },
+		"log-format":       func(c *Configuration, v string) error { c.LogFormat = v; return nil },
+		"log-level":        func(c *Configuration, v string) error { return c.LogLevel.UnmarshalText([]byte(v)) },
+		"icon-base-url":    func(c *Configuration, v string) error { c.IconBaseURL = v; return nil },
+		"emoji-url-format": func(c *Configuration, v string) error { c.EmojiURLFormat = v; return nil },
+		"capture-dir":      func(c *Configuration, v string) error { c.CaptureDir = v; return nil },
+		"capture-max": func(c *Configuration, v string) (err error) {
+			c.CaptureMax, err = strconv.Atoi(v)
+			return
+		},
+		"response-mode": func(c *Configuration, v string) error { c.ResponseMode = v; return nil },
+		"queue-url":     func(c *Configuration, v string) error { c.QueueURL = v; return nil },
+		"admin-history": func(c *Configuration, v string) (err error) {
+			c.AdminHistory, err = strconv.Atoi(v)
+			return
+		},
+	}
+	usage := map[string]string{
+		"port":             "port to listen on",
+		"engine":           "HTTP engine: `fasthttp`, `nethttp` or `awslambda`",
+		"home-url":         "home page URL",
+		"webhook-url":      "webhook URL shown on the home page",
+		"tokens":           "comma-delimited list of verification tokens",
+		"log-format":       "log format: `json` or `console`",
+		"log-level":        "log level, e.g. `debug`, `info`, `warn`",
+		"icon-base-url":    "base URL for handler icons",
+		"emoji-url-format": "URL format for emoji images",
+		"capture-dir":      "directory to capture inbound requests to",
+		"capture-max":      "number of requests captured per input type",
+		"response-mode":    "webhook response body: `minimal`, `summary` or `debug`",
+		"queue-url":        "SQS queue URL to queue webhooks to for asynchronous delivery",
+		"admin-history":    "number of recent events kept for the admin page",
+	}
+	for name := range apply {
+		fs.String(name, "", usage[name])
+	}
+	return fs, apply
+}
+
+func readConfigurationFile(filename string, cfg *Configuration) error {
+	bytes, err := os.ReadFile(filename)
+	if err != nil {
+		return err
+	}
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(bytes, cfg)
+	default:
+		err = json.Unmarshal(bytes, cfg)
+	}
+	if err != nil {
+		return fmt.Errorf("cannot parse configuration file [%s]: %w", filename, err)
+	}
+	return nil
+}
+
+// overlayEnv sets the fields whose environment variables are present,
+// leaving defaults and file values for the others.
+func overlayEnv(cfg *Configuration, environ map[string]string) error {
+	envCfg := Configuration{}
+	if err := env.ParseWithOptions(&envCfg, env.Options{Environment: environ}); err != nil {
+		return err
+	}
+	dst := reflect.ValueOf(cfg).Elem()
+	src := reflect.ValueOf(envCfg)
+	for i := 0; i < dst.NumField(); i++ {
+		key, _, _ := strings.Cut(dst.Type().Field(i).Tag.Get("env"), ",")
+		if val, ok := environ[key]; ok && len(key) > 0 && len(val) > 0 {
+			dst.Field(i).Set(src.Field(i))
+		}
+	}
+	return nil
+}
+
+func envMap(environ []string) map[string]string {
+	m := map[string]string{}
+	for _, kv := range environ {
+		if k, v, ok := strings.Cut(kv, "="); ok {
+			m[k] = v
+		}
+	}
+	return m
+}
+
+// ConfigureLogger sets the global `zerolog` level and output format.
+func (c *Configuration) ConfigureLogger() error {
+	zerolog.SetGlobalLevel(c.LogLevel)
+	// Tokens and webhook URLs are redacted from every log line.
+	out := redact.NewWriter(os.Stderr)
+	switch strings.ToLower(strings.TrimSpace(c.LogFormat)) {
+	case "", LogFormatJSON:
+		log.Logger = zerolog.New(out).With().Timestamp().Logger()
+	case LogFormatConsole, "text":
+		log.Logger = log.Output(zerolog.ConsoleWriter{Out: out})
+	default:
+		return errors.New("unknown log format [" + c.LogFormat + "]")
+	}
+	return nil
+}
@@ -0,0 +1,43 @@
+package config
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+var LoadConfigurationTests = []struct {
+	file     string
+	environ  map[string]string
+	args     []string
+	wantPort int
+	wantHome string
+}{
+	{"", map[string]string{}, nil, 3000, ""},
+	{"port: 4000\nhomeURL: https://file.example.com\n", map[string]string{}, nil, 4000, "https://file.example.com"},
+	{"port: 4000\nhomeURL: https://file.example.com\n", map[string]string{"PORT": "5000"}, nil, 5000, "https://file.example.com"},
+	{"port: 4000\n", map[string]string{"PORT": "5000"}, []string{"-port", "6000"}, 6000, ""}}
+
+func TestLoadConfigurationPrecedence(t *testing.T) {
+	for i, tt := range LoadConfigurationTests {
+		args := tt.args
+		if len(tt.file) > 0 {
+			filename := filepath.Join(t.TempDir(), "chathooks.yaml")
+			if err := os.WriteFile(filename, []byte(tt.file), 0600); err != nil {
+				t.Fatal(err)
+			}
+			args = append([]string{"-config", filename}, args...)
+		}
+		cfg, err := loadConfiguration(args, tt.environ, io.Discard)
+		if err != nil {
+			t.Fatalf("loadConfiguration(%d): error [%v]", i, err)
+		}
+		if cfg.Port != tt.wantPort {
+			t.Errorf("loadConfiguration(%d).Port: want [%d], got [%d]", i, tt.wantPort, cfg.Port)
+		}
+		if cfg.HomeURL != tt.wantHome {
+			t.Errorf("loadConfiguration(%d).HomeURL: want [%s], got [%s]", i, tt.wantHome, cfg.HomeURL)
+		}
+	}
+}
@@ -24,7 +24,7 @@ const (
 )
 
 func NewHandler() handlers.Handler {
-	return handlers.Handler{MessageBodyType: MessageBodyType, Normalize: Normalize}
+	return handlers.Handler{Key: HandlerKey, MessageBodyType: MessageBodyType, Normalize: Normalize}
 }
 
 func Normalize(cfg config.Configuration, hReq handlers.HandlerRequest) (cc.Message, error) {
@@ -129,7 +129,10 @@ func (aoa *AhaOutAudit) Activity() string {
 }
 
 func (aoa *AhaOutAudit) Title() string {
-	username := strings.TrimSpace(aoa.User.Name)
+	username := ""
+	if aoa.User != nil {
+		username = strings.TrimSpace(aoa.User.Name)
+	}
 	description := strings.TrimSpace(aoa.Description)
 	itemURL := strings.TrimSpace(aoa.AuditableURL)
 	title := ""
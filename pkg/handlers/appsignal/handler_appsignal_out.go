@@ -20,7 +20,7 @@ const (
 )
 
 func NewHandler() handlers.Handler {
-	return handlers.Handler{MessageBodyType: MessageBodyType, Normalize: Normalize}
+	return handlers.Handler{Key: HandlerKey, MessageBodyType: MessageBodyType, Normalize: Normalize}
 }
 
 func Normalize(cfg config.Configuration, hReq handlers.HandlerRequest) (cc.Message, error) {
@@ -37,16 +37,16 @@ func Normalize(cfg config.Configuration, hReq handlers.HandlerRequest) (cc.Messa
 
 	if len(src.Marker.URL) > 0 {
 		ccMsg.Activity = "App deployed"
-		ccMsg.Title = fmt.Sprintf("%v deployed ([%v](%v))", src.Marker.Site, src.Marker.Revision[:7], src.Marker.URL)
+		ccMsg.Title = fmt.Sprintf("%v deployed ([%v](%v))", src.Marker.Site, handlers.ShortID(src.Marker.Revision, 7), src.Marker.URL)
 
 		attachment := cc.NewAttachment()
 		if 1 == 0 {
 			if len(src.Marker.Revision) > 0 {
 				field := cc.Field{Title: "Revision", Short: true}
 				if len(src.Marker.URL) > 0 {
-					field.Value = fmt.Sprintf("[%v](%v)", src.Marker.Revision[:7], src.Marker.URL)
+					field.Value = fmt.Sprintf("[%v](%v)", handlers.ShortID(src.Marker.Revision, 7), src.Marker.URL)
 				} else {
-					field.Value = src.Marker.Revision[:7]
+					field.Value = handlers.ShortID(src.Marker.Revision, 7)
 				}
 				attachment.AddField(field)
 			} else if len(src.Marker.URL) > 0 {
@@ -0,0 +1,11 @@
+package apteligent
+
+import (
+	"testing"
+
+	"github.com/grokify/chathooks/pkg/handlers/handlerstest"
+)
+
+func FuzzNormalize(f *testing.F) {
+	handlerstest.FuzzNormalize(f, NewHandler())
+}
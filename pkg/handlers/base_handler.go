@@ -1,11 +1,14 @@
 package handlers
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"net/url"
+	"runtime/debug"
 	"strings"
 
 	"github.com/aws/aws-lambda-go/events"
@@ -22,14 +25,36 @@ import (
 
 const (
 	DisplayName = "base_handler"
+	// MaxInputBodyBytes is the largest event body converted, the
+	// `fasthttp` default request body limit.
+	MaxInputBodyBytes = 4 << 20
 )
 
+// ErrNormalizePanic is wrapped by the error returned when a handler's
+// `Normalize` panics.
+var ErrNormalizePanic = errors.New("handler panicked normalizing the request")
+
+// ErrEmptyInputBody is returned when no event could be decoded from the
+// request, e.g. the body is empty, `null`, `{}` or `[]`, or not valid for
+// the handler's `MessageBodyType`.
+var ErrEmptyInputBody = errors.New("no event found in request body")
+
+// ErrInputBodyTooLarge is returned for events over `MaxInputBodyBytes`.
+var ErrInputBodyTooLarge = errors.New("request body too large")
+
 type Handler struct {
 	Config          config.Configuration
 	AdapterSet      adapters.AdapterSet
 	Key             string
 	Normalize       Normalize
 	MessageBodyType models.MessageBodyType
+	QueryParamNames []string // handler-specific query string parameters, e.g. for the URL builder.
+	Recorder        Recorder // optional, records each handled request.
+}
+
+// Recorder records handled requests and their results, e.g. for the admin page.
+type Recorder interface {
+	Record(hookData models.HookData, result models.Result)
 }
 
 type HandlerRequest struct {
@@ -47,92 +72,147 @@ func NewHandlerRequest() HandlerRequest {
 
 type Normalize func(config.Configuration, HandlerRequest) (commonchat.Message, error)
 
-// HandleAwsLambda is the method to respond to a fasthttp request.
+// HandleAwsLambda is the method to respond to an AWS Lambda request.
 func (h Handler) HandleAwsLambda(ctx context.Context, awsReq events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
-	hookData := models.HookDataFromAwsLambdaEvent(h.MessageBodyType, awsReq, h.MessageBodyType)
-	errs := h.HandleCanonical(hookData)
-	awsRes, err := models.BuildAwsAPIGatewayProxyResponse(hookData, errs...)
-	return awsRes, err
+	hookData := models.HookDataFromAwsLambdaEvent(h.MessageBodyType, awsReq)
+	result := h.HandleCanonical(hookData)
+	return models.BuildAwsAPIGatewayProxyResponse(h.Config.ResponseMode, hookData, result)
 }
 
-// HandleNetHTTP is the method to respond to a fasthttp request.
+// HandleAnyHTTP is the method to respond to an `anyhttp` request.
 func (h Handler) HandleAnyHTTP(aRes anyhttp.Response, aReq anyhttp.Request) {
 	hookData := models.HookDataFromAnyHTTPReq(h.MessageBodyType, aReq)
-	errs := h.HandleCanonical(hookData)
-
-	awsRes, err := models.BuildAwsAPIGatewayProxyResponse(hookData, errs...)
-
-	if err != nil {
-		aRes.SetStatusCode(http.StatusInternalServerError)
+	result := h.HandleCanonical(hookData)
+	if err := models.WriteAnyHTTPResponse(aRes, h.Config.ResponseMode, hookData, result); err != nil {
 		log.Info().
 			Err(err).
 			Str("event", "outgoing.webhook.error").
 			Msg("ERROR")
-	} else {
-		if bytes, err := json.Marshal(awsRes.Body); err != nil {
-			aRes.SetStatusCode(http.StatusInternalServerError)
-		} else {
-			_, err := aRes.SetBodyBytes(bytes)
-			if err != nil {
-				log.Info().
-					Err(err).
-					Str("event", "outgoing.webhook.error").
-					Msg("ERROR")
-				aRes.SetStatusCode(http.StatusInternalServerError)
-			} else {
-				aRes.SetStatusCode(awsRes.StatusCode)
-			}
-		}
 	}
 }
 
-// HandleNetHTTP is the method to respond to a fasthttp request.
+// HandleNetHTTP is the method to respond to a net/http request.
 func (h Handler) HandleNetHTTP(res http.ResponseWriter, req *http.Request) {
-	hookData := models.HookDataFromNetHTTPReq(h.MessageBodyType, req)
-	errs := h.HandleCanonical(hookData)
+	h.HandleAnyHTTP(anyhttp.NewResReqNetHTTP(res, req))
+}
 
-	awsRes, err := models.BuildAwsAPIGatewayProxyResponse(hookData, errs...)
+// HandleFastHTTP is the method to respond to a fasthttp request.
+func (h Handler) HandleFastHTTP(ctx *fasthttp.RequestCtx) {
+	h.HandleAnyHTTP(anyhttp.NewResReqFastHTTP(ctx))
+}
 
-	if err != nil {
-		res.WriteHeader(http.StatusInternalServerError)
-		log.Info().
-			Err(err).
-			Str("event", "outgoing.webhook.error").
-			Msg("ERROR")
-	} else {
-		res.WriteHeader(awsRes.StatusCode)
-		fmt.Fprint(res, awsRes.Body)
+// HandleCanonical is the method to handle a processed request. An empty
+// input body is a decode error (`400`) and a failed conversion is a
+// normalization error (`422`, or `500` if the handler panicked).
+// Otherwise the result has one delivery per output adapter.
+func (h Handler) HandleCanonical(hookData models.HookData) models.Result {
+	log.Debug().
+		Str("event", "incoming.webhook").
+		Str("handler", DisplayName).
+		Str("input_body", string(hookData.InputBody)).
+		Msg("HANDLE_CANONICAL")
+
+	hookData, result := h.canonical(hookData)
+	if len(result.Errors) == 0 {
+		result.Deliveries = h.AdapterSet.SendWebhooks(hookData)
 	}
+	h.record(hookData, result)
+	return result
 }
 
-// HandleFastHTTP is the method to respond to a fasthttp request.
-func (h Handler) HandleFastHTTP(ctx *fasthttp.RequestCtx) {
-	hookData := models.HookDataFromFastHTTPReqCtx(h.MessageBodyType, ctx)
-	errs := h.HandleCanonical(hookData)
+// Validate checks that a request decodes and normalizes, returning the
+// same errors as `HandleCanonical`, without delivering or recording it.
+// It is used to reject requests before they are queued.
+func (h Handler) Validate(hookData models.HookData) models.Result {
+	_, result := h.canonical(hookData)
+	return result
+}
 
-	awsRes, err := models.BuildAwsAPIGatewayProxyResponse(hookData, errs...)
+// canonical applies the request's route and sets the canonical message.
+func (h Handler) canonical(hookData models.HookData) (models.HookData, models.Result) {
+	if route, ok := h.Config.Route(hookData.RouteName); ok {
+		hookData.ApplyRoute(route)
+	}
+
+	if err := checkInputBody(hookData.InputBody); err != nil {
+		log.Info().
+			Err(err).
+			Str("type", "http.response").
+			Int("http_status", http.StatusBadRequest).
+			Str("handler", h.key(hookData)).
+			Msg("E_INVALID_INPUT_BODY")
+		return hookData, models.NewErrorResult(models.ErrorTypeDecode, http.StatusBadRequest, err.Error())
+	}
 
+	ccMsg, err := h.NormalizeHookData(hookData)
 	if err != nil {
-		ctx.SetStatusCode(http.StatusInternalServerError)
+		statusCode := http.StatusUnprocessableEntity
+		if errors.Is(err, ErrNormalizePanic) {
+			statusCode = http.StatusInternalServerError
+		}
 		log.Info().
 			Err(err).
-			Str("event", "outgoing.webhook.error").
-			Msg("ERROR")
-	} else {
-		ctx.SetStatusCode(awsRes.StatusCode)
-		fmt.Fprint(ctx, awsRes.Body)
+			Str("type", "http.response").
+			Int("http_status", statusCode).
+			Str("handler", h.key(hookData)).
+			Msg("request conversion failed")
+
+		return hookData, models.NewErrorResult(models.ErrorTypeNormalize, statusCode, err.Error())
 	}
+	hookData.CanonicalMessage = ccMsg
+	return hookData, models.Result{}
 }
 
-// HandleCanonical is the method to handle a processed request.
-func (h Handler) HandleCanonical(hookData models.HookData) []models.ErrorInfo {
-	log.Debug().
-		Str("event", "incoming.webhook").
-		Str("handler", DisplayName).
-		Str("input_body", string(hookData.InputBody)).
-		Msg("HANDLE_CANONICAL")
+// checkInputBody returns `ErrEmptyInputBody` for bodies without an event
+// and `ErrInputBodyTooLarge` for bodies over `MaxInputBodyBytes`.
+func checkInputBody(body []byte) error {
+	if len(body) > MaxInputBodyBytes {
+		return ErrInputBodyTooLarge
+	}
+	body = bytes.TrimSpace(body)
+	if len(body) == 0 {
+		return ErrEmptyInputBody
+	}
+	// Empty JSON values are short, so longer bodies are not parsed twice.
+	if len(body) > 16 || !json.Valid(body) {
+		return nil
+	}
+	var v any
+	if err := json.Unmarshal(body, &v); err != nil {
+		return nil
+	}
+	switch val := v.(type) {
+	case nil:
+		return ErrEmptyInputBody
+	case map[string]any:
+		if len(val) == 0 {
+			return ErrEmptyInputBody
+		}
+	case []any:
+		if len(val) == 0 {
+			return ErrEmptyInputBody
+		}
+	}
+	return nil
+}
 
-	ccMsg, err := h.Normalize(h.Config,
+// NormalizeHookData converts the request body to a `commonchat.Message`,
+// applying the `defaultActivity` and `defaultIcon` query string parameters.
+// A panic in the handler's `Normalize` is recovered and returned as an
+// error wrapping `ErrNormalizePanic`.
+func (h Handler) NormalizeHookData(hookData models.HookData) (ccMsg commonchat.Message, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Error().
+				Str("handler", h.key(hookData)).
+				Interface("panic", r).
+				Bytes("stack", debug.Stack()).
+				Msg("E_HANDLER_NORMALIZE_PANIC")
+			ccMsg = commonchat.Message{}
+			err = fmt.Errorf("%w: %v", ErrNormalizePanic, r)
+		}
+	}()
+	ccMsg, err = h.Normalize(h.Config,
 		HandlerRequest{
 			QueryParams: hookData.CustomQueryParams,
 			Body:        hookData.InputBody})
@@ -155,16 +235,19 @@ func (h Handler) HandleCanonical(hookData models.HookData) []models.ErrorInfo {
 			}
 		}
 	}
-	if err != nil {
-		log.Info().
-			Err(err).
-			Str("type", "http.response").
-			Int("http_status", fasthttp.StatusNotAcceptable).
-			Str("handler", DisplayName).
-			Msg("request conversion failed")
+	return ccMsg, err
+}
 
-		return []models.ErrorInfo{{StatusCode: 500, Body: []byte(err.Error())}}
+// key returns the handler key for logging, falling back to the input type.
+func (h Handler) key(hookData models.HookData) string {
+	if len(h.Key) > 0 {
+		return h.Key
+	}
+	return hookData.InputType
+}
+
+func (h Handler) record(hookData models.HookData, result models.Result) {
+	if h.Recorder != nil {
+		h.Recorder.Record(hookData, result)
 	}
-	hookData.CanonicalMessage = ccMsg
-	return h.AdapterSet.SendWebhooks(hookData)
 }
@@ -0,0 +1,106 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/grokify/commonchat"
+	hum "github.com/grokify/mogo/net/http/httputilmore"
+	"github.com/valyala/fasthttp"
+
+	"github.com/grokify/chathooks/pkg/config"
+	"github.com/grokify/chathooks/pkg/models"
+)
+
+var HandleCanonicalErrorTests = []struct {
+	body           string
+	normalize      Normalize
+	wantType       models.ErrorType
+	wantStatusCode int
+}{
+	{"", nil, models.ErrorTypeDecode, http.StatusBadRequest},
+	{"{ }", nil, models.ErrorTypeDecode, http.StatusBadRequest},
+	{"null", nil, models.ErrorTypeDecode, http.StatusBadRequest},
+	{`{"event":"build"}`, func(config.Configuration, HandlerRequest) (commonchat.Message, error) {
+		return commonchat.Message{}, errors.New("unsupported event")
+	}, models.ErrorTypeNormalize, http.StatusUnprocessableEntity},
+	{`{"event":"build"}`, func(config.Configuration, HandlerRequest) (commonchat.Message, error) {
+		var id string
+		return commonchat.Message{Title: id[:8]}, nil
+	}, models.ErrorTypeNormalize, http.StatusInternalServerError}}
+
+func TestHandleCanonicalErrors(t *testing.T) {
+	for i, tt := range HandleCanonicalErrorTests {
+		h := Handler{Key: "test", Normalize: tt.normalize}
+		result := h.HandleCanonical(models.HookData{InputType: "test", InputBody: []byte(tt.body)})
+		if len(result.Errors) != 1 {
+			t.Fatalf("Handler.HandleCanonical(%d): want [1] error, got [%d]", i, len(result.Errors))
+		}
+		if result.Errors[0].Type != tt.wantType {
+			t.Errorf("Handler.HandleCanonical(%d): want type [%s], got [%s]", i, tt.wantType, result.Errors[0].Type)
+		}
+		if result.StatusCode() != tt.wantStatusCode {
+			t.Errorf("Handler.HandleCanonical(%d): want status [%d], got [%d]", i, tt.wantStatusCode, result.StatusCode())
+		}
+	}
+}
+
+// TestHandleEngineResponses checks every engine returns the same
+// `ResponseInfo` status, content type and errors.
+func TestHandleEngineResponses(t *testing.T) {
+	for i, tt := range HandleCanonicalErrorTests {
+		h := Handler{Key: "test", MessageBodyType: models.JSON, Normalize: tt.normalize}
+		want := models.ResponseInfo{
+			StatusCode: tt.wantStatusCode,
+			Errors:     h.HandleCanonical(models.HookData{InputBody: []byte(tt.body)}).Errors}
+
+		netRec := httptest.NewRecorder()
+		h.HandleNetHTTP(netRec, httptest.NewRequest(http.MethodPost, "/hook?inputType=test", strings.NewReader(tt.body)))
+
+		fastCtx := &fasthttp.RequestCtx{}
+		fastCtx.Request.Header.SetMethod(http.MethodPost)
+		fastCtx.Request.SetRequestURI("/hook?inputType=test")
+		fastCtx.Request.SetBodyString(tt.body)
+		h.HandleFastHTTP(fastCtx)
+
+		awsRes, err := h.HandleAwsLambda(context.Background(), events.APIGatewayProxyRequest{
+			HTTPMethod:            http.MethodPost,
+			QueryStringParameters: map[string]string{"inputType": "test"},
+			Body:                  tt.body})
+		if err != nil {
+			t.Fatalf("Handler.HandleAwsLambda(%d): error [%v]", i, err)
+		}
+
+		engines := map[string]struct {
+			statusCode  int
+			contentType string
+			body        []byte
+		}{
+			"net/http":  {netRec.Code, netRec.Header().Get(hum.HeaderContentType), netRec.Body.Bytes()},
+			"fasthttp":  {fastCtx.Response.StatusCode(), string(fastCtx.Response.Header.ContentType()), fastCtx.Response.Body()},
+			"awslambda": {awsRes.StatusCode, awsRes.Headers[hum.HeaderContentType], []byte(awsRes.Body)}}
+		for name, res := range engines {
+			if res.statusCode != tt.wantStatusCode {
+				t.Errorf("Handler(%d) [%s]: want status [%d], got [%d]", i, name, tt.wantStatusCode, res.statusCode)
+			}
+			if res.contentType != hum.ContentTypeAppJSONUtf8 {
+				t.Errorf("Handler(%d) [%s]: want content type [%s], got [%s]", i, name, hum.ContentTypeAppJSONUtf8, res.contentType)
+			}
+			got := models.ResponseInfo{}
+			if err := json.Unmarshal(res.body, &got); err != nil {
+				t.Fatalf("Handler(%d) [%s]: invalid JSON [%s]", i, name, string(res.body))
+			}
+			got.HookData = nil
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("Handler(%d) [%s]: want [%v], got [%v]", i, name, want, got)
+			}
+		}
+	}
+}
@@ -23,7 +23,7 @@ const (
 )
 
 func NewHandler() handlers.Handler {
-	return handlers.Handler{MessageBodyType: MessageBodyType, Normalize: Normalize}
+	return handlers.Handler{Key: HandlerKey, MessageBodyType: MessageBodyType, Normalize: Normalize}
 }
 
 /*
@@ -20,7 +20,7 @@ const (
 )
 
 func NewHandler() handlers.Handler {
-	return handlers.Handler{MessageBodyType: MessageBodyType, Normalize: Normalize}
+	return handlers.Handler{Key: HandlerKey, MessageBodyType: MessageBodyType, Normalize: Normalize}
 }
 
 func Normalize(cfg config.Configuration, hReq handlers.HandlerRequest) (cc.Message, error) {
@@ -40,3 +40,12 @@ func IntegrationActivitySuffix(displayName string) string {
 	}
 	return ""
 }
+
+// ShortID returns the first `length` bytes of an ID, such as a commit
+// SHA, or the whole ID when it is shorter.
+func ShortID(id string, length int) string {
+	if len(id) <= length {
+		return id
+	}
+	return id[:length]
+}
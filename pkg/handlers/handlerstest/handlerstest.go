@@ -0,0 +1,383 @@
+// Package handlerstest checks that a handler behaves like the built-in
+// handlers. Call `Run` from a handler package test with the handler and
+// its `event-example_*` fixtures:
+//
+//	func TestConformance(t *testing.T) {
+//		handlerstest.RunDir(t, NewHandler(), "testdata", handlerstest.Options{})
+//	}
+//
+// `FuzzNormalize` fuzzes the handler's `Normalize` from its example events.
+package handlerstest
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"io/fs"
+	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/grokify/commonchat"
+	"github.com/grokify/mogo/net/http/httputilmore"
+	"github.com/grokify/sogo/net/http/anyhttp"
+	"github.com/valyala/fasthttp"
+
+	"github.com/grokify/chathooks/pkg/adapters"
+	"github.com/grokify/chathooks/pkg/config"
+	"github.com/grokify/chathooks/pkg/handlers"
+	"github.com/grokify/chathooks/pkg/models"
+	"github.com/grokify/chathooks/pkg/util"
+)
+
+const (
+	HomeURL        = "https://chathooks.example.com"
+	IconBaseURL    = "https://icons.example.com/chathooks/"
+	OversizedBytes = 8 << 20
+
+	// OutputType and OutputURL are the output invalid bodies are sent to,
+	// which fails the check if it is called.
+	OutputType = "handlerstest"
+	OutputURL  = "https://chat.example.com/webhook"
+)
+
+// rxFixture matches fixture filenames, capturing the event slug.
+var rxFixture = regexp.MustCompile(`^event-example_(.+)\.(json|txt)$`)
+
+// Options adjust the checks for a handler.
+type Options struct {
+	// ErrorSlugs are fixture slugs for which `Normalize` is expected to
+	// return an error, such as events the handler deliberately skips.
+	ErrorSlugs []string
+	// EventIcons skips the icon check for handlers that take the icon
+	// from the event, such as the Slack handler.
+	EventIcons bool
+}
+
+// RunDir runs `Run` with the fixtures in directory `dir`.
+func RunDir(t *testing.T, handler handlers.Handler, dir string, opts Options) {
+	t.Helper()
+	Run(t, handler, os.DirFS(dir), opts)
+}
+
+// Run checks `handler` against the `event-example_<slug>.(json|txt)`
+// fixtures at the root of `fixtures`:
+//
+//   - each fixture normalizes to a message with an activity or title;
+//   - the icon is resolved with `Configuration.GetAppIconURL`;
+//   - empty, garbage and oversized bodies return decode or normalize
+//     errors without panicking or delivering;
+//   - the fixture decodes to the same message over `net/http`, `fasthttp`
+//     and AWS Lambda, for each encoding its `MessageBodyType` accepts;
+//   - normalizing the same fixture twice gives the same message.
+func Run(t *testing.T, handler handlers.Handler, fixtures fs.FS, opts Options) {
+	t.Helper()
+	if handler.Normalize == nil {
+		t.Fatal("handler.Normalize is nil")
+	}
+	if len(handler.Key) == 0 {
+		t.Error("handler.Key is empty")
+	}
+	entries, err := fs.ReadDir(fixtures, ".")
+	if err != nil {
+		t.Fatalf("cannot read fixtures: %v", err)
+	}
+	found := 0
+	for _, entry := range entries {
+		m := rxFixture.FindStringSubmatch(entry.Name())
+		if entry.IsDir() || m == nil {
+			continue
+		}
+		found++
+		slug := m[1]
+		body, err := fs.ReadFile(fixtures, entry.Name())
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Run(slug, func(t *testing.T) {
+			if slices.Contains(opts.ErrorSlugs, slug) {
+				if _, err := normalize(handler, homeConfig(), body); err == nil {
+					t.Error("Normalize: want error, got none")
+				}
+				return
+			}
+			checkFixture(t, handler, body, opts)
+		})
+	}
+	if found == 0 {
+		t.Error("no event-example_* fixtures found")
+	}
+	t.Run("invalid-bodies", func(t *testing.T) {
+		checkInvalidBodies(t, handler)
+	})
+}
+
+func checkFixture(t *testing.T, handler handlers.Handler, body []byte, opts Options) {
+	want, err := normalize(handler, homeConfig(), body)
+	if err != nil {
+		t.Fatalf("Normalize: error [%v]", err)
+	}
+	if len(strings.TrimSpace(want.Activity)) == 0 && len(strings.TrimSpace(want.Title)) == 0 {
+		t.Error("Normalize: activity and title are both empty")
+	}
+
+	if !opts.EventIcons {
+		for _, cfg := range []config.Configuration{homeConfig(), iconConfig()} {
+			msg, err := normalize(handler, cfg, body)
+			if err != nil {
+				t.Fatalf("Normalize: error [%v]", err)
+			}
+			wantIcon, err := cfg.GetAppIconURL(handler.Key)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if msg.IconURL != wantIcon.String() {
+				t.Errorf("Normalize: want icon URL [%s] from Configuration.GetAppIconURL, got [%s]", wantIcon.String(), msg.IconURL)
+			}
+		}
+	}
+
+	again, err := normalize(handler, homeConfig(), body)
+	if err != nil {
+		t.Fatalf("Normalize: error on second run [%v]", err)
+	}
+	if !bytes.Equal(mustJSON(t, want), mustJSON(t, again)) {
+		t.Errorf("Normalize: not deterministic:\n%s\n%s", mustJSON(t, want), mustJSON(t, again))
+	}
+
+	for _, enc := range encodings(handler.MessageBodyType, body) {
+		for _, tr := range transports {
+			inputBody := tr.decode(handler.MessageBodyType, enc.contentType, enc.body)
+			msg, err := handler.Normalize(homeConfig(), handlers.HandlerRequest{Body: inputBody})
+			if err != nil {
+				t.Errorf("%s %s: Normalize error [%v]", tr.name, enc.contentType, err)
+				continue
+			}
+			if !bytes.Equal(mustJSON(t, want), mustJSON(t, msg)) {
+				t.Errorf("%s %s: message differs from direct Normalize:\n%s", tr.name, enc.contentType, mustJSON(t, msg))
+			}
+		}
+	}
+}
+
+// checkInvalidBodies checks that invalid bodies are rejected without
+// panicking or delivering, as a decode error (`400`) when no event is
+// found in the body or a normalize error (`422`) when it cannot be
+// converted.
+func checkInvalidBodies(t *testing.T, handler handlers.Handler) {
+	random := make([]byte, 4096)
+	rand.New(rand.NewSource(1)).Read(random) // #nosec G404 -- deterministic test data
+	bodies := map[string][]byte{
+		"empty":          {},
+		"garbage":        []byte("garbage"),
+		"truncated-json": []byte(`{"a":`),
+		"json-null":      []byte(`null`),
+		"json-array":     []byte(`[]`),
+		"json-object":    []byte(`{}`),
+		"random":         random,
+		"oversized-json": []byte(`{"a":"` + strings.Repeat("a", OversizedBytes) + `"}`),
+		"oversized-form": []byte("payload=" + strings.Repeat("a", OversizedBytes))}
+	adapter := &recordingAdapter{}
+	handler.Config = homeConfig()
+	handler.AdapterSet = adapters.AdapterSet{Types: map[string]commonchat.Adapter{OutputType: adapter}}
+	handler.Recorder = nil
+	for name, body := range bodies {
+		result := func() (result models.Result) {
+			defer func() {
+				if r := recover(); r != nil {
+					t.Errorf("HandleCanonical(%s): panic [%v]", name, r)
+				}
+			}()
+			contentType := httputilmore.ContentTypeAppFormURLEncodedUtf8
+			if json.Valid(body) {
+				contentType = httputilmore.ContentTypeAppJSONUtf8
+			}
+			return handler.HandleCanonical(models.HookData{
+				InputType:  handler.Key,
+				InputBody:  models.BodyToMessageBytes(handler.MessageBodyType, contentType, body),
+				OutputType: OutputType,
+				OutputURL:  OutputURL})
+		}()
+		if len(result.Deliveries) > 0 || adapter.calls > 0 {
+			t.Errorf("HandleCanonical(%s): want no deliveries, got [%d]", name, len(result.Deliveries))
+			adapter.calls = 0
+		}
+		if len(result.Errors) == 0 {
+			t.Errorf("HandleCanonical(%s): want decode or normalize error, got none", name)
+			continue
+		}
+		switch errInfo := result.Errors[0]; {
+		case errInfo.Type == models.ErrorTypeDecode && errInfo.StatusCode == http.StatusBadRequest:
+		case errInfo.Type == models.ErrorTypeNormalize && errInfo.StatusCode == http.StatusUnprocessableEntity:
+		default:
+			t.Errorf("HandleCanonical(%s): want decode [400] or normalize [422] error, got [%s] [%d]",
+				name, errInfo.Type, errInfo.StatusCode)
+		}
+	}
+}
+
+// recordingAdapter counts the messages sent to it, without sending them.
+type recordingAdapter struct {
+	calls int
+}
+
+func (a *recordingAdapter) SendWebhook(url string, ccMsg commonchat.Message, msg any, opts map[string]any) (*fasthttp.Request, *fasthttp.Response, error) {
+	a.calls++
+	res := fasthttp.AcquireResponse()
+	res.SetStatusCode(http.StatusOK)
+	return nil, res, nil
+}
+
+func (a *recordingAdapter) SendMessage(ccMsg commonchat.Message, msg any, opts map[string]any) (*fasthttp.Request, *fasthttp.Response, error) {
+	return a.SendWebhook(OutputURL, ccMsg, msg, opts)
+}
+
+func (a *recordingAdapter) WebhookUID(ctx *fasthttp.RequestCtx) (string, error) {
+	return "", nil
+}
+
+// FuzzNormalize fuzzes `handler.Normalize`, seeded with the handler's
+// example events. Errors are expected for invalid input. Panics fail.
+func FuzzNormalize(f *testing.F, handler handlers.Handler) {
+	data, err := util.NewExampleData()
+	if err != nil {
+		f.Fatal(err)
+	}
+	for _, slug := range data.EventSlugs(handler.Key) {
+		bytes, err := data.ExampleMessageBytes(handler.Key, slug)
+		if err != nil {
+			f.Fatal(err)
+		}
+		f.Add(bytes)
+	}
+	f.Add([]byte(`{}`))
+	f.Fuzz(func(t *testing.T, body []byte) {
+		_, _ = handler.Normalize(config.Configuration{}, handlers.HandlerRequest{Body: body})
+	})
+}
+
+func normalize(handler handlers.Handler, cfg config.Configuration, body []byte) (commonchat.Message, error) {
+	contentType := httputilmore.ContentTypeAppFormURLEncodedUtf8
+	if json.Valid(body) {
+		contentType = httputilmore.ContentTypeAppJSONUtf8
+	}
+	return handler.Normalize(cfg, handlers.HandlerRequest{
+		Body: models.BodyToMessageBytes(handler.MessageBodyType, contentType, body)})
+}
+
+func homeConfig() config.Configuration {
+	return config.Configuration{HomeURL: HomeURL, EmojiURLFormat: config.EmojiURLFormat}
+}
+
+func iconConfig() config.Configuration {
+	return config.Configuration{HomeURL: HomeURL, IconBaseURL: IconBaseURL, EmojiURLFormat: config.EmojiURLFormat}
+}
+
+func mustJSON(t *testing.T, v any) []byte {
+	t.Helper()
+	b, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+// encoding is a request body as a sender would post a fixture.
+type encoding struct {
+	contentType string
+	body        []byte
+}
+
+// encodings returns the request bodies a `MessageBodyType` accepts for a
+// fixture. JSON fixtures for form payload types are wrapped in a
+// `payload` form parameter, as Slack-style senders post them.
+func encodings(bodyType models.MessageBodyType, body []byte) []encoding {
+	isJSON := json.Valid(body)
+	form := encoding{httputilmore.ContentTypeAppFormURLEncodedUtf8, body}
+	if isJSON {
+		form.body = []byte(models.ParamPayload + "=" + url.QueryEscape(string(body)))
+	}
+	switch bodyType {
+	case models.URLEncodedJSONPayload:
+		return []encoding{form}
+	case models.URLEncodedJSONPayloadOrJSON:
+		if isJSON {
+			return []encoding{{httputilmore.ContentTypeAppJSONUtf8, body}, form}
+		}
+		return []encoding{form}
+	case models.URLEncoded, models.URLEncodedRails:
+		return []encoding{{httputilmore.ContentTypeAppFormURLEncodedUtf8, body}}
+	default:
+		return []encoding{{httputilmore.ContentTypeAppJSONUtf8, body}}
+	}
+}
+
+// transport decodes a request body as the service does for an engine.
+type transport struct {
+	name   string
+	decode func(bodyType models.MessageBodyType, contentType string, body []byte) []byte
+}
+
+var transports = []transport{
+	{"net/http", func(bodyType models.MessageBodyType, contentType string, body []byte) []byte {
+		req := httptest.NewRequest(http.MethodPost, "/hook", bytes.NewReader(body))
+		req.Header.Set(httputilmore.HeaderContentType, contentType)
+		aReq := anyhttp.NewRequestNetHTTP(req)
+		if err := aReq.ParseForm(); err != nil {
+			return nil
+		}
+		return models.HookDataFromAnyHTTPReq(bodyType, aReq).InputBody
+	}},
+	{"fasthttp", func(bodyType models.MessageBodyType, contentType string, body []byte) []byte {
+		ctx := &fasthttp.RequestCtx{}
+		ctx.Request.SetRequestURI("/hook")
+		ctx.Request.Header.SetMethod(http.MethodPost)
+		ctx.Request.Header.SetContentType(contentType)
+		ctx.Request.SetBody(body)
+		aReq := anyhttp.NewRequestFastHTTP(ctx)
+		if err := aReq.ParseForm(); err != nil {
+			return nil
+		}
+		return models.HookDataFromAnyHTTPReq(bodyType, aReq).InputBody
+	}},
+	{"awslambda", func(bodyType models.MessageBodyType, contentType string, body []byte) []byte {
+		return models.HookDataFromAwsLambdaEvent(bodyType, events.APIGatewayProxyRequest{
+			HTTPMethod: http.MethodPost,
+			Path:       "/hook",
+			Headers:    map[string]string{httputilmore.HeaderContentType: contentType},
+			Body:       string(body)}).InputBody
+	}},
+	{"awslambda-base64", func(bodyType models.MessageBodyType, contentType string, body []byte) []byte {
+		return models.HookDataFromAwsLambdaEvent(bodyType, events.APIGatewayProxyRequest{
+			HTTPMethod:      http.MethodPost,
+			Path:            "/hook",
+			Headers:         map[string]string{httputilmore.HeaderContentType: contentType},
+			Body:            base64.StdEncoding.EncodeToString(body),
+			IsBase64Encoded: true}).InputBody
+	}},
+	{"awslambda-v2-base64", func(bodyType models.MessageBodyType, contentType string, body []byte) []byte {
+		req := events.APIGatewayV2HTTPRequest{
+			Version:         "2.0",
+			RawPath:         "/hook",
+			Headers:         map[string]string{"content-type": contentType},
+			Body:            base64.StdEncoding.EncodeToString(body),
+			IsBase64Encoded: true}
+		req.RequestContext.HTTP.Method = http.MethodPost
+		return models.HookDataFromAwsLambdaEvent(bodyType, models.APIGatewayProxyRequestFromV2(req)).InputBody
+	}},
+	{"function-url", func(bodyType models.MessageBodyType, contentType string, body []byte) []byte {
+		req := events.LambdaFunctionURLRequest{
+			Version: "2.0",
+			RawPath: "/hook",
+			Headers: map[string]string{"content-type": contentType},
+			Body:    string(body)}
+		req.RequestContext.HTTP.Method = http.MethodPost
+		return models.HookDataFromAwsLambdaEvent(bodyType, models.APIGatewayProxyRequestFromFunctionURL(req)).InputBody
+	}}}
@@ -0,0 +1,48 @@
+package handlerstest
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"testing/fstest"
+
+	"github.com/grokify/commonchat"
+
+	"github.com/grokify/chathooks/pkg/config"
+	"github.com/grokify/chathooks/pkg/handlers"
+	"github.com/grokify/chathooks/pkg/models"
+)
+
+// buildEvent is the event posted to the fake handler.
+type buildEvent struct {
+	Repo   string `json:"repo"`
+	Status string `json:"status"`
+}
+
+func normalizeBuild(cfg config.Configuration, hReq handlers.HandlerRequest) (commonchat.Message, error) {
+	evt := buildEvent{}
+	if err := json.Unmarshal(hReq.Body, &evt); err != nil {
+		return commonchat.Message{}, err
+	} else if len(evt.Repo) == 0 {
+		return commonchat.Message{}, errors.New("repo not set")
+	}
+	ccMsg := commonchat.NewMessage()
+	ccMsg.Activity = "Build " + evt.Status
+	ccMsg.Title = evt.Repo
+	if iconURL, err := cfg.GetAppIconURL("build"); err == nil {
+		ccMsg.IconURL = iconURL.String()
+	}
+	return ccMsg, nil
+}
+
+func TestRun(t *testing.T) {
+	fixtures := fstest.MapFS{
+		"event-example_passed.json":  {Data: []byte(`{"repo": "chathooks", "status": "passed"}`)},
+		"event-example_no-repo.json": {Data: []byte(`{"status": "passed"}`)},
+		"README.md":                  {Data: []byte("not a fixture")}}
+	handler := handlers.Handler{
+		Key:             "build",
+		MessageBodyType: models.JSON,
+		Normalize:       normalizeBuild}
+	Run(t, handler, fixtures, Options{ErrorSlugs: []string{"no-repo"}})
+}
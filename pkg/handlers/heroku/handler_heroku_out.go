@@ -1,6 +1,7 @@
 package heroku
 
 import (
+	"errors"
 	"fmt"
 	"net/url"
 	"strings"
@@ -21,8 +22,11 @@ const (
 	WebhookDocsURL   = "https://devcenter.heroku.com/articles/app-webhooks-tutorial"
 )
 
+// ErrNoDeployFields is returned for forms without Heroku deploy fields.
+var ErrNoDeployFields = errors.New("heroku: no deploy fields in form")
+
 func NewHandler() handlers.Handler {
-	return handlers.Handler{MessageBodyType: MessageBodyType, Normalize: Normalize}
+	return handlers.Handler{Key: HandlerKey, MessageBodyType: MessageBodyType, Normalize: Normalize}
 }
 
 func BuildInboundMessage(ctx *fasthttp.RequestCtx) (HerokuOutMessage, error) {
@@ -42,6 +46,8 @@ func Normalize(cfg config.Configuration, hReq handlers.HandlerRequest) (cc.Messa
 	src, err := HerokuOutMessageFromQuery(hReq.Body)
 	if err != nil {
 		return cc.Message{}, err
+	} else if src == (HerokuOutMessage{}) {
+		return cc.Message{}, ErrNoDeployFields
 	}
 	return NormalizeHerokuMessage(cfg, src)
 }
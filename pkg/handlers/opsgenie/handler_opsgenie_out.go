@@ -22,7 +22,7 @@ const (
 )
 
 func NewHandler() handlers.Handler {
-	return handlers.Handler{MessageBodyType: MessageBodyType, Normalize: Normalize}
+	return handlers.Handler{Key: HandlerKey, MessageBodyType: MessageBodyType, Normalize: Normalize}
 }
 
 func Normalize(cfg config.Configuration, hReq handlers.HandlerRequest) (cc.Message, error) {
@@ -71,7 +71,7 @@ func Normalize(cfg config.Configuration, hReq handlers.HandlerRequest) (cc.Messa
 	ccMsg.Title = fmt.Sprintf("%s %s ([%s](%s))",
 		src.IntegrationName,
 		alertType,
-		src.Alert.AlertID[:8],
+		handlers.ShortID(src.Alert.AlertID, 8),
 		src.Alert.AlertURL())
 
 	attachment := cc.NewAttachment()
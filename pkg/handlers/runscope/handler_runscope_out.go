@@ -20,7 +20,7 @@ const (
 )
 
 func NewHandler() handlers.Handler {
-	return handlers.Handler{MessageBodyType: MessageBodyType, Normalize: Normalize}
+	return handlers.Handler{Key: HandlerKey, MessageBodyType: MessageBodyType, Normalize: Normalize}
 }
 
 func Normalize(cfg config.Configuration, hReq handlers.HandlerRequest) (cc.Message, error) {
@@ -41,7 +41,7 @@ func Normalize(cfg config.Configuration, hReq handlers.HandlerRequest) (cc.Messa
 		src.TestName,
 		src.TestURL,
 		src.Result,
-		src.TestID[:8],
+		handlers.ShortID(src.TestID, 8),
 		src.TestRunURL)
 
 	attachment := cc.NewAttachment()
@@ -64,7 +64,7 @@ func Normalize(cfg config.Configuration, hReq handlers.HandlerRequest) (cc.Messa
 	if len(src.TeamName) > 0 {
 		attachment.AddField(cc.Field{
 			Title: "Team",
-			Value: fmt.Sprintf("%v (%v)", src.TeamName, src.TeamID[:8])})
+			Value: fmt.Sprintf("%v (%v)", src.TeamName, handlers.ShortID(src.TeamID, 8))})
 	}
 
 	ccMsg.AddAttachment(attachment)
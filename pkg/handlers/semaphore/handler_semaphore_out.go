@@ -22,7 +22,7 @@ const (
 )
 
 func NewHandler() handlers.Handler {
-	return handlers.Handler{MessageBodyType: MessageBodyType, Normalize: Normalize}
+	return handlers.Handler{Key: HandlerKey, MessageBodyType: MessageBodyType, Normalize: Normalize}
 }
 
 // func NormalizeBytes(bytes []byte) (glipwebhook.GlipWebhookMessage, error) {
@@ -72,7 +72,7 @@ func NormalizeSemaphoreciBuildOutMessage(cfg config.Configuration, src Semaphore
 		src.ProjectName,
 		src.BranchName,
 		src.Result,
-		src.Commit.ID[:7],
+		handlers.ShortID(src.Commit.ID, 7),
 		src.Commit.URL)
 
 	attachment := cc.NewAttachment()
@@ -129,7 +129,7 @@ func NormalizeSemaphoreciDeployOutMessage(cfg config.Configuration, src Semaphor
 		src.ProjectName,
 		src.BranchName,
 		src.Result,
-		src.Commit.ID[:7],
+		handlers.ShortID(src.Commit.ID, 7),
 		src.Commit.URL)
 
 	attachment := cc.NewAttachment()
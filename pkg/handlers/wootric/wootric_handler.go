@@ -32,7 +32,10 @@ func NewHandler() handlers.Handler {
 	return handlers.Handler{
 		Key:             HandlerKey,
 		MessageBodyType: MessageBodyType,
-		Normalize:       Normalize}
+		Normalize:       Normalize,
+		QueryParamNames: []string{
+			WootricQryVarFormatResponse,
+			WootricQryVarSkipEmptyText}}
 }
 
 func Normalize(cfg config.Configuration, hReq handlers.HandlerRequest) (cc.Message, error) {
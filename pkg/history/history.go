@@ -0,0 +1,119 @@
+package history
+
+import (
+	"bytes"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/grokify/chathooks/pkg/models"
+)
+
+const (
+	DefaultSize = 100
+	// MaxInputBytes is the longest input body kept per event.
+	MaxInputBytes = 64 << 10
+)
+
+// Event is a handled inbound webhook request with its delivery results.
+// `HookData` is redacted, with the input body truncated to
+// `MaxInputBytes`.
+type Event struct {
+	ID         string
+	Time       time.Time
+	InputType  string
+	Route      string
+	StatusCode int
+	OK         bool // `models.Result.OK`
+	HookData   models.HookData
+	Truncated  bool // the input body was truncated
+	Deliveries []models.DeliveryResult
+	Errors     []models.ErrorInfo // errors before delivery, e.g. normalization errors
+	outputURL  string
+}
+
+// ReplayHookData returns the hook data to replay the event with, which
+// has the output URL. It returns false if the input body was truncated.
+func (evt Event) ReplayHookData() (models.HookData, bool) {
+	hookData := evt.HookData
+	hookData.OutputURL = evt.outputURL
+	return hookData, !evt.Truncated
+}
+
+// Store is a bounded, in-memory ring buffer of recent events. It
+// satisfies `handlers.Recorder`.
+type Store struct {
+	mutex  sync.RWMutex
+	events []Event
+	next   int
+	full   bool
+	count  uint64
+}
+
+// NewStore returns a `Store` holding up to `size` events. If `size`
+// is less than 1, `DefaultSize` is used.
+func NewStore(size int) *Store {
+	if size < 1 {
+		size = DefaultSize
+	}
+	return &Store{events: make([]Event, size)}
+}
+
+// Record adds an event for a handled request, overwriting the oldest
+// event when the buffer is full.
+func (s *Store) Record(hookData models.HookData, result models.Result) {
+	evt := Event{
+		Time:       time.Now().UTC(),
+		InputType:  hookData.InputType,
+		Route:      hookData.Route,
+		StatusCode: result.StatusCode(),
+		OK:         result.OK(),
+		HookData:   hookData.Redacted(),
+		Deliveries: result.Deliveries,
+		Errors:     result.Errors,
+		outputURL:  hookData.OutputURL}
+	if len(evt.HookData.InputBody) > MaxInputBytes {
+		evt.HookData.InputBody = bytes.Clone(evt.HookData.InputBody[:MaxInputBytes])
+		evt.Truncated = true
+	}
+	if len(evt.HookData.InputMessage) > MaxInputBytes {
+		evt.HookData.InputMessage = bytes.Clone(evt.HookData.InputMessage[:MaxInputBytes])
+		evt.Truncated = true
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.count++
+	evt.ID = strconv.FormatUint(s.count, 10)
+	s.events[s.next] = evt
+	s.next++
+	if s.next == len(s.events) {
+		s.next = 0
+		s.full = true
+	}
+}
+
+// Events returns the recorded events, newest first.
+func (s *Store) Events() []Event {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	n := s.next
+	if s.full {
+		n = len(s.events)
+	}
+	evts := make([]Event, 0, n)
+	for i := 1; i <= n; i++ {
+		evts = append(evts, s.events[(s.next-i+len(s.events))%len(s.events)])
+	}
+	return evts
+}
+
+// Event returns the event with the supplied ID if it is still in the buffer.
+func (s *Store) Event(id string) (Event, bool) {
+	for _, evt := range s.Events() {
+		if evt.ID == id {
+			return evt, true
+		}
+	}
+	return Event{}, false
+}
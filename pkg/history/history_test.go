@@ -0,0 +1,76 @@
+package history
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/grokify/chathooks/pkg/models"
+)
+
+var StoreTests = []struct {
+	size    int
+	records int
+	wantIDs []string
+}{
+	{3, 2, []string{"2", "1"}},
+	{3, 5, []string{"5", "4", "3"}}}
+
+func TestStoreEvents(t *testing.T) {
+	for _, tt := range StoreTests {
+		store := NewStore(tt.size)
+		for i := 0; i < tt.records; i++ {
+			store.Record(models.HookData{InputType: "datadog"}, models.Result{})
+		}
+		evts := store.Events()
+		if len(evts) != len(tt.wantIDs) {
+			t.Fatalf("Store.Events(): want [%d] events, got [%d]", len(tt.wantIDs), len(evts))
+		}
+		for i, evt := range evts {
+			if evt.ID != tt.wantIDs[i] {
+				t.Errorf("Store.Events()[%d]: want id [%s], got [%s]", i, tt.wantIDs[i], evt.ID)
+			}
+		}
+	}
+}
+
+func TestStoreDeliveries(t *testing.T) {
+	store := NewStore(1)
+	store.Record(models.HookData{
+		OutputType:  "glip",
+		OutputURL:   "https://hooks.glip.com/webhook/11112222-3333-4444-5555-666677778888",
+		OutputNames: []string{"slack"}},
+		models.Result{Deliveries: []models.DeliveryResult{
+			{Adapter: "glip", Host: "hooks.glip.com", StatusCode: http.StatusOK},
+			{Adapter: "slack", Host: "hooks.slack.com", StatusCode: http.StatusForbidden, Error: "invalid_token"}}})
+	evt, ok := store.Event("1")
+	if !ok {
+		t.Fatal("Store.Event(1): not found")
+	}
+	if evt.StatusCode != http.StatusMultiStatus {
+		t.Errorf("Store.Event(1).StatusCode: want [%d], got [%d]", http.StatusMultiStatus, evt.StatusCode)
+	}
+	if len(evt.Deliveries) != 2 || !evt.Deliveries[0].OK() || evt.Deliveries[1].OK() {
+		t.Errorf("Store.Event(1).Deliveries: want glip ok and slack failed, got [%v]", evt.Deliveries)
+	}
+}
+
+func TestStoreRedacted(t *testing.T) {
+	store := NewStore(2)
+	outputURL := "https://hooks.glip.com/webhook/11112222-3333-4444-5555-666677778888"
+	store.Record(models.HookData{Token: "secret", OutputURL: outputURL, InputBody: []byte(`{"event":"build"}`)}, models.Result{})
+	store.Record(models.HookData{InputBody: make([]byte, MaxInputBytes+1)}, models.Result{})
+	evt, _ := store.Event("1")
+	if evt.HookData.Token == "secret" || evt.HookData.OutputURL == outputURL || evt.Truncated {
+		t.Errorf("Store.Event(1): want redacted, not truncated, got [%v]", evt.HookData)
+	}
+	if hookData, ok := evt.ReplayHookData(); !ok || hookData.OutputURL != outputURL {
+		t.Errorf("Store.Event(1).ReplayHookData(): want [%s] [true], got [%s] [%v]", outputURL, hookData.OutputURL, ok)
+	}
+	evt, _ = store.Event("2")
+	if len(evt.HookData.InputBody) != MaxInputBytes || !evt.Truncated {
+		t.Errorf("Store.Event(2): want truncated body, got [%d] bytes", len(evt.HookData.InputBody))
+	}
+	if _, ok := evt.ReplayHookData(); ok {
+		t.Errorf("Store.Event(2).ReplayHookData(): want [false], got [true]")
+	}
+}
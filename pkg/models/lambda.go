@@ -0,0 +1,109 @@
+package models
+
+import (
+	"net/url"
+	"strings"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+const headerCookie = "Cookie"
+
+// APIGatewayProxyRequestFromV2 converts an API Gateway HTTP API event,
+// payload format 2.0, to a REST API event so both are handled alike.
+func APIGatewayProxyRequestFromV2(req events.APIGatewayV2HTTPRequest) events.APIGatewayProxyRequest {
+	return apiGatewayProxyRequestV2(lambdaRequestV2{
+		Method:                req.RequestContext.HTTP.Method,
+		RawPath:               req.RawPath,
+		RawQueryString:        req.RawQueryString,
+		QueryStringParameters: req.QueryStringParameters,
+		Headers:               req.Headers,
+		Cookies:               req.Cookies,
+		SourceIP:              req.RequestContext.HTTP.SourceIP,
+		RequestID:             req.RequestContext.RequestID,
+		Body:                  req.Body,
+		IsBase64Encoded:       req.IsBase64Encoded})
+}
+
+// APIGatewayProxyRequestFromFunctionURL converts a Lambda Function URL
+// event to an API Gateway REST API event.
+func APIGatewayProxyRequestFromFunctionURL(req events.LambdaFunctionURLRequest) events.APIGatewayProxyRequest {
+	return apiGatewayProxyRequestV2(lambdaRequestV2{
+		Method:                req.RequestContext.HTTP.Method,
+		RawPath:               req.RawPath,
+		RawQueryString:        req.RawQueryString,
+		QueryStringParameters: req.QueryStringParameters,
+		Headers:               req.Headers,
+		Cookies:               req.Cookies,
+		SourceIP:              req.RequestContext.HTTP.SourceIP,
+		RequestID:             req.RequestContext.RequestID,
+		Body:                  req.Body,
+		IsBase64Encoded:       req.IsBase64Encoded})
+}
+
+// APIGatewayV2HTTPResponseFromV1 converts an API Gateway REST API response
+// to an HTTP API response.
+func APIGatewayV2HTTPResponseFromV1(res events.APIGatewayProxyResponse) events.APIGatewayV2HTTPResponse {
+	return events.APIGatewayV2HTTPResponse{
+		StatusCode:      res.StatusCode,
+		Headers:         res.Headers,
+		Body:            res.Body,
+		IsBase64Encoded: res.IsBase64Encoded}
+}
+
+// FunctionURLResponseFromV1 converts an API Gateway REST API response to
+// a Lambda Function URL response.
+func FunctionURLResponseFromV1(res events.APIGatewayProxyResponse) events.LambdaFunctionURLResponse {
+	return events.LambdaFunctionURLResponse{
+		StatusCode:      res.StatusCode,
+		Headers:         res.Headers,
+		Body:            res.Body,
+		IsBase64Encoded: res.IsBase64Encoded}
+}
+
+// lambdaRequestV2 holds the fields shared by HTTP API and Function URL
+// events, which use the same payload format.
+type lambdaRequestV2 struct {
+	Method                string
+	RawPath               string
+	RawQueryString        string
+	QueryStringParameters map[string]string
+	Headers               map[string]string
+	Cookies               []string
+	SourceIP              string
+	RequestID             string
+	Body                  string
+	IsBase64Encoded       bool
+}
+
+func apiGatewayProxyRequestV2(req lambdaRequestV2) events.APIGatewayProxyRequest {
+	v1 := events.APIGatewayProxyRequest{
+		HTTPMethod:      req.Method,
+		Path:            req.RawPath,
+		Headers:         map[string]string{},
+		Body:            req.Body,
+		IsBase64Encoded: req.IsBase64Encoded,
+		RequestContext: events.APIGatewayProxyRequestContext{
+			HTTPMethod: req.Method,
+			Path:       req.RawPath,
+			RequestID:  req.RequestID,
+			Identity:   events.APIGatewayRequestIdentity{SourceIP: req.SourceIP}}}
+	for k, v := range req.Headers {
+		v1.Headers[k] = v
+	}
+	if len(req.Cookies) > 0 {
+		v1.Headers[headerCookie] = strings.Join(req.Cookies, "; ")
+	}
+	// `queryStringParameters` joins repeated parameters with commas, so
+	// the raw query string is preferred.
+	if query, err := url.ParseQuery(req.RawQueryString); err == nil && len(query) > 0 {
+		v1.QueryStringParameters = map[string]string{}
+		v1.MultiValueQueryStringParameters = map[string][]string(query)
+		for k, vals := range query {
+			v1.QueryStringParameters[k] = vals[0]
+		}
+	} else if len(req.QueryStringParameters) > 0 {
+		v1.QueryStringParameters = req.QueryStringParameters
+	}
+	return v1
+}
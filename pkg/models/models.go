@@ -2,7 +2,6 @@ package models
 
 import (
 	"encoding/base64"
-	"encoding/json"
 	"io"
 	"net/http"
 	"net/url"
@@ -18,6 +17,7 @@ import (
 	"github.com/valyala/fasthttp"
 
 	"github.com/grokify/chathooks/pkg/config"
+	"github.com/grokify/chathooks/pkg/redact"
 )
 
 const (
@@ -27,6 +27,7 @@ const (
 	QueryParamOutputType     = config.ParamNameOutputType
 	QueryParamOutputURL      = config.ParamNameOutputURL
 	QueryParamToken          = config.ParamNameToken
+	QueryParamRoute          = config.ParamNameRoute
 
 	ParamPayload = "payload"
 )
@@ -38,8 +39,13 @@ var fixedParams = map[string]int{
 	QueryParamOutputType:     4,
 	QueryParamOutputURL:      5,
 	QueryParamToken:          6,
+	QueryParamRoute:          7,
 }
 
+// hookPathPrefixes are the webhook paths that accept a route name suffix,
+// e.g. `/hook/{route}`.
+var hookPathPrefixes = []string{"/hook/", "/webhook/"}
+
 type RequestParams struct {
 	InputType  string `url:"inputType"`
 	OutputType string `url:"outputType"`
@@ -67,6 +73,8 @@ var intervals = [...]string{
 */
 
 type HookData struct {
+	Route             string             `json:"route,omitempty"`
+	RouteName         string             `json:"routeName,omitempty"`
 	InputType         string             `json:"inputType,omitempty"`
 	InputBody         []byte             `json:"inputBody,omitempty"`
 	OutputFormat      string             `json:"outputFormat,omitempty"`
@@ -76,9 +84,26 @@ type HookData struct {
 	Token             string             `json:"token,omitempty"`
 	InputMessage      []byte             `json:"inputMessage,omitempty"`
 	CustomQueryParams url.Values         `json:"customParams,omitempty"`
+	Recipients        []string           `json:"recipients,omitempty"`
 	CanonicalMessage  commonchat.Message `json:"canonicalMessage,omitempty"`
 }
 
+// Redacted returns a copy safe to log or return to the caller, with the
+// token, output URL, secret custom query string parameters and any
+// secrets in the input body redacted.
+func (hd HookData) Redacted() HookData {
+	hd.Token = redact.String(hd.Token)
+	hd.OutputURL = redact.WebhookURL(hd.OutputURL)
+	hd.CustomQueryParams = redact.Values(hd.CustomQueryParams)
+	if len(hd.InputBody) > 0 {
+		hd.InputBody = []byte(redact.Text(string(hd.InputBody)))
+	}
+	if len(hd.InputMessage) > 0 {
+		hd.InputMessage = []byte(redact.Text(string(hd.InputMessage)))
+	}
+	return hd
+}
+
 type hookDataRequest struct {
 	BodyType              MessageBodyType
 	Headers               map[string]string
@@ -87,34 +112,24 @@ type hookDataRequest struct {
 	IsBase64Encoded       bool
 }
 
-// HookDataFromAwsLambdaEvent converts a Lambda event to
-// generic HookData.
-func HookDataFromAwsLambdaEvent(bodyType MessageBodyType, awsReq events.APIGatewayProxyRequest, messageBodyType MessageBodyType) HookData {
+// HookDataFromAwsLambdaEvent converts a Lambda event to generic HookData.
+// Base64 bodies are decoded and URL-encoded bodies are parsed as for the
+// HTTP engines. HTTP API and Function URL events can be converted with
+// `APIGatewayProxyRequestFromV2` and `APIGatewayProxyRequestFromFunctionURL`.
+func HookDataFromAwsLambdaEvent(bodyType MessageBodyType, awsReq events.APIGatewayProxyRequest) HookData {
 	hookData := newHookDataGeneric(hookDataRequest{
 		BodyType:              bodyType,
 		Headers:               awsReq.Headers,
 		Body:                  awsReq.Body,
 		IsBase64Encoded:       awsReq.IsBase64Encoded,
 		QueryStringParameters: awsReq.QueryStringParameters})
-	// `application/x-www-form-urlencoded` is currently not supported
-	// with AWS Lambda because Lambda cannot support URL Query String
-	// parameterss with this Content-Type.
-	if messageBodyType == URLEncoded ||
-		messageBodyType == URLEncodedJSONPayload ||
-		messageBodyType == URLEncodedRails {
-		var jsonData awsJSONWrapper
-		err := json.Unmarshal(hookData.InputMessage, &jsonData)
-		if err == nil {
-			hookData.InputMessage = []byte(jsonData.Body)
-		}
+	hookData.Route = awsReq.Path
+	if len(hookData.RouteName) == 0 {
+		hookData.RouteName = RouteNameFromPath(awsReq.Path)
 	}
 	return hookData
 }
 
-type awsJSONWrapper struct {
-	Body string `json:"body,omitempty"`
-}
-
 /*
 func HookDataFromEawsyLambdaEvent(bodyType MessageBodyType, eawsyReq *apigatewayproxyevt.Event) HookData {
 	return newHookDataGeneric(hookDataRequest{
@@ -160,6 +175,9 @@ func newHookDataForQueryString(queryStringParameters map[string]string) HookData
 	if token, ok := queryStringParameters[QueryParamToken]; ok {
 		data.Token = strings.TrimSpace(token)
 	}
+	if route, ok := queryStringParameters[QueryParamRoute]; ok {
+		data.RouteName = strings.TrimSpace(route)
+	}
 	if namedOutputs, ok := queryStringParameters[QueryParamOutputAdapters]; ok {
 		data.OutputNames = stringsutil.SliceCondenseSpace(strings.Split(namedOutputs, ","), true, false)
 	}
@@ -175,6 +193,8 @@ func newHookDataForQueryString(queryStringParameters map[string]string) HookData
 
 func HookDataFromAnyHTTPReq(bodyType MessageBodyType, aReq anyhttp.Request) HookData {
 	return HookData{
+		Route:             RequestPath(string(aReq.RequestURI())),
+		RouteName:         RouteName(aReq.QueryArgs().GetString(QueryParamRoute), string(aReq.RequestURI())),
 		InputType:         aReq.QueryArgs().GetString(QueryParamInputType),
 		InputBody:         BodyToMessageBytesAnyHTTP(bodyType, aReq),
 		OutputFormat:      config.MustParseOutputFormat(aReq.QueryArgs().GetString(QueryParamOutputFormat)),
@@ -187,6 +207,8 @@ func HookDataFromAnyHTTPReq(bodyType MessageBodyType, aReq anyhttp.Request) Hook
 
 func HookDataFromNetHTTPReq(bodyType MessageBodyType, req *http.Request) HookData {
 	return HookData{
+		Route:        req.URL.Path,
+		RouteName:    RouteName(hum.GetReqQueryParam(req, QueryParamRoute), req.URL.Path),
 		InputType:    hum.GetReqQueryParam(req, QueryParamInputType),
 		InputBody:    BodyToMessageBytesNetHTTP(bodyType, req),
 		OutputFormat: config.MustParseOutputFormat(hum.GetReqQueryParam(req, QueryParamOutputFormat)),
@@ -198,6 +220,8 @@ func HookDataFromNetHTTPReq(bodyType MessageBodyType, req *http.Request) HookDat
 
 func HookDataFromFastHTTPReqCtx(bodyType MessageBodyType, ctx *fasthttp.RequestCtx) HookData {
 	return HookData{
+		Route:        string(ctx.Path()),
+		RouteName:    RouteName(fhu.GetReqQueryParam(ctx, QueryParamRoute), string(ctx.Path())),
 		InputType:    fhu.GetReqQueryParam(ctx, QueryParamInputType),
 		InputBody:    BodyToMessageBytesFastHTTP(bodyType, ctx),
 		OutputFormat: config.MustParseOutputFormat(fhu.GetReqQueryParam(ctx, QueryParamOutputFormat)),
@@ -207,6 +231,58 @@ func HookDataFromFastHTTPReqCtx(bodyType MessageBodyType, ctx *fasthttp.RequestC
 		OutputNames:  fhu.GetSplitReqQueryParam(ctx, QueryParamOutputAdapters, ",'")}
 }
 
+// RouteName returns the `route` query string parameter if present,
+// otherwise the route name in the request path.
+func RouteName(routeParam, requestURI string) string {
+	if routeParam = strings.TrimSpace(routeParam); len(routeParam) > 0 {
+		return routeParam
+	}
+	return RouteNameFromPath(RequestPath(requestURI))
+}
+
+// RouteNameFromPath returns the route name for webhook paths like
+// `/hook/{route}`, or an empty string.
+func RouteNameFromPath(path string) string {
+	for _, prefix := range hookPathPrefixes {
+		if name, ok := strings.CutPrefix(path, prefix); ok {
+			return strings.Trim(name, "/")
+		}
+	}
+	return ""
+}
+
+// ApplyRoute fills parameters not set in the request from the route.
+func (hookData *HookData) ApplyRoute(route config.RouteConfig) {
+	if len(hookData.InputType) == 0 {
+		hookData.InputType = route.InputType
+	}
+	if len(hookData.OutputType) == 0 && len(hookData.OutputURL) == 0 {
+		hookData.OutputType = route.OutputType
+		hookData.OutputURL = route.OutputURL
+	}
+	if len(hookData.OutputFormat) == 0 {
+		hookData.OutputFormat = config.MustParseOutputFormat(route.OutputFormat)
+	}
+	if len(stringsutil.SliceCondenseSpace(hookData.OutputNames, true, false)) == 0 {
+		hookData.OutputNames = route.Adapters
+	}
+	if hookData.CustomQueryParams == nil {
+		hookData.CustomQueryParams = url.Values{}
+	}
+	for key, val := range route.Params {
+		if _, ok := hookData.CustomQueryParams[key]; !ok {
+			hookData.CustomQueryParams.Set(key, val)
+		}
+	}
+	hookData.Recipients = route.Recipients
+}
+
+// RequestPath returns the path of a request URI without the query string.
+func RequestPath(requestURI string) string {
+	path, _, _ := strings.Cut(requestURI, "?")
+	return path
+}
+
 func bodyToMessageBytesGeneric(bodyType MessageBodyType, headers map[string]string, body string, isBase64Encoded bool) []byte {
 	var bodyConverted []byte
 	if isBase64Encoded {
@@ -224,11 +300,9 @@ func bodyToMessageBytesGeneric(bodyType MessageBodyType, headers map[string]stri
 		}
 		bodyConverted = []byte(v.Get(ParamPayload))
 	case URLEncodedJSONPayloadOrJSON:
-		if ct, ok := headers["content-type"]; ok {
-			ct = strings.TrimSpace(strings.ToLower(ct))
-			if strings.Contains(ct, hum.ContentTypeAppJSON) {
-				return []byte(body)
-			}
+		ct := strings.TrimSpace(strings.ToLower(headerValue(headers, hum.HeaderContentType)))
+		if strings.Contains(ct, hum.ContentTypeAppJSON) {
+			return []byte(body)
 		}
 		v, err := url.ParseQuery(body)
 		if err != nil {
@@ -244,6 +318,13 @@ func bodyToMessageBytesGeneric(bodyType MessageBodyType, headers map[string]stri
 	return bodyConverted
 }
 
+// BodyToMessageBytes returns the message bytes for a raw request body with
+// the supplied content type, e.g. an example event.
+func BodyToMessageBytes(bodyType MessageBodyType, contentType string, body []byte) []byte {
+	return bodyToMessageBytesGeneric(bodyType,
+		map[string]string{hum.HeaderContentType: contentType}, string(body), false)
+}
+
 func BodyToMessageBytesAnyHTTP(bodyType MessageBodyType, aReq anyhttp.Request) []byte {
 	switch bodyType {
 	case URLEncodedJSONPayload:
@@ -270,10 +351,28 @@ func BodyToMessageBytesAnyHTTP(bodyType MessageBodyType, aReq anyhttp.Request) [
 		if err != nil {
 			return []byte{}
 		}
+		// `net/http` consumes a form body when the form is parsed.
+		if req, ok := aReq.(*anyhttp.RequestNetHTTP); ok && len(bytes) == 0 && len(req.Raw.PostForm) > 0 {
+			return []byte(req.Raw.PostForm.Encode())
+		}
 		return bytes
 	}
 }
 
+// headerValue returns the value of a header in a map with header names
+// in any case, such as Lambda event headers.
+func headerValue(headers map[string]string, name string) string {
+	if v, ok := headers[name]; ok {
+		return v
+	}
+	for k, v := range headers {
+		if strings.EqualFold(k, name) {
+			return v
+		}
+	}
+	return ""
+}
+
 func BodyToMessageBytesNetHTTP(bodyType MessageBodyType, req *http.Request) []byte {
 	switch bodyType {
 	case URLEncodedJSONPayload:
@@ -320,90 +419,3 @@ type AwsAPIGatewayProxyOutput struct {
 	Body            string            `json:"body"`
 	Headers         map[string]string `json:"headers"`
 }
-
-type ErrorInfo struct {
-	StatusCode int
-	Body       []byte
-}
-
-type ResponseInfo struct {
-	HookData   HookData    `json:"hookData,omitempty"`
-	Responses  []ErrorInfo `json:"responses,omitempty"`
-	StatusCode int         `json:"statusCode,omitempty"`
-	//URL        string      `json:"url,omitempty"`
-	//Body       any         `json:"body,omitempty"`
-	//InputType  string      `json:"inputType,omitempty"`
-	//OutputType string      `json:"outputType,omitempty"`
-}
-
-func (ri *ResponseInfo) ToAPIGatewayProxyResponse() (events.APIGatewayProxyResponse, error) {
-	res := events.APIGatewayProxyResponse{
-		StatusCode: ri.StatusCode,
-	}
-
-	bodyBytes, err := json.Marshal(ri)
-	if err != nil {
-		return res, nil
-	}
-	res.Body = string(bodyBytes)
-
-	return res, nil
-}
-
-/*
-func ErrorsInfoToResponseInfo(errs ...ErrorInfo) ErrorInfo {
-	resInfo := ResponseInfo{
-		Responses: errs,
-	}
-	return resInfo
-}
-*/
-
-func GetMaxStatusCode(errs ...ErrorInfo) int {
-	if len(errs) == 0 {
-		return http.StatusOK
-	} else if len(errs) == 1 {
-		return errs[0].StatusCode
-	}
-	maxStatus := 200
-	for _, errInfo := range errs {
-		if errInfo.StatusCode > maxStatus {
-			maxStatus = errInfo.StatusCode
-		}
-	}
-	return maxStatus
-}
-
-func ErrorsInfoToResponseInfoOld(errs ...ErrorInfo) ErrorInfo {
-	resInfo := ErrorInfo{}
-	if len(errs) == 0 {
-		resInfo.StatusCode = http.StatusOK
-		return resInfo
-	}
-	bodyBytes, err := json.Marshal(errs)
-	if err != nil {
-		resInfo.Body = []byte(err.Error())
-	} else {
-		resInfo.Body = bodyBytes
-	}
-	if len(errs) == 1 {
-		resInfo.StatusCode = errs[0].StatusCode
-	} else {
-		maxStatus := 0
-		for _, errInfo := range errs {
-			if errInfo.StatusCode > maxStatus {
-				maxStatus = errInfo.StatusCode
-			}
-		}
-		resInfo.StatusCode = maxStatus
-	}
-	return resInfo
-}
-
-func BuildAwsAPIGatewayProxyResponse(hookData HookData, errs ...ErrorInfo) (events.APIGatewayProxyResponse, error) {
-	resInfo := ResponseInfo{
-		HookData:   hookData,
-		Responses:  errs,
-		StatusCode: GetMaxStatusCode(errs...)}
-	return resInfo.ToAPIGatewayProxyResponse()
-}
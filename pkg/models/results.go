@@ -0,0 +1,209 @@
+package models
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/aws/aws-lambda-go/events"
+	hum "github.com/grokify/mogo/net/http/httputilmore"
+	"github.com/grokify/sogo/net/http/anyhttp"
+
+	"github.com/grokify/chathooks/pkg/config"
+	"github.com/grokify/chathooks/pkg/redact"
+)
+
+// ErrorType classifies an error that stopped a request before delivery.
+type ErrorType string
+
+const (
+	ErrorTypeDecode    ErrorType = "decode"    // the request could not be decoded, `400`.
+	ErrorTypeNormalize ErrorType = "normalize" // the handler could not convert the event, `422`.
+	ErrorTypeAuth      ErrorType = "auth"      // the request token is missing or invalid, `401`.
+	ErrorTypeQueue     ErrorType = "queue"     // the request could not be queued, `503`.
+)
+
+// ErrorInfo is an error that stopped a request before delivery.
+type ErrorInfo struct {
+	Type       ErrorType `json:"type"`
+	StatusCode int       `json:"statusCode"`
+	Message    string    `json:"message"`
+}
+
+// DeliveryResult is the outcome of sending a message to one output adapter.
+// `StatusCode` is `502` when no response was received, with the transport
+// error in `Error`. For other failures, `Error` holds the response body.
+type DeliveryResult struct {
+	Adapter    string `json:"adapter"`
+	Host       string `json:"host,omitempty"`
+	StatusCode int    `json:"statusCode"`
+	LatencyMS  int64  `json:"latencyMs"`
+	Retries    int    `json:"retries"`
+	Error      string `json:"error,omitempty"`
+	// Accepted is set when an adapter treats a status outside `2xx` as
+	// success.
+	Accepted bool `json:"accepted,omitempty"`
+}
+
+// OK returns true if the delivery succeeded.
+func (d DeliveryResult) OK() bool {
+	return d.Accepted || (d.StatusCode >= 200 && d.StatusCode < 300 && len(d.Error) == 0)
+}
+
+// Result is the outcome of handling an inbound webhook request.
+// `MessageID` is set when the request was queued for asynchronous
+// delivery instead of delivered.
+type Result struct {
+	Errors     []ErrorInfo      `json:"errors,omitempty"`
+	Deliveries []DeliveryResult `json:"deliveries,omitempty"`
+	MessageID  string           `json:"messageId,omitempty"`
+}
+
+// NewErrorResult returns a `Result` for a request that failed before delivery.
+func NewErrorResult(errType ErrorType, statusCode int, msg string) Result {
+	return Result{Errors: []ErrorInfo{{
+		Type:       errType,
+		StatusCode: statusCode,
+		Message:    msg}}}
+}
+
+// StatusCode returns the HTTP status code for the result. Errors return
+// their own status code and queued requests return `202`. Otherwise,
+// `502` is returned when every delivery failed, `207` when only some
+// failed and `200` when all succeeded or there were no outputs.
+func (r Result) StatusCode() int {
+	if len(r.Errors) > 0 {
+		maxStatus := 0
+		for _, errInfo := range r.Errors {
+			if errInfo.StatusCode > maxStatus {
+				maxStatus = errInfo.StatusCode
+			}
+		}
+		return maxStatus
+	}
+	if len(r.MessageID) > 0 {
+		return http.StatusAccepted
+	}
+	failed := 0
+	for _, del := range r.Deliveries {
+		if !del.OK() {
+			failed++
+		}
+	}
+	if failed == 0 {
+		return http.StatusOK
+	} else if failed == len(r.Deliveries) {
+		return http.StatusBadGateway
+	}
+	return http.StatusMultiStatus
+}
+
+// OK returns true if the request had no errors and every delivery
+// succeeded.
+func (r Result) OK() bool {
+	return len(r.Errors) == 0 && len(r.Failed()) == 0
+}
+
+// Failed returns the deliveries that did not succeed.
+func (r Result) Failed() []DeliveryResult {
+	var dels []DeliveryResult
+	for _, del := range r.Deliveries {
+		if !del.OK() {
+			dels = append(dels, del)
+		}
+	}
+	return dels
+}
+
+// ResponseInfo is the JSON response body for a handled request. It is the
+// same for every HTTP engine and AWS Lambda.
+type ResponseInfo struct {
+	StatusCode int              `json:"statusCode"`
+	Errors     []ErrorInfo      `json:"errors,omitempty"`
+	Deliveries []DeliveryResult `json:"deliveries,omitempty"`
+	MessageID  string           `json:"messageId,omitempty"`
+	HookData   *HookData        `json:"hookData,omitempty"`
+}
+
+// NewResponseInfo returns the `ResponseInfo` for a request in a
+// `config.ResponseMode*` mode. `minimal` returns the status code and error
+// types, `summary` adds error messages, delivery results and the queue
+// message ID, and `debug` adds the hook data. Secrets are redacted in
+// every mode. `hookData` is nil for requests rejected before it was read,
+// e.g. for a missing token.
+func NewResponseInfo(mode string, hookData *HookData, result Result) ResponseInfo {
+	ri := ResponseInfo{StatusCode: result.StatusCode()}
+	for _, errInfo := range result.Errors {
+		if mode == config.ResponseModeMinimal {
+			errInfo.Message = ""
+		} else {
+			errInfo.Message = redact.Text(errInfo.Message)
+		}
+		ri.Errors = append(ri.Errors, errInfo)
+	}
+	if mode == config.ResponseModeMinimal {
+		return ri
+	}
+	for _, del := range result.Deliveries {
+		del.Error = redact.Text(del.Error)
+		ri.Deliveries = append(ri.Deliveries, del)
+	}
+	ri.MessageID = result.MessageID
+	if mode == config.ResponseModeDebug && hookData != nil {
+		redacted := hookData.Redacted()
+		ri.HookData = &redacted
+	}
+	return ri
+}
+
+func (ri *ResponseInfo) ToAPIGatewayProxyResponse() (events.APIGatewayProxyResponse, error) {
+	res := events.APIGatewayProxyResponse{
+		StatusCode: ri.StatusCode,
+		Headers:    map[string]string{hum.HeaderContentType: hum.ContentTypeAppJSONUtf8}}
+	bodyBytes, err := json.Marshal(ri)
+	if err != nil {
+		return res, err
+	}
+	res.Body = string(bodyBytes)
+	return res, nil
+}
+
+func BuildAwsAPIGatewayProxyResponse(mode string, hookData HookData, result Result) (events.APIGatewayProxyResponse, error) {
+	resInfo := NewResponseInfo(mode, &hookData, result)
+	return resInfo.ToAPIGatewayProxyResponse()
+}
+
+// BuildAwsAPIGatewayProxyErrorResponse returns the response for a request
+// rejected before its hook data was read, e.g. for a missing token.
+func BuildAwsAPIGatewayProxyErrorResponse(mode string, errType ErrorType, statusCode int, msg string) (events.APIGatewayProxyResponse, error) {
+	resInfo := NewResponseInfo(mode, nil, NewErrorResult(errType, statusCode, msg))
+	return resInfo.ToAPIGatewayProxyResponse()
+}
+
+// WriteAnyHTTPResponse writes the `ResponseInfo` JSON for a handled request.
+func WriteAnyHTTPResponse(aRes anyhttp.Response, mode string, hookData HookData, result Result) error {
+	awsRes, err := BuildAwsAPIGatewayProxyResponse(mode, hookData, result)
+	if err != nil {
+		aRes.SetStatusCode(http.StatusInternalServerError)
+		return err
+	}
+	return writeAnyHTTP(aRes, awsRes)
+}
+
+// WriteAnyHTTPErrorResponse writes the `ResponseInfo` JSON for a request
+// rejected before its hook data was read.
+func WriteAnyHTTPErrorResponse(aRes anyhttp.Response, mode string, errType ErrorType, statusCode int, msg string) error {
+	awsRes, err := BuildAwsAPIGatewayProxyErrorResponse(mode, errType, statusCode, msg)
+	if err != nil {
+		aRes.SetStatusCode(http.StatusInternalServerError)
+		return err
+	}
+	return writeAnyHTTP(aRes, awsRes)
+}
+
+func writeAnyHTTP(aRes anyhttp.Response, awsRes events.APIGatewayProxyResponse) error {
+	// `net/http` sends the headers with the status code, so set them first.
+	aRes.SetContentType(hum.ContentTypeAppJSONUtf8)
+	aRes.SetStatusCode(awsRes.StatusCode)
+	_, err := aRes.SetBodyBytes([]byte(awsRes.Body))
+	return err
+}
@@ -0,0 +1,89 @@
+package models
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/grokify/chathooks/pkg/config"
+)
+
+var ResultStatusCodeTests = []struct {
+	v    Result
+	want int
+}{
+	{Result{}, http.StatusOK},
+	{NewErrorResult(ErrorTypeDecode, http.StatusBadRequest, "empty"), http.StatusBadRequest},
+	{NewErrorResult(ErrorTypeAuth, http.StatusUnauthorized, "token"), http.StatusUnauthorized},
+	{Result{MessageID: "1"}, http.StatusAccepted},
+	{Result{Deliveries: []DeliveryResult{
+		{Adapter: "glip", StatusCode: http.StatusOK},
+		{Adapter: "slack", StatusCode: http.StatusNoContent}}}, http.StatusOK},
+	{Result{Deliveries: []DeliveryResult{
+		{Adapter: "glip", StatusCode: http.StatusOK},
+		{Adapter: "slack", StatusCode: http.StatusForbidden}}}, http.StatusMultiStatus},
+	{Result{Deliveries: []DeliveryResult{
+		{Adapter: "http", StatusCode: http.StatusConflict, Accepted: true}}}, http.StatusOK},
+	{Result{Deliveries: []DeliveryResult{
+		{Adapter: "http", StatusCode: http.StatusOK, Error: "unexpected status [200]"}}}, http.StatusBadGateway},
+	{Result{Deliveries: []DeliveryResult{
+		{Adapter: "glip", StatusCode: http.StatusBadGateway},
+		{Adapter: "slack", StatusCode: http.StatusForbidden}}}, http.StatusBadGateway}}
+
+func TestResultStatusCode(t *testing.T) {
+	for _, tt := range ResultStatusCodeTests {
+		try := tt.v.StatusCode()
+		if try != tt.want {
+			t.Errorf("Result.StatusCode(%v): want [%v], got [%v]", tt.v, tt.want, try)
+		}
+	}
+}
+
+var ResponseInfoModeTests = []struct {
+	mode           string
+	wantMessage    bool
+	wantDeliveries bool
+	wantHookData   bool
+}{
+	{config.ResponseModeMinimal, false, false, false},
+	{config.ResponseModeSummary, true, true, false},
+	{"", true, true, false},
+	{config.ResponseModeDebug, true, true, true}}
+
+func TestNewResponseInfoModes(t *testing.T) {
+	hookData := HookData{
+		InputType:         "travisci",
+		Token:             "abc",
+		OutputURL:         "https://hooks.glip.com/webhook/11112222-3333-4444-5555-666677778888",
+		CustomQueryParams: url.Values{"token": {"abc"}}}
+	result := Result{
+		Errors: []ErrorInfo{{Type: ErrorTypeNormalize, StatusCode: http.StatusUnprocessableEntity, Message: "unexpected end of JSON input"}},
+		Deliveries: []DeliveryResult{{
+			Adapter:    "slack",
+			Host:       "hooks.slack.com",
+			StatusCode: http.StatusNotFound,
+			Error:      "no_service for https://hooks.slack.com/services/T000/B000/XXXX"}}}
+	for _, tt := range ResponseInfoModeTests {
+		ri := NewResponseInfo(tt.mode, &hookData, result)
+		if (len(ri.Errors[0].Message) > 0) != tt.wantMessage {
+			t.Errorf("NewResponseInfo(%s): want message [%v], got [%s]", tt.mode, tt.wantMessage, ri.Errors[0].Message)
+		}
+		if (len(ri.Deliveries) > 0) != tt.wantDeliveries {
+			t.Errorf("NewResponseInfo(%s): want deliveries [%v], got [%v]", tt.mode, tt.wantDeliveries, ri.Deliveries)
+		}
+		if (ri.HookData != nil) != tt.wantHookData {
+			t.Errorf("NewResponseInfo(%s): want hook data [%v], got [%v]", tt.mode, tt.wantHookData, ri.HookData)
+		}
+		bytes, err := json.Marshal(ri)
+		if err != nil {
+			t.Fatalf("NewResponseInfo(%s): cannot marshal [%v]", tt.mode, err)
+		}
+		for _, secret := range []string{"abc", "11112222", "XXXX"} {
+			if strings.Contains(string(bytes), secret) {
+				t.Errorf("NewResponseInfo(%s): secret [%s] in [%s]", tt.mode, secret, string(bytes))
+			}
+		}
+	}
+}
@@ -0,0 +1,98 @@
+package queue
+
+import (
+	"context"
+	"strconv"
+	"sync"
+
+	"github.com/aws/aws-lambda-go/events"
+
+	"github.com/grokify/chathooks/pkg/models"
+)
+
+// EventSourceSQS is the `eventSource` of SQS records.
+const EventSourceSQS = "aws:sqs"
+
+// Memory is an in-memory queue for local testing. Messages are received
+// as SQS events so they can be passed to the SQS consumer.
+type Memory struct {
+	mu       sync.Mutex
+	messages []events.SQSMessage
+	nextID   int
+}
+
+// NewMemory returns an empty in-memory queue.
+func NewMemory() *Memory {
+	return &Memory{}
+}
+
+// Enqueue adds hook data to the queue.
+func (m *Memory) Enqueue(ctx context.Context, hookData models.HookData) (string, error) {
+	body, err := Marshal(hookData)
+	if err != nil {
+		return "", err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.nextID++
+	id := strconv.Itoa(m.nextID)
+	m.messages = append(m.messages, events.SQSMessage{
+		MessageId:   id,
+		Body:        string(body),
+		EventSource: EventSourceSQS})
+	return id, nil
+}
+
+// Len returns the number of messages in the queue.
+func (m *Memory) Len() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return len(m.messages)
+}
+
+// Receive removes up to `maxMessages` messages from the queue and returns
+// them as an SQS event.
+func (m *Memory) Receive(maxMessages int) events.SQSEvent {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if maxMessages <= 0 || maxMessages > len(m.messages) {
+		maxMessages = len(m.messages)
+	}
+	event := events.SQSEvent{Records: m.messages[:maxMessages:maxMessages]}
+	m.messages = m.messages[maxMessages:]
+	return event
+}
+
+// Process passes the queued messages to `handle` in batches of up to
+// `batchSize`, as SQS invokes a Lambda function. Messages reported as
+// batch item failures are returned to the queue, as SQS does once their
+// visibility timeout expires, and the failures are returned. If `handle`
+// returns an error, the whole batch is returned to the queue.
+func (m *Memory) Process(ctx context.Context, batchSize int, handle func(context.Context, events.SQSEvent) (events.SQSEventResponse, error)) (events.SQSEventResponse, error) {
+	failures := events.SQSEventResponse{}
+	var retry []events.SQSMessage
+	var err error
+	for m.Len() > 0 {
+		event := m.Receive(batchSize)
+		res, herr := handle(ctx, event)
+		if herr != nil {
+			err = herr
+			retry = append(retry, event.Records...)
+			continue
+		}
+		failed := map[string]bool{}
+		for _, item := range res.BatchItemFailures {
+			failed[item.ItemIdentifier] = true
+		}
+		for _, msg := range event.Records {
+			if failed[msg.MessageId] {
+				retry = append(retry, msg)
+			}
+		}
+		failures.BatchItemFailures = append(failures.BatchItemFailures, res.BatchItemFailures...)
+	}
+	m.mu.Lock()
+	m.messages = append(m.messages, retry...)
+	m.mu.Unlock()
+	return failures, err
+}
@@ -0,0 +1,66 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+
+	"github.com/grokify/chathooks/pkg/models"
+)
+
+// MemoryProcessTests are batch results for three queued messages.
+// Failed messages, and every message of a batch that returns an error,
+// are returned to the queue.
+var MemoryProcessTests = []struct {
+	failIDs      []string
+	err          error
+	wantFailures int
+	wantQueued   int
+}{
+	{nil, nil, 0, 0},
+	{[]string{"2"}, nil, 1, 1},
+	{nil, errors.New("timeout"), 0, 3}}
+
+func TestMemoryProcess(t *testing.T) {
+	for _, tt := range MemoryProcessTests {
+		mem := NewMemory()
+		for _, inputType := range []string{"aha", "heroku", "travisci"} {
+			if _, err := mem.Enqueue(context.Background(), models.HookData{InputType: inputType}); err != nil {
+				t.Fatal(err)
+			}
+		}
+		calls := 0
+		res, err := mem.Process(context.Background(), 2, func(ctx context.Context, event events.SQSEvent) (events.SQSEventResponse, error) {
+			calls++
+			res := events.SQSEventResponse{}
+			for _, id := range tt.failIDs {
+				for _, msg := range event.Records {
+					if msg.MessageId == id {
+						res.BatchItemFailures = append(res.BatchItemFailures, events.SQSBatchItemFailure{ItemIdentifier: id})
+					}
+				}
+			}
+			return res, tt.err
+		})
+		if err != tt.err || calls != 2 {
+			t.Errorf("Memory.Process(%v, %v): want error [%v] in [2] batches, got [%v] in [%d]", tt.failIDs, tt.err, tt.err, err, calls)
+		}
+		if len(res.BatchItemFailures) != tt.wantFailures || mem.Len() != tt.wantQueued {
+			t.Errorf("Memory.Process(%v, %v): want failures [%d] queued [%d], got [%d] [%d]",
+				tt.failIDs, tt.err, tt.wantFailures, tt.wantQueued, len(res.BatchItemFailures), mem.Len())
+		}
+	}
+}
+
+func TestMarshal(t *testing.T) {
+	body, err := Marshal(models.HookData{InputType: "travisci", InputBody: []byte(`{"id":1}`)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	hookData, err := Unmarshal(body)
+	if err != nil || hookData.InputType != "travisci" || string(hookData.InputBody) != `{"id":1}` {
+		t.Errorf("Unmarshal(Marshal()): want [travisci], got [%v] error [%v]", hookData, err)
+	}
+}
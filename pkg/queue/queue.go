@@ -0,0 +1,30 @@
+// Package queue holds webhook requests for asynchronous delivery. The
+// function receiving webhooks validates and enqueues each request's
+// `models.HookData` and a consumer, e.g. an AWS Lambda function
+// triggered by SQS, normalizes and delivers it.
+package queue
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/grokify/chathooks/pkg/models"
+)
+
+// Queue is a message queue for hook data.
+type Queue interface {
+	// Enqueue adds hook data to the queue and returns the message ID.
+	Enqueue(ctx context.Context, hookData models.HookData) (string, error)
+}
+
+// Marshal returns the message body for hook data.
+func Marshal(hookData models.HookData) ([]byte, error) {
+	return json.Marshal(hookData)
+}
+
+// Unmarshal returns the hook data in a message body.
+func Unmarshal(body []byte) (models.HookData, error) {
+	hookData := models.HookData{}
+	err := json.Unmarshal(body, &hookData)
+	return hookData, err
+}
@@ -0,0 +1,42 @@
+package queue
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+
+	"github.com/grokify/chathooks/pkg/models"
+)
+
+// SQS is an Amazon SQS queue.
+type SQS struct {
+	Client   *sqs.Client
+	QueueURL string
+}
+
+// NewSQS returns an SQS queue using the default AWS configuration, e.g.
+// the Lambda execution role.
+func NewSQS(ctx context.Context, queueURL string) (*SQS, error) {
+	cfg, err := awsconfig.LoadDefaultConfig(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &SQS{Client: sqs.NewFromConfig(cfg), QueueURL: queueURL}, nil
+}
+
+// Enqueue sends hook data to the queue.
+func (q *SQS) Enqueue(ctx context.Context, hookData models.HookData) (string, error) {
+	body, err := Marshal(hookData)
+	if err != nil {
+		return "", err
+	}
+	out, err := q.Client.SendMessage(ctx, &sqs.SendMessageInput{
+		QueueUrl:    aws.String(q.QueueURL),
+		MessageBody: aws.String(string(body))})
+	if err != nil {
+		return "", err
+	}
+	return aws.ToString(out.MessageId), nil
+}
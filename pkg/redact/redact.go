@@ -0,0 +1,140 @@
+// Package redact removes secrets such as verification tokens and chat webhook
+// URLs from text before it is logged or returned to a caller.
+package redact
+
+import (
+	"io"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// Redacted replaces a secret value.
+const Redacted = "REDACTED"
+
+// Params are the query string parameter names whose values are secrets.
+// Names are matched case-insensitively.
+var Params = []string{
+	"token",
+	"outputURL",
+	"url",
+	"key",
+	"secret",
+	"signature",
+	"access_token",
+	"accessToken",
+	"api_key",
+	"apiKey",
+	"password",
+}
+
+var (
+	rxURL   = regexp.MustCompile(`https?://[^\s"'<>\\]+`)
+	rxParam = regexp.MustCompile(`(?i)\b(` + strings.Join(quoteAll(Params), "|") + `)=([^&\s"'\\]+)`)
+	rxHook  = regexp.MustCompile(`(?i)(^hooks?\.|webhook|/hooks?/|/bot\d+:)`)
+)
+
+func quoteAll(s []string) []string {
+	q := make([]string, len(s))
+	for i, v := range s {
+		q[i] = regexp.QuoteMeta(v)
+	}
+	return q
+}
+
+// IsParam returns true if the query string parameter is a secret.
+func IsParam(name string) bool {
+	for _, p := range Params {
+		if strings.EqualFold(p, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// String returns `Redacted` for a non-empty secret.
+func String(s string) string {
+	if len(s) == 0 {
+		return s
+	}
+	return Redacted
+}
+
+// WebhookURL keeps only the scheme and host of a chat webhook URL, since
+// the path and query string usually contain the secret.
+func WebhookURL(s string) string {
+	if len(s) == 0 {
+		return s
+	}
+	u, err := url.Parse(s)
+	if err != nil || len(u.Host) == 0 {
+		return Redacted
+	}
+	return u.Scheme + "://" + u.Host + "/" + Redacted
+}
+
+// URL redacts the secret query string parameters and user info password of
+// a URL, and the path of a URL that looks like a chat webhook, e.g.
+// `hooks.slack.com`, a path containing `webhook` or `/hooks/`, or a
+// Telegram bot token.
+func URL(s string) string {
+	u, err := url.Parse(s)
+	if err != nil {
+		return rxParam.ReplaceAllString(s, "$1="+Redacted)
+	}
+	if rxHook.MatchString(u.Host) || rxHook.MatchString(u.Path) {
+		return WebhookURL(s)
+	}
+	if _, ok := u.User.Password(); ok {
+		u.User = url.UserPassword(u.User.Username(), Redacted)
+	}
+	if len(u.RawQuery) > 0 {
+		u.RawQuery = rxParam.ReplaceAllString(u.RawQuery, "$1="+Redacted)
+	}
+	return u.String()
+}
+
+// Values returns a copy of the query string parameters with secret
+// values redacted.
+func Values(v url.Values) url.Values {
+	if v == nil {
+		return nil
+	}
+	r := url.Values{}
+	for k, vals := range v {
+		for _, val := range vals {
+			if IsParam(k) {
+				val = String(val)
+			}
+			r.Add(k, val)
+		}
+	}
+	return r
+}
+
+// Text redacts the URLs and secret `name=value` pairs in free text, such
+// as a log line.
+func Text(s string) string {
+	s = rxURL.ReplaceAllStringFunc(s, URL)
+	return rxParam.ReplaceAllString(s, "$1="+Redacted)
+}
+
+// Writer redacts each write before passing it to the underlying writer.
+// It is meant for line-oriented output such as a logger.
+type Writer struct {
+	w io.Writer
+}
+
+// NewWriter returns a `Writer` wrapping `w`.
+func NewWriter(w io.Writer) Writer {
+	return Writer{w: w}
+}
+
+// Write redacts `p` and writes it. It returns `len(p)` on success so
+// callers do not treat the changed length as a short write.
+func (rw Writer) Write(p []byte) (int, error) {
+	if _, err := io.WriteString(rw.w, Text(string(p))); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
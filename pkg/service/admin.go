@@ -0,0 +1,172 @@
+package service
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"net/http"
+	"strings"
+
+	"github.com/grokify/mogo/net/http/httputilmore"
+	"github.com/grokify/sogo/net/http/anyhttp"
+	"github.com/rs/zerolog/log"
+	"github.com/valyala/fasthttp"
+
+	"github.com/grokify/chathooks/pkg/models"
+	"github.com/grokify/chathooks/pkg/templates"
+)
+
+const (
+	AdminRealm = `Basic realm="chathooks admin"`
+	// FormParamReplayToken is the admin form parameter with the replay
+	// token, which protects replay from cross-site requests.
+	FormParamReplayToken = "replayToken"
+)
+
+// HandleAdminAnyRequest serves the admin page listing recent events, the raw
+// body of an event and event replay. The admin page is only available when
+// `CHATHOOKS_ADMIN_TOKEN` is set. Requests authenticate with HTTP Basic auth
+// using the admin token as the password, or with an `Authorization: Bearer` header.
+// Browsers resend Basic credentials automatically, so replay with Basic auth
+// also requires the event's replay token, posted by the admin page form.
+func (svc *Service) HandleAdminAnyRequest(aRes anyhttp.Response, aReq anyhttp.Request) {
+	log.Info().Msg("HANDLE_ADMIN_AnyHTTP")
+	if svc.History == nil {
+		aRes.SetStatusCode(http.StatusNotFound)
+		return
+	}
+	if !svc.adminAuthorized(aReq.HeaderString(httputilmore.HeaderAuthorization)) {
+		log.Warn().Msg("E_ADMIN_UNAUTHORIZED")
+		aRes.SetHeader(httputilmore.HeaderWWWAuthenticate, AdminRealm)
+		aRes.SetStatusCode(http.StatusUnauthorized)
+		return
+	}
+
+	method := string(aReq.Method())
+	switch models.RequestPath(string(aReq.RequestURI())) {
+	case PathAdmin:
+		if method != http.MethodGet {
+			aRes.SetStatusCode(http.StatusMethodNotAllowed)
+			return
+		}
+		data := templates.AdminData{
+			HomeURL:     svc.Config.HomeURL,
+			Events:      svc.History.Events(),
+			ReplayToken: svc.replayToken}
+		writeAdminBody(aRes, httputilmore.ContentTypeTextHTMLUtf8, []byte(templates.AdminPage(data)))
+	case PathAdminRaw:
+		if method != http.MethodGet {
+			aRes.SetStatusCode(http.StatusMethodNotAllowed)
+			return
+		}
+		evt, ok := svc.History.Event(aReq.QueryArgs().GetString(QueryParamEventID))
+		if !ok {
+			aRes.SetStatusCode(http.StatusNotFound)
+			return
+		}
+		writeAdminBody(aRes, httputilmore.ContentTypeTextPlainUtf8, evt.HookData.InputBody)
+	case PathAdminReplay:
+		if method != http.MethodPost {
+			aRes.SetStatusCode(http.StatusMethodNotAllowed)
+			return
+		}
+		eventID := aReq.QueryArgs().GetString(QueryParamEventID)
+		if !isBearer(aReq.HeaderString(httputilmore.HeaderAuthorization)) && !svc.replayTokenValid(aReq, eventID) {
+			log.Warn().Str("event_id", eventID).Msg("E_ADMIN_REPLAY_TOKEN_INVALID")
+			aRes.SetStatusCode(http.StatusForbidden)
+			return
+		}
+		evt, ok := svc.History.Event(eventID)
+		if !ok {
+			aRes.SetStatusCode(http.StatusNotFound)
+			return
+		}
+		handler, ok := svc.HandlerSet.Handlers[evt.InputType]
+		if !ok {
+			aRes.SetStatusCode(http.StatusNotFound)
+			return
+		}
+		hookData, ok := evt.ReplayHookData()
+		if !ok {
+			log.Warn().Str("event_id", evt.ID).Msg("E_ADMIN_REPLAY_TRUNCATED")
+			aRes.SetStatusCode(http.StatusConflict)
+			return
+		}
+		log.Info().
+			Str("event_id", evt.ID).
+			Str("handler_input_type", evt.InputType).
+			Msg("ADMIN_REPLAY")
+		hookData.Route = PathAdminReplay
+		// The handler records the replay as a new event.
+		handler.HandleCanonical(hookData)
+		aRes.SetHeader(httputilmore.HeaderLocation, PathAdmin)
+		aRes.SetStatusCode(http.StatusSeeOther)
+	default:
+		aRes.SetStatusCode(http.StatusNotFound)
+	}
+}
+
+func (svc *Service) HandleAdminNetHTTP(res http.ResponseWriter, req *http.Request) {
+	svc.HandleAdminAnyRequest(anyhttp.NewResReqNetHTTP(res, req))
+}
+
+func (svc *Service) HandleAdminFastHTTP(ctx *fasthttp.RequestCtx) {
+	svc.HandleAdminAnyRequest(anyhttp.NewResReqFastHTTP(ctx))
+}
+
+// adminAuthorized checks an `Authorization` header value against the admin token.
+func (svc *Service) adminAuthorized(authz string) bool {
+	if len(svc.Config.AdminToken) == 0 {
+		return false
+	}
+	var supplied string
+	if token, ok := strings.CutPrefix(authz, "Bearer "); ok {
+		supplied = strings.TrimSpace(token)
+	} else if basic, ok := strings.CutPrefix(authz, "Basic "); ok {
+		decoded, err := base64.StdEncoding.DecodeString(strings.TrimSpace(basic))
+		if err != nil {
+			return false
+		}
+		_, password, ok := strings.Cut(string(decoded), ":")
+		if !ok {
+			return false
+		}
+		supplied = password
+	} else {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(supplied), []byte(svc.Config.AdminToken)) == 1
+}
+
+func isBearer(authz string) bool {
+	return strings.HasPrefix(authz, "Bearer ")
+}
+
+// replayToken returns the replay token for an event, an HMAC of its ID
+// keyed by the admin token, so it cannot be built by another site.
+func (svc *Service) replayToken(eventID string) string {
+	mac := hmac.New(sha256.New, []byte(svc.Config.AdminToken))
+	mac.Write([]byte(PathAdminReplay + "?" + QueryParamEventID + "=" + eventID))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// replayTokenValid checks the replay token posted with a replay request.
+func (svc *Service) replayTokenValid(aReq anyhttp.Request, eventID string) bool {
+	if err := aReq.ParseForm(); err != nil {
+		return false
+	}
+	supplied := aReq.PostArgs().GetString(FormParamReplayToken)
+	return len(supplied) > 0 &&
+		hmac.Equal([]byte(supplied), []byte(svc.replayToken(eventID)))
+}
+
+func writeAdminBody(aRes anyhttp.Response, contentType string, body []byte) {
+	aRes.SetContentType(contentType)
+	if _, err := aRes.SetBodyBytes(body); err != nil {
+		aRes.SetStatusCode(http.StatusInternalServerError)
+		return
+	}
+	aRes.SetStatusCode(http.StatusOK)
+}
@@ -0,0 +1,49 @@
+package service
+
+import (
+	"encoding/base64"
+	"net/http"
+	"testing"
+
+	"github.com/valyala/fasthttp"
+
+	"github.com/grokify/chathooks/pkg/models"
+)
+
+// AdminReplayTests are replay requests for event `1`. Requests with Basic
+// auth need the replay token, as browsers send Basic credentials on
+// cross-site requests.
+var AdminReplayTests = []struct {
+	authz       string
+	replayToken bool
+	wantStatus  int
+}{
+	{"Basic " + base64.StdEncoding.EncodeToString([]byte("admin:admin")), false, http.StatusForbidden},
+	{"Basic " + base64.StdEncoding.EncodeToString([]byte("admin:admin")), true, http.StatusSeeOther},
+	{"Bearer admin", false, http.StatusSeeOther},
+	{"Bearer wrong", false, http.StatusUnauthorized}}
+
+func TestHandleAdminReplay(t *testing.T) {
+	cfg := goldenConfig()
+	cfg.AdminToken = "admin"
+	svc, err := NewServiceConfig(cfg)
+	if err != nil {
+		t.Fatalf("NewServiceConfig(): error [%v]", err)
+	}
+	svc.History.Record(models.HookData{InputType: "travisci"}, models.Result{})
+	for _, tt := range AdminReplayTests {
+		ctx := &fasthttp.RequestCtx{}
+		ctx.Request.Header.SetMethod(http.MethodPost)
+		ctx.Request.SetRequestURI(PathAdminReplay + "?" + QueryParamEventID + "=1")
+		ctx.Request.Header.Set("Authorization", tt.authz)
+		ctx.Request.Header.SetContentType("application/x-www-form-urlencoded")
+		if tt.replayToken {
+			ctx.Request.SetBodyString(FormParamReplayToken + "=" + svc.replayToken("1"))
+		}
+		svc.HandleAdminFastHTTP(ctx)
+		if ctx.Response.StatusCode() != tt.wantStatus {
+			t.Errorf("Service.HandleAdminFastHTTP(%s, token %v): want status [%d], got [%d]",
+				tt.authz, tt.replayToken, tt.wantStatus, ctx.Response.StatusCode())
+		}
+	}
+}
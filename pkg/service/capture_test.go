@@ -0,0 +1,33 @@
+package service
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestCaptureNetHTTPForm checks form bodies are captured as sent, not as
+// re-encoded by `ParseForm`.
+func TestCaptureNetHTTPForm(t *testing.T) {
+	cfg := goldenConfig()
+	cfg.CaptureDir = t.TempDir()
+	svc, err := NewServiceConfig(cfg)
+	if err != nil {
+		t.Fatalf("NewServiceConfig(): error [%v]", err)
+	}
+	body := "user=jane%40example.com&app=secure-woodland-9775&git_log=+*+fix"
+	req := httptest.NewRequest(http.MethodPost, "/hook?inputType=heroku", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	svc.HandleHookNetHTTP(httptest.NewRecorder(), req)
+
+	files, err := filepath.Glob(filepath.Join(cfg.CaptureDir, "heroku", "event-example_*"))
+	if err != nil || len(files) != 1 {
+		t.Fatalf("Service.HandleHookNetHTTP(): want 1 captured body, got [%v] [%v]", files, err)
+	}
+	if try, err := os.ReadFile(files[0]); err != nil || string(try) != body {
+		t.Errorf("Service.HandleHookNetHTTP(): want captured body [%s], got [%s] [%v]", body, string(try), err)
+	}
+}
@@ -0,0 +1,41 @@
+package service
+
+import (
+	"io/fs"
+	"sort"
+	"testing"
+
+	"github.com/grokify/chathooks/docs"
+	"github.com/grokify/chathooks/pkg/handlers/handlerstest"
+)
+
+// conformanceOptions are the exceptions to the conformance checks for
+// built-in handlers.
+var conformanceOptions = map[string]handlerstest.Options{
+	"slack":   {EventIcons: true},
+	"wootric": {ErrorSlugs: []string{"decline-created"}}}
+
+func TestHandlersConformance(t *testing.T) {
+	svc, err := NewServiceConfig(goldenConfig())
+	if err != nil {
+		t.Fatalf("NewServiceConfig(): error [%v]", err)
+	}
+	keys := []string{}
+	for key := range svc.HandlerSet.Handlers {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		handler, _ := svc.Handler(key)
+		fixtures, err := fs.Sub(docs.Handlers(), key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := fs.Stat(fixtures, "."); err != nil {
+			continue // no example events
+		}
+		t.Run(key, func(t *testing.T) {
+			handlerstest.Run(t, handler, fixtures, conformanceOptions[key])
+		})
+	}
+}
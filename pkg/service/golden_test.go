@@ -0,0 +1,119 @@
+package service
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/grokify/chathooks/pkg/adapters"
+	"github.com/grokify/chathooks/pkg/config"
+	"github.com/grokify/chathooks/pkg/models"
+	"github.com/grokify/chathooks/pkg/util"
+)
+
+// Run `go test ./pkg/service -run TestHandlersGolden -update` to
+// regenerate the golden files after an intended output change.
+var update = flag.Bool("update", false, "update golden files in testdata/golden")
+
+const goldenDir = "testdata/golden"
+
+// goldenOutput is the checked-in output for one example event.
+type goldenOutput struct {
+	Error   string `json:"error,omitempty"`
+	Message any    `json:"message,omitempty"`
+	Glip    any    `json:"glip,omitempty"`
+	Slack   any    `json:"slack,omitempty"`
+}
+
+func goldenConfig() config.Configuration {
+	return config.Configuration{
+		HomeURL:        "https://chathooks.example.com",
+		EmojiURLFormat: config.EmojiURLFormat}
+}
+
+// TestHandlersGolden runs every registered handler over each of its
+// `docs/handlers/<key>/event-example_*` files and compares the message
+// and the Glip and Slack payloads with `testdata/golden/<key>/<slug>.json`.
+func TestHandlersGolden(t *testing.T) {
+	// Some handlers format times in the local time zone.
+	local := time.Local
+	time.Local = time.UTC
+	defer func() { time.Local = local }()
+
+	svc, err := NewServiceConfig(goldenConfig())
+	if err != nil {
+		t.Fatalf("NewServiceConfig(): error [%v]", err)
+	}
+	exampleData, err := util.NewExampleData()
+	if err != nil {
+		t.Fatalf("util.NewExampleData(): error [%v]", err)
+	}
+	keys := []string{}
+	for key := range svc.HandlerSet.Handlers {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		handler, ok := svc.Handler(key)
+		if !ok {
+			t.Errorf("Service.Handler(%s): not a handlers.Handler", key)
+			continue
+		}
+		for _, slug := range exampleData.EventSlugs(key) {
+			t.Run(key+"/"+slug, func(t *testing.T) {
+				body, err := exampleData.ExampleMessageBytes(key, slug)
+				if err != nil {
+					t.Fatal(err)
+				}
+				contentType := "application/x-www-form-urlencoded"
+				if json.Valid(body) {
+					contentType = "application/json"
+				}
+				got := goldenOutput{}
+				ccMsg, err := handler.NormalizeHookData(models.HookData{
+					InputType: key,
+					InputBody: models.BodyToMessageBytes(handler.MessageBodyType, contentType, body)})
+				if err != nil {
+					got.Error = err.Error()
+				} else {
+					got.Message = ccMsg
+					if got.Glip, err = adapters.ConvertMessage(adapters.AdapterTypeGlip, ccMsg, nil); err != nil {
+						t.Fatal(err)
+					}
+					if got.Slack, err = adapters.ConvertMessage(adapters.AdapterTypeSlack, ccMsg, nil); err != nil {
+						t.Fatal(err)
+					}
+				}
+				gotBytes, err := json.MarshalIndent(got, "", "  ")
+				if err != nil {
+					t.Fatal(err)
+				}
+				gotBytes = append(gotBytes, '\n')
+
+				goldenFile := filepath.Join(goldenDir, key, slug+".json")
+				if *update {
+					if err := os.MkdirAll(filepath.Dir(goldenFile), 0755); err != nil {
+						t.Fatal(err)
+					}
+					if err := os.WriteFile(goldenFile, gotBytes, 0600); err != nil {
+						t.Fatal(err)
+					}
+					return
+				}
+				wantBytes, err := os.ReadFile(goldenFile)
+				if err != nil {
+					t.Fatalf("golden file [%s]: %v (run with -update to create it)", goldenFile, err)
+				}
+				if !bytes.Equal(gotBytes, wantBytes) {
+					t.Errorf("output differs from [%s] (run with -update if intended):\n%s", goldenFile, gotBytes)
+				}
+			})
+		}
+	}
+}
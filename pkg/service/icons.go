@@ -0,0 +1,64 @@
+package service
+
+import (
+	"io/fs"
+	"mime"
+	"net/http"
+	"path"
+	"strings"
+
+	"github.com/grokify/mogo/net/http/httputilmore"
+	"github.com/grokify/sogo/net/http/anyhttp"
+	"github.com/valyala/fasthttp"
+
+	"github.com/grokify/chathooks/docs"
+	"github.com/grokify/chathooks/pkg/config"
+	"github.com/grokify/chathooks/pkg/models"
+)
+
+const (
+	IconCacheControl = "public, max-age=86400"
+)
+
+var iconFS = docs.Icons()
+
+// HandleIconAnyRequest serves the handler icons embedded from `docs/icons`
+// at `/icons/{file}`.
+func HandleIconAnyRequest(aRes anyhttp.Response, aReq anyhttp.Request) {
+	method := string(aReq.Method())
+	if method != http.MethodGet && method != http.MethodHead {
+		aRes.SetStatusCode(http.StatusMethodNotAllowed)
+		return
+	}
+	file, ok := strings.CutPrefix(models.RequestPath(string(aReq.RequestURI())), config.IconPath)
+	if !ok || strings.Contains(file, "/") || !fs.ValidPath(file) {
+		aRes.SetStatusCode(http.StatusNotFound)
+		return
+	}
+	bytes, err := fs.ReadFile(iconFS, file)
+	if err != nil {
+		aRes.SetStatusCode(http.StatusNotFound)
+		return
+	}
+	contentType := mime.TypeByExtension(path.Ext(file))
+	if len(contentType) == 0 {
+		contentType = httputilmore.ContentTypeAppOctetStream
+	}
+	aRes.SetContentType(contentType)
+	aRes.SetHeader(httputilmore.HeaderCacheControl, IconCacheControl)
+	if method == http.MethodGet {
+		if _, err := aRes.SetBodyBytes(bytes); err != nil {
+			aRes.SetStatusCode(http.StatusInternalServerError)
+			return
+		}
+	}
+	aRes.SetStatusCode(http.StatusOK)
+}
+
+func HandleIconNetHTTP(res http.ResponseWriter, req *http.Request) {
+	HandleIconAnyRequest(anyhttp.NewResReqNetHTTP(res, req))
+}
+
+func HandleIconFastHTTP(ctx *fasthttp.RequestCtx) {
+	HandleIconAnyRequest(anyhttp.NewResReqFastHTTP(ctx))
+}
@@ -0,0 +1,221 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"mime"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/aws/aws-lambda-go/events"
+	hum "github.com/grokify/mogo/net/http/httputilmore"
+
+	"github.com/grokify/chathooks/pkg/models"
+	"github.com/grokify/chathooks/pkg/queue"
+)
+
+// Lambda event types accepted by `HandleLambdaEvent`.
+const (
+	LambdaEventAPIGatewayV1 = "apigateway-v1" // API Gateway REST API
+	LambdaEventAPIGatewayV2 = "apigateway-v2" // API Gateway HTTP API, payload format 2.0
+	LambdaEventFunctionURL  = "function-url"  // Lambda Function URL
+	LambdaEventSQS          = "sqs"           // SQS batch of queued webhooks
+)
+
+// ErrUnknownLambdaEvent is returned for Lambda events that are not HTTP
+// requests from API Gateway or a Function URL, or SQS batches.
+var ErrUnknownLambdaEvent = errors.New("unknown Lambda event")
+
+// lambdaEventProbe holds the fields used to tell Lambda event types apart.
+type lambdaEventProbe struct {
+	Version        string `json:"version"`
+	HTTPMethod     string `json:"httpMethod"`
+	RequestContext struct {
+		DomainName string `json:"domainName"`
+		HTTP       struct {
+			Method string `json:"method"`
+		} `json:"http"`
+	} `json:"requestContext"`
+	Records []struct {
+		EventSource string `json:"eventSource"`
+	} `json:"Records"`
+}
+
+// LambdaEventType returns the type of a raw Lambda event.
+func LambdaEventType(raw []byte) (string, error) {
+	probe := lambdaEventProbe{}
+	if err := json.Unmarshal(raw, &probe); err != nil {
+		return "", err
+	}
+	switch {
+	case len(probe.HTTPMethod) > 0:
+		return LambdaEventAPIGatewayV1, nil
+	case probe.Version == "2.0" && len(probe.RequestContext.HTTP.Method) > 0:
+		// Function URLs are served at `<url-id>.lambda-url.<region>.on.aws`.
+		if strings.Contains(probe.RequestContext.DomainName, ".lambda-url.") {
+			return LambdaEventFunctionURL, nil
+		}
+		return LambdaEventAPIGatewayV2, nil
+	case len(probe.Records) > 0 && probe.Records[0].EventSource == queue.EventSourceSQS:
+		return LambdaEventSQS, nil
+	default:
+		return "", ErrUnknownLambdaEvent
+	}
+}
+
+// HandleLambdaEvent is the native AWS Lambda entrypoint. It accepts API
+// Gateway REST API, HTTP API and Lambda Function URL events and returns
+// the matching response type. Webhooks are handled by `HandleAwsLambda`
+// and other requests, such as the home page and icons, by the `net/http`
+// router. SQS events are handled by `HandleSQSEvent`, so the same
+// function can be deployed as the webhook function and the queue
+// consumer.
+func (svc *Service) HandleLambdaEvent(ctx context.Context, raw json.RawMessage) (any, error) {
+	eventType, err := LambdaEventType(raw)
+	if err != nil {
+		return nil, err
+	}
+	switch eventType {
+	case LambdaEventAPIGatewayV2:
+		req := events.APIGatewayV2HTTPRequest{}
+		if err := json.Unmarshal(raw, &req); err != nil {
+			return nil, err
+		}
+		res, err := svc.handleLambdaRequest(ctx, models.APIGatewayProxyRequestFromV2(req))
+		return models.APIGatewayV2HTTPResponseFromV1(res), err
+	case LambdaEventFunctionURL:
+		req := events.LambdaFunctionURLRequest{}
+		if err := json.Unmarshal(raw, &req); err != nil {
+			return nil, err
+		}
+		res, err := svc.handleLambdaRequest(ctx, models.APIGatewayProxyRequestFromFunctionURL(req))
+		return models.FunctionURLResponseFromV1(res), err
+	case LambdaEventSQS:
+		event := events.SQSEvent{}
+		if err := json.Unmarshal(raw, &event); err != nil {
+			return nil, err
+		}
+		return svc.HandleSQSEvent(ctx, event)
+	default:
+		req := events.APIGatewayProxyRequest{}
+		if err := json.Unmarshal(raw, &req); err != nil {
+			return nil, err
+		}
+		return svc.handleLambdaRequest(ctx, req)
+	}
+}
+
+// handleLambdaRequest sends webhooks, which are `POST` requests to any
+// path but the example and admin pages, to `HandleAwsLambda`.
+func (svc *Service) handleLambdaRequest(ctx context.Context, req events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	if req.HTTPMethod == http.MethodPost &&
+		req.Path != PathExample && req.Path != PathAdminReplay {
+		return svc.HandleAwsLambda(ctx, req)
+	}
+	return svc.serveLambdaHTTP(ctx, req)
+}
+
+// serveLambdaHTTP serves a Lambda request with the `net/http` router.
+func (svc *Service) serveLambdaHTTP(ctx context.Context, req events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	body := []byte(req.Body)
+	if req.IsBase64Encoded {
+		decoded, err := base64.StdEncoding.DecodeString(req.Body)
+		if err != nil {
+			return models.BuildAwsAPIGatewayProxyErrorResponse(
+				svc.Config.ResponseMode, models.ErrorTypeDecode, http.StatusBadRequest, err.Error())
+		}
+		body = decoded
+	}
+	query := url.Values(req.MultiValueQueryStringParameters)
+	if len(query) == 0 {
+		query = url.Values{}
+		for k, v := range req.QueryStringParameters {
+			query.Set(k, v)
+		}
+	}
+	u := url.URL{Path: req.Path, RawQuery: query.Encode()}
+	if len(u.Path) == 0 {
+		u.Path = "/"
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, req.HTTPMethod, u.String(), bytes.NewReader(body))
+	if err != nil {
+		return events.APIGatewayProxyResponse{}, err
+	}
+	for k, v := range req.Headers {
+		httpReq.Header.Set(k, v)
+	}
+	// Set as for server requests, which the handlers use for routing.
+	httpReq.RequestURI = u.RequestURI()
+	httpReq.RemoteAddr = req.RequestContext.Identity.SourceIP
+
+	res := newLambdaResponseWriter()
+	getHTTPServeMux(*svc).ServeHTTP(res, httpReq)
+	return res.response(), nil
+}
+
+// lambdaResponseWriter is an `http.ResponseWriter` that builds a Lambda
+// response.
+type lambdaResponseWriter struct {
+	header     http.Header
+	statusCode int
+	body       bytes.Buffer
+}
+
+func newLambdaResponseWriter() *lambdaResponseWriter {
+	return &lambdaResponseWriter{header: http.Header{}}
+}
+
+func (w *lambdaResponseWriter) Header() http.Header { return w.header }
+
+func (w *lambdaResponseWriter) WriteHeader(statusCode int) {
+	if w.statusCode == 0 {
+		w.statusCode = statusCode
+	}
+}
+
+func (w *lambdaResponseWriter) Write(p []byte) (int, error) {
+	w.WriteHeader(http.StatusOK)
+	return w.body.Write(p)
+}
+
+// response returns the Lambda response. Bodies that are not text, such
+// as icons, are base64 encoded.
+func (w *lambdaResponseWriter) response() events.APIGatewayProxyResponse {
+	res := events.APIGatewayProxyResponse{
+		StatusCode: w.statusCode,
+		Headers:    map[string]string{}}
+	if res.StatusCode == 0 {
+		res.StatusCode = http.StatusOK
+	}
+	for k, v := range w.header {
+		res.Headers[k] = strings.Join(v, ", ")
+	}
+	if isTextContentType(w.header.Get(hum.HeaderContentType)) {
+		res.Body = w.body.String()
+	} else {
+		res.Body = base64.StdEncoding.EncodeToString(w.body.Bytes())
+		res.IsBase64Encoded = true
+	}
+	return res
+}
+
+func isTextContentType(contentType string) bool {
+	if len(contentType) == 0 {
+		return true
+	}
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	return strings.HasPrefix(mediaType, "text/") ||
+		mediaType == hum.ContentTypeAppJSON ||
+		mediaType == hum.ContentTypeAppFormURLEncoded ||
+		strings.HasSuffix(mediaType, "+json") ||
+		strings.HasSuffix(mediaType, "+xml") ||
+		strings.HasSuffix(mediaType, "/xml") ||
+		strings.HasSuffix(mediaType, "/javascript")
+}
@@ -0,0 +1,97 @@
+package service
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+// LambdaEventTests are recorded API Gateway and Function URL events in
+// `testdata/lambda`. `wantActivity` is the activity of the normalized
+// message for webhook events.
+var LambdaEventTests = []struct {
+	file         string
+	wantType     string
+	wantStatus   int
+	wantActivity string
+	wantBase64   bool
+}{
+	{"apigateway-v1_json.json", LambdaEventAPIGatewayV1, 200, "Build passed", false},
+	{"apigateway-v1_form-base64.json", LambdaEventAPIGatewayV1, 200, "updown.io", false},
+	{"apigateway-v2_form-base64.json", LambdaEventAPIGatewayV2, 200, "secure-woodland-9775 deployed on Heroku", false},
+	{"apigateway-v2_json.json", LambdaEventAPIGatewayV2, 200, "Build success", false},
+	{"apigateway-v2_no-input-type.json", LambdaEventAPIGatewayV2, 400, "", false},
+	{"function-url_form.json", LambdaEventFunctionURL, 200, "updown.io", false},
+	{"function-url_json-base64.json", LambdaEventFunctionURL, 200, "Build passed", false},
+	{"function-url_icon.json", LambdaEventFunctionURL, 200, "", true}}
+
+func TestHandleLambdaEvent(t *testing.T) {
+	cfg := goldenConfig()
+	cfg.AdminToken = "admin" // records events in the history.
+	for _, tt := range LambdaEventTests {
+		svc, err := NewServiceConfig(cfg)
+		if err != nil {
+			t.Fatalf("NewServiceConfig(): error [%v]", err)
+		}
+		raw, err := os.ReadFile(filepath.Join("testdata", "lambda", tt.file))
+		if err != nil {
+			t.Fatal(err)
+		}
+		eventType, err := LambdaEventType(raw)
+		if err != nil || eventType != tt.wantType {
+			t.Errorf("LambdaEventType(%s): want [%s], got [%s] error [%v]", tt.file, tt.wantType, eventType, err)
+			continue
+		}
+		res, err := svc.HandleLambdaEvent(context.Background(), raw)
+		if err != nil {
+			t.Errorf("Service.HandleLambdaEvent(%s): error [%v]", tt.file, err)
+			continue
+		}
+		var statusCode int
+		var isBase64 bool
+		switch eventType {
+		case LambdaEventAPIGatewayV1:
+			r, ok := res.(events.APIGatewayProxyResponse)
+			statusCode, isBase64 = r.StatusCode, r.IsBase64Encoded
+			if !ok {
+				t.Errorf("Service.HandleLambdaEvent(%s): want APIGatewayProxyResponse, got [%T]", tt.file, res)
+			}
+		case LambdaEventAPIGatewayV2:
+			r, ok := res.(events.APIGatewayV2HTTPResponse)
+			statusCode, isBase64 = r.StatusCode, r.IsBase64Encoded
+			if !ok {
+				t.Errorf("Service.HandleLambdaEvent(%s): want APIGatewayV2HTTPResponse, got [%T]", tt.file, res)
+			}
+		case LambdaEventFunctionURL:
+			r, ok := res.(events.LambdaFunctionURLResponse)
+			statusCode, isBase64 = r.StatusCode, r.IsBase64Encoded
+			if !ok {
+				t.Errorf("Service.HandleLambdaEvent(%s): want LambdaFunctionURLResponse, got [%T]", tt.file, res)
+			}
+		}
+		if statusCode != tt.wantStatus {
+			t.Errorf("Service.HandleLambdaEvent(%s): want status [%d], got [%d]", tt.file, tt.wantStatus, statusCode)
+		}
+		if isBase64 != tt.wantBase64 {
+			t.Errorf("Service.HandleLambdaEvent(%s): want base64 [%v], got [%v]", tt.file, tt.wantBase64, isBase64)
+		}
+		if len(tt.wantActivity) == 0 {
+			continue
+		}
+		evt, ok := svc.History.Event("1")
+		if !ok {
+			t.Errorf("Service.HandleLambdaEvent(%s): event not recorded", tt.file)
+		} else if evt.HookData.CanonicalMessage.Activity != tt.wantActivity {
+			t.Errorf("Service.HandleLambdaEvent(%s): want activity [%s], got [%s]", tt.file, tt.wantActivity, evt.HookData.CanonicalMessage.Activity)
+		}
+	}
+}
+
+func TestLambdaEventTypeUnknown(t *testing.T) {
+	if _, err := LambdaEventType([]byte(`{"Records":[{"eventSource":"aws:s3"}]}`)); err != ErrUnknownLambdaEvent {
+		t.Errorf("LambdaEventType(S3): want [%v], got [%v]", ErrUnknownLambdaEvent, err)
+	}
+}
@@ -0,0 +1,129 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/rs/zerolog/log"
+
+	"github.com/grokify/chathooks/pkg/models"
+	"github.com/grokify/chathooks/pkg/queue"
+)
+
+// enqueue validates a webhook request and adds it to the queue for
+// asynchronous delivery. Requests that fail validation are rejected with
+// the same errors as synchronous requests.
+func (svc *Service) enqueue(ctx context.Context, inputType string, hookData models.HookData) models.Result {
+	handler, ok := svc.Handler(inputType)
+	if !ok {
+		return models.NewErrorResult(models.ErrorTypeDecode, http.StatusBadRequest,
+			fmt.Sprintf("input handler not found for [%s]", inputType))
+	}
+	hookData.InputType = inputType
+	if result := handler.Validate(hookData); len(result.Errors) > 0 {
+		return result
+	}
+	id, err := svc.Queue.Enqueue(ctx, hookData)
+	if err != nil {
+		log.Error().
+			Err(err).
+			Str("handler_input_type", inputType).
+			Msg("E_QUEUE_ENQUEUE")
+		return models.NewErrorResult(models.ErrorTypeQueue, http.StatusServiceUnavailable, err.Error())
+	}
+	log.Info().
+		Str("handler_input_type", inputType).
+		Str("message_id", id).
+		Msg("QUEUE_ENQUEUED")
+	return models.Result{MessageID: id}
+}
+
+// HandleSQSEvent is the AWS Lambda entrypoint for queued webhooks. Each
+// record holds the `models.HookData` enqueued by the webhook function,
+// which is normalized and delivered. When some outputs fail, a new
+// message for only the failed outputs is queued, so the outputs that
+// succeeded are not sent the message again. Records that cannot be read
+// or normalized, that fail for every output or that cannot be queued
+// again are reported as batch item failures so SQS retries them and, once
+// its `maxReceiveCount` is reached, moves them to the dead-letter queue.
+// The event source mapping must enable `ReportBatchItemFailures`.
+func (svc *Service) HandleSQSEvent(ctx context.Context, event events.SQSEvent) (events.SQSEventResponse, error) {
+	res := events.SQSEventResponse{}
+	for _, msg := range event.Records {
+		if err := svc.handleSQSMessage(ctx, msg); err != nil {
+			log.Warn().
+				Err(err).
+				Str("message_id", msg.MessageId).
+				Msg("E_QUEUE_MESSAGE_FAILED")
+			res.BatchItemFailures = append(res.BatchItemFailures,
+				events.SQSBatchItemFailure{ItemIdentifier: msg.MessageId})
+		}
+	}
+	return res, nil
+}
+
+func (svc *Service) handleSQSMessage(ctx context.Context, msg events.SQSMessage) error {
+	hookData, err := queue.Unmarshal([]byte(msg.Body))
+	if err != nil {
+		return err
+	}
+	handler, err := svc.inputHandler(hookData.InputType)
+	if err != nil {
+		return err
+	}
+	result := handler.HandleCanonical(hookData)
+	if len(result.Errors) > 0 {
+		return fmt.Errorf("%s: %s", result.Errors[0].Type, result.Errors[0].Message)
+	}
+	failed := result.Failed()
+	if len(failed) == 0 {
+		log.Info().
+			Str("message_id", msg.MessageId).
+			Str("handler_input_type", hookData.InputType).
+			Msg("QUEUE_MESSAGE_DELIVERED")
+		return nil
+	}
+	err = fmt.Errorf("%d of %d deliveries failed", len(failed), len(result.Deliveries))
+	if svc.Queue == nil || len(failed) == len(result.Deliveries) {
+		return err
+	}
+	id, qerr := svc.Queue.Enqueue(ctx, svc.retryHookData(hookData, failed))
+	if qerr != nil {
+		return fmt.Errorf("%w: requeue failed: %w", err, qerr)
+	}
+	log.Info().
+		Str("message_id", msg.MessageId).
+		Str("retry_message_id", id).
+		Int("failed", len(failed)).
+		Str("handler_input_type", hookData.InputType).
+		Msg("QUEUE_MESSAGE_REQUEUED")
+	return nil
+}
+
+// retryHookData returns the hook data to retry failed deliveries, with
+// only the failed outputs. The route is applied and removed so it does
+// not add the outputs that succeeded back.
+func (svc *Service) retryHookData(hookData models.HookData, failed []models.DeliveryResult) models.HookData {
+	if route, ok := svc.Config.Route(hookData.RouteName); ok {
+		hookData.ApplyRoute(route)
+	}
+	hookData.RouteName = ""
+	names := map[string]bool{}
+	for _, del := range failed {
+		names[del.Adapter] = true
+	}
+	if !names[hookData.OutputType] {
+		hookData.OutputType = ""
+		hookData.OutputURL = ""
+	}
+	outputNames := []string{}
+	for _, name := range hookData.OutputNames {
+		if names[name] {
+			outputNames = append(outputNames, name)
+		}
+	}
+	hookData.OutputNames = outputNames
+	return hookData
+}
@@ -0,0 +1,202 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+
+	"github.com/grokify/chathooks/pkg/config"
+	"github.com/grokify/chathooks/pkg/models"
+	"github.com/grokify/chathooks/pkg/queue"
+)
+
+// QueueEnqueueTests are recorded webhook events handled in asynchronous
+// mode. Valid requests are queued with `202`.
+var QueueEnqueueTests = []struct {
+	file       string
+	wantStatus int
+	wantQueued int
+}{
+	{"apigateway-v1_json.json", http.StatusAccepted, 1},
+	{"apigateway-v2_form-base64.json", http.StatusAccepted, 1},
+	{"apigateway-v2_no-input-type.json", http.StatusBadRequest, 0}}
+
+func TestQueueEnqueue(t *testing.T) {
+	for _, tt := range QueueEnqueueTests {
+		svc, err := NewServiceConfig(goldenConfig())
+		if err != nil {
+			t.Fatalf("NewServiceConfig(): error [%v]", err)
+		}
+		mem := queue.NewMemory()
+		svc.Queue = mem
+		raw, err := os.ReadFile(filepath.Join("testdata", "lambda", tt.file))
+		if err != nil {
+			t.Fatal(err)
+		}
+		res, err := svc.HandleLambdaEvent(context.Background(), raw)
+		if err != nil {
+			t.Errorf("Service.HandleLambdaEvent(%s): error [%v]", tt.file, err)
+			continue
+		}
+		statusCode := 0
+		switch r := res.(type) {
+		case events.APIGatewayProxyResponse:
+			statusCode = r.StatusCode
+		case events.APIGatewayV2HTTPResponse:
+			statusCode = r.StatusCode
+		}
+		if statusCode != tt.wantStatus {
+			t.Errorf("Service.HandleLambdaEvent(%s): want status [%d], got [%d]", tt.file, tt.wantStatus, statusCode)
+		}
+		if mem.Len() != tt.wantQueued {
+			t.Errorf("Service.HandleLambdaEvent(%s): want queued [%d], got [%d]", tt.file, tt.wantQueued, mem.Len())
+		}
+	}
+}
+
+func TestQueueEnqueueInvalid(t *testing.T) {
+	svc, err := NewServiceConfig(goldenConfig())
+	if err != nil {
+		t.Fatalf("NewServiceConfig(): error [%v]", err)
+	}
+	mem := queue.NewMemory()
+	svc.Queue = mem
+	result := svc.enqueue(context.Background(), "travisci", models.HookData{InputBody: []byte("{")})
+	if result.StatusCode() != http.StatusUnprocessableEntity || mem.Len() != 0 {
+		t.Errorf("Service.enqueue(invalid): want [%d] and none queued, got [%d] and [%d] queued",
+			http.StatusUnprocessableEntity, result.StatusCode(), mem.Len())
+	}
+}
+
+// TestHandleSQSEventRecorded handles a recorded SQS batch in which the
+// second record has an unknown input type.
+func TestHandleSQSEventRecorded(t *testing.T) {
+	svc, err := NewServiceConfig(goldenConfig())
+	if err != nil {
+		t.Fatalf("NewServiceConfig(): error [%v]", err)
+	}
+	raw, err := os.ReadFile(filepath.Join("testdata", "lambda", "sqs_batch.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if eventType, err := LambdaEventType(raw); err != nil || eventType != LambdaEventSQS {
+		t.Fatalf("LambdaEventType(sqs_batch.json): want [%s], got [%s] error [%v]", LambdaEventSQS, eventType, err)
+	}
+	res, err := svc.HandleLambdaEvent(context.Background(), raw)
+	if err != nil {
+		t.Fatalf("Service.HandleLambdaEvent(sqs_batch.json): error [%v]", err)
+	}
+	sqsRes, ok := res.(events.SQSEventResponse)
+	want := "2e1424d4-f796-459a-8184-9c92662be6da"
+	if !ok || len(sqsRes.BatchItemFailures) != 1 || sqsRes.BatchItemFailures[0].ItemIdentifier != want {
+		t.Errorf("Service.HandleLambdaEvent(sqs_batch.json): want failures [%s], got [%v]", want, res)
+	}
+}
+
+// TestQueueMemoryProcess enqueues webhooks for an output that succeeds
+// and one that fails, and checks only the failed message is retried.
+func TestQueueMemoryProcess(t *testing.T) {
+	received := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received++
+		if r.URL.Path == "/fail" {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer srv.Close()
+
+	svc, err := NewServiceConfig(goldenConfig())
+	if err != nil {
+		t.Fatalf("NewServiceConfig(): error [%v]", err)
+	}
+	mem := queue.NewMemory()
+	svc.Queue = mem
+	body, err := os.ReadFile(filepath.Join("..", "..", "docs", "handlers", "travisci", "event-example_build.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var failID string
+	for _, path := range []string{"/ok", "/fail"} {
+		result := svc.enqueue(context.Background(), "travisci", models.HookData{
+			InputBody:  body,
+			OutputType: "slack",
+			OutputURL:  srv.URL + path})
+		if result.StatusCode() != http.StatusAccepted {
+			t.Fatalf("Service.enqueue(%s): want [%d], got [%d]", path, http.StatusAccepted, result.StatusCode())
+		}
+		failID = result.MessageID
+	}
+
+	res, err := mem.Process(context.Background(), 10, svc.HandleSQSEvent)
+	if err != nil {
+		t.Fatalf("Memory.Process(): error [%v]", err)
+	}
+	if len(res.BatchItemFailures) != 1 || res.BatchItemFailures[0].ItemIdentifier != failID {
+		t.Errorf("Memory.Process(): want failures [%s], got [%v]", failID, res.BatchItemFailures)
+	}
+	if received != 2 {
+		t.Errorf("Memory.Process(): want [2] deliveries, got [%d]", received)
+	}
+	if mem.Len() != 1 {
+		t.Errorf("Memory.Process(): want [1] message requeued, got [%d]", mem.Len())
+	}
+}
+
+// TestQueuePartialFailure delivers to an output that succeeds and a named
+// adapter that fails, and checks only the failed adapter is retried.
+func TestQueuePartialFailure(t *testing.T) {
+	received := map[string]int{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received[r.URL.Path]++
+		if r.URL.Path == "/fail" {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer srv.Close()
+
+	cfg := goldenConfig()
+	cfg.Adapters = []config.AdapterConfig{{Name: "ops", Type: "slack", URL: srv.URL + "/fail"}}
+	svc, err := NewServiceConfig(cfg)
+	if err != nil {
+		t.Fatalf("NewServiceConfig(): error [%v]", err)
+	}
+	mem := queue.NewMemory()
+	svc.Queue = mem
+	body, err := os.ReadFile(filepath.Join("..", "..", "docs", "handlers", "travisci", "event-example_build.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	result := svc.enqueue(context.Background(), "travisci", models.HookData{
+		InputBody:   body,
+		OutputType:  "slack",
+		OutputURL:   srv.URL + "/ok",
+		OutputNames: []string{"ops"}})
+	if result.StatusCode() != http.StatusAccepted {
+		t.Fatalf("Service.enqueue(): want [%d], got [%d]", http.StatusAccepted, result.StatusCode())
+	}
+
+	res, err := mem.Process(context.Background(), 10, svc.HandleSQSEvent)
+	if err != nil {
+		t.Fatalf("Memory.Process(): error [%v]", err)
+	}
+	// The first message is requeued for `ops` only, which fails again.
+	if len(res.BatchItemFailures) != 1 || res.BatchItemFailures[0].ItemIdentifier != "2" {
+		t.Errorf("Memory.Process(): want failures [2], got [%v]", res.BatchItemFailures)
+	}
+	if received["/ok"] != 1 || received["/fail"] != 2 {
+		t.Errorf("Memory.Process(): want deliveries ok [1] fail [2], got [%v]", received)
+	}
+	retry := mem.Receive(1)
+	if len(retry.Records) != 1 {
+		t.Fatalf("Memory.Receive(): want [1] message, got [%d]", len(retry.Records))
+	}
+	hookData, err := queue.Unmarshal([]byte(retry.Records[0].Body))
+	if err != nil || len(hookData.OutputType) > 0 || len(hookData.OutputNames) != 1 || hookData.OutputNames[0] != "ops" {
+		t.Errorf("queue.Unmarshal(): want outputs [ops], got [%s] [%v] error [%v]", hookData.OutputType, hookData.OutputNames, err)
+	}
+}
@@ -0,0 +1,147 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"os"
+	"os/signal"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/buaazp/fasthttprouter"
+	"github.com/rs/zerolog/log"
+
+	"github.com/grokify/chathooks/pkg/config"
+)
+
+const (
+	ReloadPollInterval = 5 * time.Second
+)
+
+// Reloader serves a `Service` that is rebuilt from a fresh configuration
+// on `SIGHUP` or when the configuration file changes. Listeners and
+// in-flight requests are not interrupted: each request is dispatched to
+// the service current when it arrives. The port and engine cannot change
+// without a restart. It satisfies `httpsimple.SimpleServer`.
+type Reloader struct {
+	load    func() (config.Configuration, error)
+	current atomic.Pointer[Service]
+	modTime atomic.Int64 // configuration file modification time in Unix nanoseconds
+}
+
+// NewReloader loads the configuration with `load` and returns a `Reloader`
+// serving a service built from it.
+func NewReloader(load func() (config.Configuration, error)) (*Reloader, error) {
+	r := &Reloader{load: load}
+	cfg, err := load()
+	if err != nil {
+		return nil, err
+	}
+	if err := cfg.ConfigureLogger(); err != nil {
+		return nil, err
+	}
+	svc, err := newService(cfg, nil)
+	if err != nil {
+		return nil, err
+	}
+	r.current.Store(&svc)
+	r.modTime.Store(configModTime(cfg.ConfigFile))
+	return r, nil
+}
+
+// Service returns the current service.
+func (r *Reloader) Service() *Service { return r.current.Load() }
+
+// Reload rebuilds the service from a fresh configuration. On error, the
+// current service is kept.
+func (r *Reloader) Reload() error {
+	cfg, err := r.load()
+	if err != nil {
+		return err
+	}
+	old := r.Service()
+	if cfg.Port != old.Config.Port || cfg.Engine != old.Config.Engine {
+		log.Warn().
+			Int("port", old.Config.Port).
+			Str("engine", old.Config.Engine).
+			Msg("RELOAD_PORT_ENGINE_REQUIRE_RESTART")
+		cfg.Port = old.Config.Port
+		cfg.Engine = old.Config.Engine
+	}
+	svc, err := newService(cfg, old.History)
+	if err != nil {
+		return err
+	}
+	if err := cfg.ConfigureLogger(); err != nil {
+		return err
+	}
+	r.current.Store(&svc)
+	r.modTime.Store(configModTime(cfg.ConfigFile))
+	log.Info().
+		Str("config_file", cfg.ConfigFile).
+		Msg("CONFIGURATION_RELOADED")
+	return nil
+}
+
+// Watch reloads the service on `SIGHUP` and when the configuration file
+// modification time changes, until `ctx` is done.
+func (r *Reloader) Watch(ctx context.Context) {
+	sighup := make(chan os.Signal, 1)
+	signal.Notify(sighup, syscall.SIGHUP)
+	defer signal.Stop(sighup)
+	ticker := time.NewTicker(ReloadPollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-sighup:
+			r.reloadAndLog("sighup")
+		case <-ticker.C:
+			cfgFile := r.Service().Config.ConfigFile
+			if len(cfgFile) > 0 && configModTime(cfgFile) != r.modTime.Load() {
+				r.reloadAndLog("file")
+			}
+		}
+	}
+}
+
+func (r *Reloader) reloadAndLog(trigger string) {
+	if err := r.Reload(); err != nil {
+		log.Error().
+			Err(err).
+			Str("trigger", trigger).
+			Msg("E_CONFIGURATION_RELOAD_FAILED")
+		// Do not retry an unchanged, invalid file on every poll.
+		r.modTime.Store(configModTime(r.Service().Config.ConfigFile))
+	}
+}
+
+func (r *Reloader) PortInt() int       { return r.Service().Config.Port }
+func (r *Reloader) HTTPEngine() string { return r.Service().Config.Engine }
+
+func (r *Reloader) Router() http.Handler {
+	return newHTTPServeMux(r.Service)
+}
+
+func (r *Reloader) RouterFast() *fasthttprouter.Router {
+	return newRouterFast(r.Service)
+}
+
+// HandleLambdaEvent handles a Lambda event with the current service.
+func (r *Reloader) HandleLambdaEvent(ctx context.Context, raw json.RawMessage) (any, error) {
+	return r.Service().HandleLambdaEvent(ctx, raw)
+}
+
+func configModTime(filename string) int64 {
+	if len(filename) == 0 {
+		return 0
+	}
+	fi, err := os.Stat(filename)
+	if err != nil {
+		return 0
+	}
+	return fi.ModTime().UnixNano()
+}
@@ -1,19 +1,25 @@
 package service
 
 import (
+	"bytes"
 	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	clog "log"
 	"net/http"
+	"net/url"
+	"slices"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
 
-	"github.com/apex/gateway"
 	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambda"
 	"github.com/buaazp/fasthttprouter"
-	ccglip "github.com/grokify/commonchat/glip"
-	ccslack "github.com/grokify/commonchat/slack"
 	"github.com/grokify/mogo/net/http/httputilmore"
 	"github.com/grokify/sogo/net/http/anyhttp"
 	"github.com/rs/zerolog/log"
@@ -21,8 +27,11 @@ import (
 
 	"github.com/grokify/chathooks/pkg/adapters"
 	"github.com/grokify/chathooks/pkg/config"
+	"github.com/grokify/chathooks/pkg/history"
 	"github.com/grokify/chathooks/pkg/models"
+	"github.com/grokify/chathooks/pkg/queue"
 	"github.com/grokify/chathooks/pkg/templates"
+	"github.com/grokify/chathooks/pkg/util"
 
 	"github.com/grokify/chathooks/pkg/handlers"
 	"github.com/grokify/chathooks/pkg/handlers/aha"
@@ -64,12 +73,22 @@ tokens as a comma delimited string.
 
 // CHATHOOKS_URL=http://localhost:8080/hook CHATHOOKS_HOME_URL=http://localhost:8080 go run main.go
 
+const (
+	PathHook              = "/hook"
+	PathExample           = "/example"
+	PathAdmin             = "/admin"
+	PathAdminRaw          = "/admin/raw"
+	PathAdminReplay       = "/admin/replay"
+	QueryParamEventID     = "id"
+	QueryParamExampleSlug = "slug"
+)
+
 type HandlerSet struct {
 	Handlers map[string]Handler
 }
 
 type Handler interface {
-	HandleCanonical(hookData models.HookData) []models.ErrorInfo
+	HandleCanonical(hookData models.HookData) models.Result
 	HandleAwsLambda(ctx context.Context, req events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error)
 	HandleFastHTTP(ctx *fasthttp.RequestCtx)
 	HandleNetHTTP(res http.ResponseWriter, req *http.Request)
@@ -82,43 +101,71 @@ type Service struct {
 	HandlerSet   HandlerSet
 	RequireToken bool
 	Tokens       map[string]int
+	Capture      *util.CaptureWriter
+	History      *history.Store
+	Queue        queue.Queue // optional, queues webhooks for asynchronous delivery.
 }
 
 type HandlerFactory struct {
 	Config     config.Configuration
 	AdapterSet adapters.AdapterSet
+	Recorder   handlers.Recorder
 }
 
 func (hf *HandlerFactory) NewHandler(normalize handlers.Normalize) handlers.Handler {
 	return handlers.Handler{
 		Config:     hf.Config,
 		AdapterSet: hf.AdapterSet,
+		Recorder:   hf.Recorder,
 		Normalize:  normalize}
 }
 
 func (hf *HandlerFactory) InflateHandler(handler handlers.Handler) handlers.Handler {
 	handler.Config = hf.Config
 	handler.AdapterSet = hf.AdapterSet
+	handler.Recorder = hf.Recorder
 	return handler
 }
 
+// NewService returns a service configured with environment variables.
 func NewService() Service {
 	cfgData, err := config.NewConfigurationEnv()
 	if err != nil {
-		log.Fatal().Err(err)
+		log.Fatal().Err(err).Msg("E_CONFIGURATION")
+	}
+	svc, err := NewServiceConfig(cfgData)
+	if err != nil {
+		log.Fatal().Err(err).Msg("E_SERVICE")
 	}
+	return svc
+}
 
-	adapterSet := adapters.NewAdapterSet()
+// NewServiceConfig returns a service for the supplied configuration.
+func NewServiceConfig(cfgData config.Configuration) (Service, error) {
+	return newService(cfgData, nil)
+}
 
-	adapterSet.Adapters["glip"] = ccglip.NewGlipAdapter("", adapters.GlipConfig())
-	slackAdapter, err := ccslack.NewSlackAdapter("")
+// newService returns a service for the supplied configuration. If the
+// admin page is enabled, `historyStore` is used to keep recent events
+// across reloads. A new store is created if it is nil.
+func newService(cfgData config.Configuration, historyStore *history.Store) (Service, error) {
+	adapterSet, err := adapters.NewAdapterSetConfig(cfgData)
 	if err != nil {
-		log.Fatal().Err(err)
+		return Service{}, err
 	}
-	adapterSet.Adapters["slack"] = slackAdapter
 
 	hf := HandlerFactory{Config: cfgData, AdapterSet: adapterSet}
 
+	// Recent events are only kept when the admin page is enabled.
+	if len(cfgData.AdminToken) > 0 {
+		if historyStore == nil {
+			historyStore = history.NewStore(cfgData.AdminHistory)
+		}
+		hf.Recorder = historyStore
+	} else {
+		historyStore = nil
+	}
+
 	handlerSet := HandlerSet{Handlers: map[string]Handler{
 		"aha":        hf.InflateHandler(aha.NewHandler()),
 		"appsignal":  hf.InflateHandler(appsignal.NewHandler()),
@@ -154,7 +201,8 @@ func NewService() Service {
 		AdapterSet:   adapterSet,
 		HandlerSet:   handlerSet,
 		RequireToken: false,
-		Tokens:       map[string]int{}}
+		Tokens:       map[string]int{},
+		History:      historyStore}
 
 	for _, token := range cfgData.Tokens {
 		token = strings.TrimSpace(token)
@@ -163,75 +211,235 @@ func NewService() Service {
 		}
 	}
 
-	return svcInfo
+	if queueURL := strings.TrimSpace(cfgData.QueueURL); len(queueURL) > 0 {
+		sqsQueue, err := queue.NewSQS(context.Background(), queueURL)
+		if err != nil {
+			return svcInfo, err
+		}
+		svcInfo.Queue = sqsQueue
+		log.Info().
+			Str("queue_url", queueURL).
+			Msg("ASYNC_MODE_ENABLED")
+	}
+
+	if len(strings.TrimSpace(cfgData.CaptureDir)) > 0 {
+		capture, err := util.NewCaptureWriter(cfgData.CaptureDir)
+		if err != nil {
+			return svcInfo, err
+		}
+		if cfgData.CaptureMax > 0 {
+			capture.MaxFiles = cfgData.CaptureMax
+		}
+		svcInfo.Capture = capture
+		log.Info().
+			Str("capture_dir", cfgData.CaptureDir).
+			Msg("CAPTURE_MODE_ENABLED")
+	}
+
+	return svcInfo, nil
+}
+
+// Handler returns the handler for an input type.
+func (svc *Service) Handler(inputType string) (handlers.Handler, bool) {
+	handler, ok := svc.HandlerSet.Handlers[inputType].(handlers.Handler)
+	return handler, ok
+}
+
+// inputType returns the `inputType` query string parameter, or the input
+// type of the request's route if the parameter is not present.
+func (svc *Service) inputType(inputType, routeName string) string {
+	if inputType = strings.TrimSpace(inputType); len(inputType) > 0 {
+		return inputType
+	}
+	if route, ok := svc.Config.Route(routeName); ok {
+		return route.InputType
+	}
+	return ""
 }
 
 func (svc *Service) HandleAwsLambda(ctx context.Context, req events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
 	log.Info().Msg("FUNC_HandleAwsLambda__BEGIN")
-	if len(svc.Tokens) > 0 {
-		token, ok := req.QueryStringParameters[config.ParamNameToken]
-		if !ok {
-			return events.APIGatewayProxyResponse{
-				StatusCode: http.StatusUnauthorized,
-				Body:       config.ErrRequiredTokenNotFound}, nil
-		}
-		if _, ok := svc.Tokens[token]; !ok {
-			return events.APIGatewayProxyResponse{
-				StatusCode: http.StatusUnauthorized,
-				Body:       config.ErrRequiredTokenNotValid}, nil
-		}
+	if err := svc.authorizeToken(req.QueryStringParameters[config.ParamNameToken]); err != nil {
+		return models.BuildAwsAPIGatewayProxyErrorResponse(
+			svc.Config.ResponseMode, models.ErrorTypeAuth, http.StatusUnauthorized, err.Error())
 	}
-	inputType, ok := req.QueryStringParameters[models.QueryParamInputType]
-	if !ok || len(strings.TrimSpace(inputType)) == 0 {
-		return events.APIGatewayProxyResponse{
-			StatusCode: 400,
-			Body:       "InputType not found"}, nil
+	inputType := svc.inputType(
+		req.QueryStringParameters[models.QueryParamInputType],
+		models.RouteName(req.QueryStringParameters[models.QueryParamRoute], req.Path))
+	handler, err := svc.inputHandler(inputType)
+	if err != nil {
+		return models.BuildAwsAPIGatewayProxyErrorResponse(
+			svc.Config.ResponseMode, models.ErrorTypeDecode, http.StatusBadRequest, err.Error())
 	}
 
-	handler, ok := svc.HandlerSet.Handlers[inputType]
-	if !ok {
-		return events.APIGatewayProxyResponse{
-			StatusCode: 400,
-			Body:       fmt.Sprintf("Input Handler Not found for: %v\n", inputType)}, nil
+	if svc.Capture != nil {
+		body := []byte(req.Body)
+		if req.IsBase64Encoded {
+			if decoded, err := base64.StdEncoding.DecodeString(req.Body); err == nil {
+				body = decoded
+			}
+		}
+		headers := http.Header{}
+		for k, v := range req.Headers {
+			headers.Add(k, v)
+		}
+		query := url.Values{}
+		for k, v := range req.QueryStringParameters {
+			query.Add(k, v)
+		}
+		svc.captureRequest(inputType, req.HTTPMethod, headers, query, body)
 	}
 
+	if svc.Queue != nil {
+		h, _ := svc.Handler(inputType)
+		hookData := models.HookDataFromAwsLambdaEvent(h.MessageBodyType, req)
+		return models.BuildAwsAPIGatewayProxyResponse(
+			svc.Config.ResponseMode, hookData, svc.enqueue(ctx, inputType, hookData))
+	}
 	return handler.HandleAwsLambda(ctx, req)
 }
 
 func (svc *Service) HandleAnyRequest(aRes anyhttp.Response, aReq anyhttp.Request) {
 	log.Info().Msg("FUNC_HandleAnyRequest__BEGIN")
 
+	var captureBody []byte
+	if svc.Capture != nil {
+		// Read before `ParseForm`, which consumes `net/http` form bodies.
+		captureBody = svc.readCaptureBody(aReq)
+	}
 	if err := aReq.ParseForm(); err != nil {
-		aRes.SetStatusCode(http.StatusInternalServerError)
-		log.Warn().Msg("E_CANNOT_PARSE_FORM")
+		log.Warn().Err(err).Msg("E_CANNOT_PARSE_FORM")
+		svc.writeError(aRes, models.ErrorTypeDecode, http.StatusBadRequest, err.Error())
 		return
 	}
 
-	if len(svc.Tokens) > 0 {
-		token := strings.TrimSpace(aReq.QueryArgs().GetString(config.ParamNameToken))
+	if err := svc.authorizeToken(aReq.QueryArgs().GetString(config.ParamNameToken)); err != nil {
+		svc.writeError(aRes, models.ErrorTypeAuth, http.StatusUnauthorized, err.Error())
+		return
+	}
 
-		if len(token) == 0 {
-			aRes.SetStatusCode(http.StatusUnauthorized)
-			log.Warn().Msg("E_NO_TOKEN")
-			return
+	inputType := svc.inputType(
+		aReq.QueryArgs().GetString(config.ParamNameInputType),
+		models.RouteName(aReq.QueryArgs().GetString(config.ParamNameRoute), string(aReq.RequestURI())))
+
+	handler, err := svc.inputHandler(inputType)
+	if err != nil {
+		log.Info().
+			Str("handler_input_type", inputType).
+			Msg("Input_Handler_Not_Found")
+		svc.writeError(aRes, models.ErrorTypeDecode, http.StatusBadRequest, err.Error())
+		return
+	}
+	log.Info().
+		Str("handler_input_type", inputType).
+		Msg("Input_Handler_Found_Processing")
+	if svc.Capture != nil {
+		svc.captureAnyRequest(inputType, aReq, captureBody)
+	}
+	if svc.Queue != nil {
+		h, _ := svc.Handler(inputType)
+		hookData := models.HookDataFromAnyHTTPReq(h.MessageBodyType, aReq)
+		result := svc.enqueue(context.Background(), inputType, hookData)
+		if err := models.WriteAnyHTTPResponse(aRes, svc.Config.ResponseMode, hookData, result); err != nil {
+			log.Warn().Err(err).Msg("E_WRITE_RESPONSE")
 		}
-		if _, ok := svc.Tokens[token]; !ok {
-			aRes.SetStatusCode(http.StatusUnauthorized)
-			log.Warn().Msg("E_INCORRECT_TOKEN")
-			return
+		return
+	}
+	handler.HandleAnyHTTP(aRes, aReq)
+}
+
+// inputHandler returns the handler for an input type.
+func (svc *Service) inputHandler(inputType string) (Handler, error) {
+	if len(inputType) == 0 {
+		return nil, errors.New("input type not found")
+	}
+	handler, ok := svc.HandlerSet.Handlers[inputType]
+	if !ok {
+		return nil, fmt.Errorf("input handler not found for [%s]", inputType)
+	}
+	return handler, nil
+}
+
+// authorizeToken checks the `token` query string parameter when
+// verification tokens are configured.
+func (svc *Service) authorizeToken(token string) error {
+	if len(svc.Tokens) == 0 {
+		return nil
+	}
+	token = strings.TrimSpace(token)
+	if len(token) == 0 {
+		log.Warn().Msg("E_NO_TOKEN")
+		return errors.New(config.ErrRequiredTokenNotFound)
+	}
+	if _, ok := svc.Tokens[token]; !ok {
+		log.Warn().Msg("E_INCORRECT_TOKEN")
+		return errors.New(config.ErrRequiredTokenNotValid)
+	}
+	return nil
+}
+
+func (svc *Service) writeError(aRes anyhttp.Response, errType models.ErrorType, statusCode int, msg string) {
+	if err := models.WriteAnyHTTPErrorResponse(aRes, svc.Config.ResponseMode, errType, statusCode, msg); err != nil {
+		log.Warn().Err(err).Msg("E_WRITE_RESPONSE")
+	}
+}
+
+// readCaptureBody returns the raw request body, up to one byte over the
+// capture limit. For `net/http`, the body is restored so it can still be
+// parsed and read by the handler.
+func (svc *Service) readCaptureBody(aReq anyhttp.Request) []byte {
+	switch req := aReq.(type) {
+	case *anyhttp.RequestFastHTTP:
+		return append([]byte{}, req.Raw.PostBody()...)
+	case *anyhttp.RequestNetHTTP:
+		if req.Raw.Body == nil {
+			return nil
+		}
+		body, err := io.ReadAll(io.LimitReader(req.Raw.Body, int64(svc.Capture.MaxBodyBytes)+1))
+		if err != nil {
+			log.Warn().Err(err).Msg("E_CAPTURE_READ_BODY")
 		}
+		req.Raw.Body = readCloser{io.MultiReader(bytes.NewReader(body), req.Raw.Body), req.Raw.Body}
+		return body
+	default:
+		body, _ := aReq.PostBody()
+		return body
 	}
+}
 
-	inputType := aReq.QueryArgs().GetString(config.ParamNameInputType)
+// readCloser reads from a reader and closes the original body.
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
 
-	if handler, ok := svc.HandlerSet.Handlers[inputType]; ok {
-		log.Info().
+// captureAnyRequest writes the raw request to the capture directory.
+func (svc *Service) captureAnyRequest(inputType string, aReq anyhttp.Request, body []byte) {
+	headers := http.Header{}
+	switch req := aReq.(type) {
+	case *anyhttp.RequestFastHTTP:
+		req.Raw.Request.Header.VisitAll(func(k, v []byte) {
+			headers.Add(string(k), string(v))
+		})
+	case *anyhttp.RequestNetHTTP:
+		headers = req.Raw.Header.Clone()
+	}
+	svc.captureRequest(inputType, string(aReq.Method()), headers, aReq.QueryArgs().GetURLValues(), body)
+}
+
+func (svc *Service) captureRequest(inputType, method string, headers http.Header, query url.Values, body []byte) {
+	filename, err := svc.Capture.Capture(inputType, method, headers, query, body)
+	if err != nil {
+		log.Warn().
+			Err(err).
 			Str("handler_input_type", inputType).
-			Msg("Input_Handler_Found_Processing")
-		handler.HandleAnyHTTP(aRes, aReq)
-	} else {
-		fmt.Printf("Input_Handler_Not_Found [%v]\n", inputType)
+			Msg("E_CAPTURE_FAILED")
+		return
 	}
+	log.Info().
+		Str("handler_input_type", inputType).
+		Str("capture_file", filename).
+		Msg("REQUEST_CAPTURED")
 }
 
 func (svc *Service) HandleHookNetHTTP(res http.ResponseWriter, req *http.Request) {
@@ -246,11 +454,7 @@ func (svc *Service) HandleHookFastHTTP(ctx *fasthttp.RequestCtx) {
 
 func (svc *Service) HandleHomeAnyRequest(aRes anyhttp.Response, aReq anyhttp.Request) {
 	log.Info().Msg("HANDLE_HOME_AnyHTTP")
-	fmt.Println(svc.Config.WebhookURL)
-	data := templates.HomeData{
-		HomeURL:    svc.Config.HomeURL,
-		WebhookURL: svc.Config.WebhookURL}
-	if _, err := aRes.SetBodyBytes([]byte(templates.HomePage(data))); err != nil {
+	if _, err := aRes.SetBodyBytes([]byte(templates.HomePage(svc.HomeData()))); err != nil {
 		aRes.SetStatusCode(http.StatusInternalServerError)
 	} else {
 		aRes.SetStatusCode(http.StatusOK)
@@ -258,6 +462,127 @@ func (svc *Service) HandleHomeAnyRequest(aRes anyhttp.Response, aReq anyhttp.Req
 	}
 }
 
+// HomeData returns the home page data, including the handlers, adapters and
+// example events available on this service for the webhook URL builder.
+func (svc *Service) HomeData() templates.HomeData {
+	data := templates.HomeData{
+		HomeURL:       svc.Config.HomeURL,
+		WebhookURL:    svc.Config.WebhookURL,
+		ExampleURL:    PathExample,
+		HandlerParams: map[string][]string{},
+		Examples:      map[string][]string{}}
+	if len(strings.TrimSpace(data.WebhookURL)) == 0 {
+		data.WebhookURL = strings.TrimRight(svc.Config.HomeURL, "/") + PathHook
+	}
+	for key, handler := range svc.HandlerSet.Handlers {
+		data.Handlers = append(data.Handlers, key)
+		if h, ok := handler.(handlers.Handler); ok && len(h.QueryParamNames) > 0 {
+			data.HandlerParams[key] = h.QueryParamNames
+		}
+	}
+	sort.Strings(data.Handlers)
+	data.OutputTypes = adapters.AdapterTypes()
+	for _, adapterCfg := range svc.Config.Adapters {
+		data.Adapters = append(data.Adapters, strings.TrimSpace(adapterCfg.Name))
+	}
+	sort.Strings(data.Adapters)
+	if exampleData, err := util.NewExampleData(); err == nil {
+		for key, src := range exampleData.Data {
+			if _, ok := svc.HandlerSet.Handlers[key]; ok && len(src.EventSlugs) > 0 {
+				data.Examples[key] = src.EventSlugs
+			}
+		}
+	}
+	return data
+}
+
+// HandleExampleAnyRequest serves the example event for the `inputType`
+// and `slug` query string parameters. A `GET` request returns the event so
+// it can be posted to a webhook URL. A `POST` request processes the event
+// as if it had been posted to the webhook URL with the same query string
+// parameters and returns the delivery results.
+func (svc *Service) HandleExampleAnyRequest(aRes anyhttp.Response, aReq anyhttp.Request) {
+	log.Info().Msg("HANDLE_EXAMPLE_AnyHTTP")
+	inputType := strings.TrimSpace(aReq.QueryArgs().GetString(config.ParamNameInputType))
+	slug := strings.TrimSpace(aReq.QueryArgs().GetString(QueryParamExampleSlug))
+
+	slugs, ok := svc.HomeData().Examples[inputType]
+	if !ok || !slices.Contains(slugs, slug) {
+		aRes.SetStatusCode(http.StatusNotFound)
+		return
+	}
+	exampleData, err := util.NewExampleData()
+	if err != nil {
+		aRes.SetStatusCode(http.StatusInternalServerError)
+		return
+	}
+	bytes, err := exampleData.ExampleMessageBytes(inputType, slug)
+	if err != nil {
+		log.Warn().
+			Err(err).
+			Str("handler_input_type", inputType).
+			Str("example_slug", slug).
+			Msg("E_EXAMPLE_NOT_READ")
+		aRes.SetStatusCode(http.StatusNotFound)
+		return
+	}
+	contentType := httputilmore.ContentTypeAppFormURLEncodedUtf8
+	if json.Valid(bytes) {
+		contentType = httputilmore.ContentTypeAppJSONUtf8
+	}
+
+	switch string(aReq.Method()) {
+	case http.MethodGet:
+		aRes.SetContentType(contentType)
+		if _, err := aRes.SetBodyBytes(bytes); err != nil {
+			aRes.SetStatusCode(http.StatusInternalServerError)
+		} else {
+			aRes.SetStatusCode(http.StatusOK)
+		}
+	case http.MethodPost:
+		if err := svc.authorizeToken(aReq.QueryArgs().GetString(config.ParamNameToken)); err != nil {
+			svc.writeError(aRes, models.ErrorTypeAuth, http.StatusUnauthorized, err.Error())
+			return
+		}
+		svc.postExample(aRes, aReq, inputType, slug, contentType, bytes)
+	default:
+		aRes.SetStatusCode(http.StatusMethodNotAllowed)
+	}
+}
+
+// postExample runs an example event through its handler and delivers it
+// to the outputs in the request query string parameters.
+func (svc *Service) postExample(aRes anyhttp.Response, aReq anyhttp.Request, inputType, slug, contentType string, body []byte) {
+	handler, ok := svc.HandlerSet.Handlers[inputType]
+	if !ok {
+		aRes.SetStatusCode(http.StatusNotFound)
+		return
+	}
+	bodyType := models.JSON
+	if h, ok := handler.(handlers.Handler); ok {
+		bodyType = h.MessageBodyType
+	}
+	hookData := models.HookDataFromAnyHTTPReq(bodyType, aReq)
+	hookData.InputType = inputType
+	hookData.InputBody = models.BodyToMessageBytes(bodyType, contentType, body)
+	log.Info().
+		Str("handler_input_type", inputType).
+		Str("example_slug", slug).
+		Msg("EXAMPLE_POST")
+	result := handler.HandleCanonical(hookData)
+	if err := models.WriteAnyHTTPResponse(aRes, svc.Config.ResponseMode, hookData, result); err != nil {
+		log.Warn().Err(err).Msg("E_WRITE_RESPONSE")
+	}
+}
+
+func (svc *Service) HandleExampleNetHTTP(res http.ResponseWriter, req *http.Request) {
+	svc.HandleExampleAnyRequest(anyhttp.NewResReqNetHTTP(res, req))
+}
+
+func (svc *Service) HandleExampleFastHTTP(ctx *fasthttp.RequestCtx) {
+	svc.HandleExampleAnyRequest(anyhttp.NewResReqFastHTTP(ctx))
+}
+
 func (svc *Service) HandleHomeNetHTTP(res http.ResponseWriter, req *http.Request) {
 	log.Debug().Msg("HANDLE_NetHTTP")
 	svc.HandleHomeAnyRequest(anyhttp.NewResReqNetHTTP(res, req))
@@ -281,22 +606,54 @@ func (svc Service) Router() http.Handler {
 }
 
 func (svc Service) RouterFast() *fasthttprouter.Router {
+	return newRouterFast(func() *Service { return &svc })
+}
+
+func getHTTPServeMux(svc Service) *http.ServeMux {
+	return newHTTPServeMux(func() *Service { return &svc })
+}
+
+// newRouterFast returns a fasthttp router that dispatches each request
+// to the service returned by `current`, so the service can be replaced
+// without restarting the listener.
+func newRouterFast(current func() *Service) *fasthttprouter.Router {
+	hook := func(ctx *fasthttp.RequestCtx) { current().HandleHookFastHTTP(ctx) }
+	admin := func(ctx *fasthttp.RequestCtx) { current().HandleAdminFastHTTP(ctx) }
 	router := fasthttprouter.New()
-	router.GET("/", svc.HandleHomeFastHTTP)
-	router.POST("/hook", svc.HandleHookFastHTTP)
-	router.POST("/hook/", svc.HandleHookFastHTTP)
-	router.POST("/webhook", svc.HandleHookFastHTTP)
-	router.POST("/webhook/", svc.HandleHookFastHTTP)
+	router.GET("/", func(ctx *fasthttp.RequestCtx) { current().HandleHomeFastHTTP(ctx) })
+	example := func(ctx *fasthttp.RequestCtx) { current().HandleExampleFastHTTP(ctx) }
+	router.GET(PathExample, example)
+	router.POST(PathExample, example)
+	router.GET(PathAdmin, admin)
+	router.GET(PathAdminRaw, admin)
+	router.POST(PathAdminReplay, admin)
+	router.GET(config.IconPath+":file", HandleIconFastHTTP)
+	router.HEAD(config.IconPath+":file", HandleIconFastHTTP)
+	router.POST("/hook", hook)
+	router.POST("/hook/", hook)
+	router.POST("/hook/:route", hook)
+	router.POST("/webhook", hook)
+	router.POST("/webhook/", hook)
+	router.POST("/webhook/:route", hook)
 	return router
 }
 
-func getHTTPServeMux(svc Service) *http.ServeMux {
+// newHTTPServeMux returns a `net/http` mux that dispatches each request
+// to the service returned by `current`.
+func newHTTPServeMux(current func() *Service) *http.ServeMux {
+	hook := func(res http.ResponseWriter, req *http.Request) { current().HandleHookNetHTTP(res, req) }
+	admin := func(res http.ResponseWriter, req *http.Request) { current().HandleAdminNetHTTP(res, req) }
 	mux := http.NewServeMux()
-	mux.HandleFunc("/", http.HandlerFunc(svc.HandleHomeNetHTTP))
-	mux.HandleFunc("/hook", http.HandlerFunc(svc.HandleHookNetHTTP))
-	mux.HandleFunc("/hook/", http.HandlerFunc(svc.HandleHookNetHTTP))
-	mux.HandleFunc("/webhook", http.HandlerFunc(svc.HandleHookNetHTTP))
-	mux.HandleFunc("/webhook/", http.HandlerFunc(svc.HandleHookNetHTTP))
+	mux.HandleFunc("/", func(res http.ResponseWriter, req *http.Request) { current().HandleHomeNetHTTP(res, req) })
+	mux.HandleFunc(PathExample, func(res http.ResponseWriter, req *http.Request) { current().HandleExampleNetHTTP(res, req) })
+	mux.HandleFunc(PathAdmin, admin)
+	mux.HandleFunc(PathAdminRaw, admin)
+	mux.HandleFunc(PathAdminReplay, admin)
+	mux.HandleFunc(config.IconPath, HandleIconNetHTTP)
+	mux.HandleFunc("/hook", hook)
+	mux.HandleFunc("/hook/", hook)
+	mux.HandleFunc("/webhook", hook)
+	mux.HandleFunc("/webhook/", hook)
 	return mux
 }
 
@@ -328,9 +685,9 @@ func ServeFastHTTP(svc Service) {
 	clog.Fatal(fasthttp.ListenAndServe(portAddress(svc.Config.Port), router.Handler))
 }
 
+// ServeAWSLambda starts the native Lambda entrypoint. See
+// `HandleLambdaEvent`.
 func ServeAWSLambda(svc Service) {
-	clog.Fatal(gateway.ListenAndServe(portAddress(svc.Config.Port), getHTTPServeMux(svc)))
+	lambda.Start(svc.HandleLambdaEvent)
 }
-
-// func serveAwsLambdaSimple(svc Service) { lambda.Start(svc.HandleAwsLambda) }
 func portAddress(port int) string { return ":" + strconv.Itoa(port) }
@@ -0,0 +1,25 @@
+package service
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/grokify/chathooks/pkg/adapters"
+	"github.com/grokify/chathooks/pkg/config"
+)
+
+func TestHomeDataAdapters(t *testing.T) {
+	cfg := goldenConfig()
+	cfg.Adapters = []config.AdapterConfig{{Name: "ops", Type: adapters.AdapterTypeSlack, URL: "https://hooks.slack.com/services/T0/B0/x"}}
+	svc, err := NewServiceConfig(cfg)
+	if err != nil {
+		t.Fatalf("NewServiceConfig(): error [%v]", err)
+	}
+	data := svc.HomeData()
+	if !slices.Equal(data.Adapters, []string{"ops"}) {
+		t.Errorf("Service.HomeData(): want adapters [ops], got [%v]", data.Adapters)
+	}
+	if !slices.Equal(data.OutputTypes, adapters.AdapterTypes()) {
+		t.Errorf("Service.HomeData(): want output types [%v], got [%v]", adapters.AdapterTypes(), data.OutputTypes)
+	}
+}
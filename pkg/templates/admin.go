@@ -0,0 +1,34 @@
+package templates
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/grokify/chathooks/pkg/history"
+)
+
+type AdminData struct {
+	HomeURL string
+	Events  []history.Event
+	// ReplayToken returns the token the replay form posts for an event.
+	ReplayToken func(eventID string) string
+}
+
+func adminTime(t time.Time) string {
+	return t.Format(time.RFC3339)
+}
+
+func adminMessageJSON(evt history.Event) string {
+	bytes, err := json.MarshalIndent(evt.HookData.CanonicalMessage, "", "  ")
+	if err != nil {
+		return err.Error()
+	}
+	return string(bytes)
+}
+
+func adminStatusClass(ok bool) string {
+	if ok {
+		return "ok"
+	}
+	return "fail"
+}
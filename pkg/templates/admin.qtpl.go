@@ -0,0 +1,279 @@
+// Code generated by qtc from "admin.qtpl". DO NOT EDIT.
+// See https://github.com/valyala/quicktemplate for details.
+
+//line admin.qtpl:1
+package templates
+
+//line admin.qtpl:1
+import (
+	qtio422016 "io"
+
+	qt422016 "github.com/valyala/quicktemplate"
+)
+
+//line admin.qtpl:1
+var (
+	_ = qtio422016.Copy
+	_ = qt422016.AcquireByteBuffer
+)
+
+//line admin.qtpl:1
+func StreamAdminPage(qw422016 *qt422016.Writer, data AdminData) {
+//line admin.qtpl:1
+	qw422016.N().S(`<!DOCTYPE html>
+<html lang="en">
+  <head>
+    <meta charset="utf-8">
+    <meta name="viewport" content="width=device-width, initial-scale=1.0">
+    <title>Chathooks Admin</title>
+  </head>
+  <style>
+    h1, h2, p, table, button {font-family: Arial, Helvetica, sans-serif;}
+    table {border-collapse:collapse;width:100%;}
+    th, td {border:1px solid #aaa;padding:0.3em;text-align:left;vertical-align:top;}
+    pre {font-family: monospace;background-color:#efefef;margin:0;max-height:20em;overflow:auto;}
+    .ok {color:#006000;}
+    .fail {color:#a00000;font-weight:bold;}
+    form {display:inline;}
+  </style>
+  <body>
+    <h1>Chathooks Admin</h1>
+    <p><a href="`)
+//line admin.qtpl:19
+	qw422016.E().S(data.HomeURL)
+//line admin.qtpl:19
+	qw422016.N().S(`">`)
+//line admin.qtpl:19
+	qw422016.E().S(data.HomeURL)
+//line admin.qtpl:19
+	qw422016.N().S(`</a></p>
+
+    <h2>Recent events</h2>
+    `)
+//line admin.qtpl:22
+	if len(data.Events) == 0 {
+//line admin.qtpl:22
+		qw422016.N().S(`
+    <p>No events have been received.</p>
+    `)
+//line admin.qtpl:24
+	} else {
+//line admin.qtpl:24
+		qw422016.N().S(`
+    <table>
+      <tr>
+        <th>ID</th>
+        <th>Time</th>
+        <th>Handler</th>
+        <th>Route</th>
+        <th>Status</th>
+        <th>Deliveries</th>
+        <th>Message</th>
+        <th>Actions</th>
+      </tr>
+      `)
+//line admin.qtpl:36
+		for _, evt := range data.Events {
+//line admin.qtpl:36
+			qw422016.N().S(`
+      <tr>
+        <td>`)
+//line admin.qtpl:38
+			qw422016.E().S(evt.ID)
+//line admin.qtpl:38
+			qw422016.N().S(`</td>
+        <td>`)
+//line admin.qtpl:39
+			qw422016.E().S(adminTime(evt.Time))
+//line admin.qtpl:39
+			qw422016.N().S(`</td>
+        <td>`)
+//line admin.qtpl:40
+			qw422016.E().S(evt.InputType)
+//line admin.qtpl:40
+			qw422016.N().S(`</td>
+        <td>`)
+//line admin.qtpl:41
+			qw422016.E().S(evt.Route)
+//line admin.qtpl:41
+			qw422016.N().S(`</td>
+        <td class="`)
+//line admin.qtpl:42
+			qw422016.E().S(adminStatusClass(evt.OK))
+//line admin.qtpl:42
+			qw422016.N().S(`">`)
+//line admin.qtpl:42
+			qw422016.N().D(evt.StatusCode)
+//line admin.qtpl:42
+			qw422016.N().S(`</td>
+        <td>
+          `)
+//line admin.qtpl:44
+			for _, del := range evt.Deliveries {
+//line admin.qtpl:44
+				qw422016.N().S(`
+          <div class="`)
+//line admin.qtpl:45
+				qw422016.E().S(adminStatusClass(del.OK()))
+//line admin.qtpl:45
+				qw422016.N().S(`">`)
+//line admin.qtpl:45
+				qw422016.E().S(del.Adapter)
+//line admin.qtpl:45
+				if len(del.Host) > 0 {
+//line admin.qtpl:45
+					qw422016.N().S(` (`)
+//line admin.qtpl:45
+					qw422016.E().S(del.Host)
+//line admin.qtpl:45
+					qw422016.N().S(`)`)
+//line admin.qtpl:45
+				}
+//line admin.qtpl:45
+				qw422016.N().S(`: `)
+//line admin.qtpl:45
+				qw422016.N().D(del.StatusCode)
+//line admin.qtpl:45
+				qw422016.N().S(` in `)
+//line admin.qtpl:45
+				qw422016.N().DL(del.LatencyMS)
+//line admin.qtpl:45
+				qw422016.N().S(`ms`)
+//line admin.qtpl:45
+				if del.Retries > 0 {
+//line admin.qtpl:45
+					qw422016.N().S(`, `)
+//line admin.qtpl:45
+					qw422016.N().D(del.Retries)
+//line admin.qtpl:45
+					qw422016.N().S(` retries`)
+//line admin.qtpl:45
+				}
+//line admin.qtpl:45
+				qw422016.N().S(`</div>
+          `)
+//line admin.qtpl:46
+				if len(del.Error) > 0 {
+//line admin.qtpl:46
+					qw422016.N().S(`<pre>`)
+//line admin.qtpl:46
+					qw422016.E().S(del.Error)
+//line admin.qtpl:46
+					qw422016.N().S(`</pre>`)
+//line admin.qtpl:46
+				}
+//line admin.qtpl:46
+				qw422016.N().S(`
+          `)
+//line admin.qtpl:47
+			}
+//line admin.qtpl:47
+			qw422016.N().S(`
+          `)
+//line admin.qtpl:48
+			for _, errInfo := range evt.Errors {
+//line admin.qtpl:48
+				qw422016.N().S(`
+          <div class="fail">`)
+//line admin.qtpl:49
+				qw422016.E().S(string(errInfo.Type))
+//line admin.qtpl:49
+				qw422016.N().S(` `)
+//line admin.qtpl:49
+				qw422016.N().D(errInfo.StatusCode)
+//line admin.qtpl:49
+				qw422016.N().S(`: `)
+//line admin.qtpl:49
+				qw422016.E().S(errInfo.Message)
+//line admin.qtpl:49
+				qw422016.N().S(`</div>
+          `)
+//line admin.qtpl:50
+			}
+//line admin.qtpl:50
+			qw422016.N().S(`
+        </td>
+        <td><pre>`)
+//line admin.qtpl:52
+			qw422016.E().S(adminMessageJSON(evt))
+//line admin.qtpl:52
+			qw422016.N().S(`</pre></td>
+        <td>
+          <a href="/admin/raw?id=`)
+//line admin.qtpl:54
+			qw422016.N().U(evt.ID)
+//line admin.qtpl:54
+			qw422016.N().S(`">Raw body</a>`)
+//line admin.qtpl:54
+			if evt.Truncated {
+//line admin.qtpl:54
+				qw422016.N().S(` (truncated)`)
+//line admin.qtpl:54
+			} else {
+//line admin.qtpl:54
+				qw422016.N().S(`
+          <form action="/admin/replay?id=`)
+//line admin.qtpl:55
+				qw422016.N().U(evt.ID)
+//line admin.qtpl:55
+				qw422016.N().S(`" method="post">`)
+//line admin.qtpl:55
+				if data.ReplayToken != nil {
+//line admin.qtpl:55
+					qw422016.N().S(`<input type="hidden" name="replayToken" value="`)
+//line admin.qtpl:55
+					qw422016.E().S(data.ReplayToken(evt.ID))
+//line admin.qtpl:55
+					qw422016.N().S(`">`)
+//line admin.qtpl:55
+				}
+//line admin.qtpl:55
+				qw422016.N().S(`<button type="submit">Replay</button></form>`)
+//line admin.qtpl:55
+			}
+//line admin.qtpl:55
+			qw422016.N().S(`
+        </td>
+      </tr>
+      `)
+//line admin.qtpl:58
+		}
+//line admin.qtpl:58
+		qw422016.N().S(`
+    </table>
+    `)
+//line admin.qtpl:60
+	}
+//line admin.qtpl:60
+	qw422016.N().S(`
+  </body>
+</html>
+`)
+//line admin.qtpl:63
+}
+
+//line admin.qtpl:63
+func WriteAdminPage(qq422016 qtio422016.Writer, data AdminData) {
+//line admin.qtpl:63
+	qw422016 := qt422016.AcquireWriter(qq422016)
+//line admin.qtpl:63
+	StreamAdminPage(qw422016, data)
+//line admin.qtpl:63
+	qt422016.ReleaseWriter(qw422016)
+//line admin.qtpl:63
+}
+
+//line admin.qtpl:63
+func AdminPage(data AdminData) string {
+//line admin.qtpl:63
+	qb422016 := qt422016.AcquireByteBuffer()
+//line admin.qtpl:63
+	WriteAdminPage(qb422016, data)
+//line admin.qtpl:63
+	qs422016 := string(qb422016.B)
+//line admin.qtpl:63
+	qt422016.ReleaseByteBuffer(qb422016)
+//line admin.qtpl:63
+	return qs422016
+//line admin.qtpl:63
+}
@@ -1,6 +1,46 @@
 package templates
 
+import (
+	"encoding/json"
+)
+
 type HomeData struct {
-	HomeURL    string
-	WebhookURL string
+	HomeURL       string
+	WebhookURL    string
+	ExampleURL    string
+	Handlers      []string            // input types, e.g. `aha`
+	OutputTypes   []string            // output types, e.g. `glip`
+	Adapters      []string            // named adapters configured on the server
+	HandlerParams map[string][]string // handler-specific query string parameters
+	Examples      map[string][]string // example event slugs per input type
+}
+
+// builderConfig is the URL builder configuration exposed to the home page script.
+type builderConfig struct {
+	WebhookURL    string              `json:"webhookURL"`
+	ExampleURL    string              `json:"exampleURL"`
+	HandlerParams map[string][]string `json:"handlerParams"`
+	Examples      map[string][]string `json:"examples"`
+}
+
+// BuilderConfigJSON returns the URL builder configuration as JSON that
+// is safe to embed in a `<script>` element.
+func (data HomeData) BuilderConfigJSON() string {
+	cfg := builderConfig{
+		WebhookURL:    data.WebhookURL,
+		ExampleURL:    data.ExampleURL,
+		HandlerParams: data.HandlerParams,
+		Examples:      data.Examples}
+	if cfg.HandlerParams == nil {
+		cfg.HandlerParams = map[string][]string{}
+	}
+	if cfg.Examples == nil {
+		cfg.Examples = map[string][]string{}
+	}
+	// `json.Marshal` escapes `<`, `>` and `&` so the output cannot close the script element.
+	bytes, err := json.Marshal(cfg)
+	if err != nil {
+		return "{}"
+	}
+	return string(bytes)
 }
@@ -28,100 +28,257 @@ func StreamHomePage(qw422016 *qt422016.Writer, data HomeData) {
     <title>Chathooks</title>
   </head>
   <style>
-    h1, h2, p, select {font-family: Arial, Helvetica, sans-serif;}
+    h1, h2, p, select, label, button {font-family: Arial, Helvetica, sans-serif;}
+    label {display:inline-block;width:10em;}
     .code {font-family: monospace;background-color:#efefef;border:1px solid #aaa;width:90%;height:5em;padding:0.3em;}
     .fixed {font-family: monospace;background-color:#efefef;border:1px solid #aaa;width:90%;height:1em;padding:0.3em;color:#800000;border-radius:3px;}
+    .hint {color:#666;font-size:0.9em;}
   </style>
   <script type="text/javascript">
 
-// https://stackoverflow.com/questions/4907843/open-a-url-in-a-new-tab-and-not-a-new-window-using-javascript
-function buildWebhookUrl() {
-  var proxyUrl = '`)
+var builderConfig = `)
 //line home.qtpl:17
-	qw422016.E().S(data.WebhookURL)
+	qw422016.N().S(data.BuilderConfigJSON())
 //line home.qtpl:17
-	qw422016.N().S(`';
-  var webhookUrl = encodeURIComponent(document.getElementById('webhookUrlOrGuid').value);
-  var input = encodeURIComponent(document.getElementById('input').value);
-  var token = encodeURIComponent(document.getElementById('token').value);
+	qw422016.N().S(`;
+
+function fieldValue(id) {
+  return document.getElementById(id).value.trim();
+}
+
+function selectedValues(id) {
+  var values = [];
+  var options = document.getElementById(id).options;
+  for (var i = 0; i < options.length; i++) {
+    if (options[i].selected && options[i].value.length > 0) {
+      values.push(options[i].value);
+    }
+  }
+  return values;
+}
+
+function buildQueryParams() {
+  var params = [];
+  var add = function(name, value) {
+    if (value.length > 0) {
+      params.push(encodeURIComponent(name) + '=' + encodeURIComponent(value));
+    }
+  };
+  add('inputType', fieldValue('inputType'));
+  add('outputType', fieldValue('outputType'));
+  add('outputURL', fieldValue('outputURL'));
+  add('outputFormat', fieldValue('outputFormat'));
+  add('adapters', selectedValues('adapters').join(','));
+  add('defaultIcon', fieldValue('defaultIcon'));
+  add('defaultActivity', fieldValue('defaultActivity'));
+  var handlerParams = document.getElementById('handlerParams').getElementsByTagName('input');
+  for (var i = 0; i < handlerParams.length; i++) {
+    add(handlerParams[i].name, handlerParams[i].value.trim());
+  }
+  add('token', fieldValue('token'));
+  return params;
+}
 
-  var queryString = '?outputType=glip&url=' + webhookUrl + '&inputType=' + input + '&token=' + token;
+function buildWebhookUrl() {
+  var params = buildQueryParams();
+  if (params.length === 0) {
+    return builderConfig.webhookURL;
+  }
+  return builderConfig.webhookURL + '?' + params.join('&');
+}
 
-  return proxyUrl + queryString
+function buildAndShowWebhookUrl() {
+  document.getElementById('proxyUrl').value = buildWebhookUrl();
 }
 
-function buildAndShowRedirectUrl() {
-  var webhookUrl = buildWebhookUrl();
-  var span = document.getElementById('proxyUrl');
+function showHandlerOptions() {
+  var inputType = fieldValue('inputType');
+
+  var paramsDiv = document.getElementById('handlerParams');
+  while (paramsDiv.firstChild) {
+    paramsDiv.removeChild(paramsDiv.firstChild);
+  }
+  var names = builderConfig.handlerParams[inputType] || [];
+  for (var i = 0; i < names.length; i++) {
+    var p = document.createElement('p');
+    var label = document.createElement('label');
+    label.appendChild(document.createTextNode(names[i]));
+    var input = document.createElement('input');
+    input.type = 'text';
+    input.name = names[i];
+    input.style.width = '400px';
+    input.onchange = buildAndShowWebhookUrl;
+    p.appendChild(label);
+    p.appendChild(input);
+    paramsDiv.appendChild(p);
+  }
+
+  var exampleSelect = document.getElementById('example');
+  while (exampleSelect.firstChild) {
+    exampleSelect.removeChild(exampleSelect.firstChild);
+  }
+  var slugs = builderConfig.examples[inputType] || [];
+  for (var j = 0; j < slugs.length; j++) {
+    var option = document.createElement('option');
+    option.appendChild(document.createTextNode(slugs[j]));
+    exampleSelect.appendChild(option);
+  }
+  document.getElementById('sendExample').disabled = slugs.length === 0;
+
+  buildAndShowWebhookUrl();
+}
 
-  while( span.firstChild ) {
-    span.removeChild( span.firstChild );
+function showResult(text) {
+  var result = document.getElementById('exampleResult');
+  while (result.firstChild) {
+    result.removeChild(result.firstChild);
   }
-  span.appendChild( document.createTextNode(webhookUrl) );
+  result.appendChild(document.createTextNode(text));
+}
+
+function sendExample() {
+  var inputType = fieldValue('inputType');
+  var slug = fieldValue('example');
+  var exampleUrl = builderConfig.exampleURL +
+    '?inputType=' + encodeURIComponent(inputType) +
+    '&slug=' + encodeURIComponent(slug);
+  showResult('Sending...');
+  fetch(exampleUrl).then(function(res) {
+    if (!res.ok) {
+      throw new Error('example not found: ' + res.status);
+    }
+    var contentType = res.headers.get('Content-Type');
+    return res.text().then(function(body) {
+      return fetch(buildWebhookUrl(), {
+        method: 'POST',
+        headers: {'Content-Type': contentType},
+        body: body
+      });
+    });
+  }).then(function(res) {
+    return res.text().then(function(body) {
+      showResult(res.status + ' ' + body);
+    });
+  }).catch(function(err) {
+    showResult(err.toString());
+  });
 }
 
   </script>
   <body>
     <img src="https://raw.githubusercontent.com/grokify/chathooks/master/docs/logos/logo_chathooks_long_600x150.png" />
     <p><a href="`)
-//line home.qtpl:40
+//line home.qtpl:144
 	qw422016.E().S(data.HomeURL)
-//line home.qtpl:40
+//line home.qtpl:144
 	qw422016.N().S(`">`)
-//line home.qtpl:40
+//line home.qtpl:144
 	qw422016.E().S(data.HomeURL)
-//line home.qtpl:40
+//line home.qtpl:144
 	qw422016.N().S(`</a></p>
 
-    <p>Easily connect your webhooks to <a href="https://glip.com">Glip</a>!</p>
+    <p>Easily connect your webhooks to <a href="https://glip.com">Glip</a>, <a href="https://slack.com">Slack</a> and more!</p>
 
     <p>View the code on GitHub: <a href="https://github.com/grokify/chathooks">Chathooks</a>.</p>
 
-    <h2>Step 1) Create a Glip Webhook URL</h2>
+    <h2>Step 1) Create a chat webhook URL</h2>
+
+    <p>Create an incoming webhook in your chat service and copy its URL, or use an adapter configured on this server.</p>
 
     <h2>Step 2) Create your Chathooks webhook proxy URL</h2>
 
-    <form action="/button" method="post">
-
-      <p><select id="input" name="source" onchange="buildAndShowWebhookUrl()">
-        <option value="slack">slack (inbound)</option>
-        <option>aha</option>
-        <option>appsignal</option>
-        <option>apteligent</option>
-        <option>circleci</option>
-        <option>codeship</option>
-        <option>confluence</option>
-        <option>datadog</option>
-        <option>deskdotcom</option>
-        <option>enchant</option>
-        <option>gosquared</option>
-        <option>gosquared2</option>
-        <option>heroku</option>
-        <option>librato</option>
-        <option>magnumci</option>
-        <option>marketo</option>
-        <option>opsgenie</option>
-        <option>papertrail</option>
-        <option>pingdom</option>
-        <option>raygun</option>
-        <option>runscope</option>
-        <option>semaphore</option>
-        <option>statuspage</option>
-        <option>travisci</option>
-        <option>userlike</option>
-        <option>victorops</option>
+    <form onsubmit="return false;">
+
+      <p><label for="inputType">inputType</label><select id="inputType" name="inputType" onchange="showHandlerOptions()">
+        `)
+//line home.qtpl:159
+	for _, handler := range data.Handlers {
+//line home.qtpl:159
+		qw422016.N().S(`<option value="`)
+//line home.qtpl:159
+		qw422016.E().S(handler)
+//line home.qtpl:159
+		qw422016.N().S(`">`)
+//line home.qtpl:159
+		qw422016.E().S(handler)
+//line home.qtpl:159
+		qw422016.N().S(`</option>
+        `)
+//line home.qtpl:160
+	}
+//line home.qtpl:160
+	qw422016.N().S(`</select> Required</p>
+
+      <div id="handlerParams"></div>
+
+      <p><label for="outputType">outputType</label><select id="outputType" name="outputType" onchange="buildAndShowWebhookUrl()">
+        <option value="">none</option>
+        `)
+//line home.qtpl:166
+	for _, outputType := range data.OutputTypes {
+//line home.qtpl:166
+		qw422016.N().S(`<option value="`)
+//line home.qtpl:166
+		qw422016.E().S(outputType)
+//line home.qtpl:166
+		qw422016.N().S(`">`)
+//line home.qtpl:166
+		qw422016.E().S(outputType)
+//line home.qtpl:166
+		qw422016.N().S(`</option>
+        `)
+//line home.qtpl:167
+	}
+//line home.qtpl:167
+	qw422016.N().S(`</select></p>
+
+      <p><label for="outputURL">outputURL</label><input type="text" id="outputURL" name="outputURL" value="" placeholder="Your chat webhook URL" style="width:400px" onchange="buildAndShowWebhookUrl()" /> Required with outputType</p>
+
+      <p><label for="outputFormat">outputFormat</label><select id="outputFormat" name="outputFormat" onchange="buildAndShowWebhookUrl()">
+        <option value="">default</option>
+        <option value="card">card</option>
+        <option value="nocard">nocard</option>
+        <option value="adaptivecard">adaptivecard</option>
       </select></p>
 
-      <p><input type="text" id="webhookUrlOrGuid" name="webhookUrlOrGuid" value="" placeholder="Your Glip Webhook URL" style="width:400px" onchange="buildAndShowRedirectUrl()" /> Required</p>
+      <p><label for="adapters">adapters</label><select id="adapters" name="adapters" multiple onchange="buildAndShowWebhookUrl()">
+        `)
+//line home.qtpl:179
+	for _, adapter := range data.Adapters {
+//line home.qtpl:179
+		qw422016.N().S(`<option value="`)
+//line home.qtpl:179
+		qw422016.E().S(adapter)
+//line home.qtpl:179
+		qw422016.N().S(`">`)
+//line home.qtpl:179
+		qw422016.E().S(adapter)
+//line home.qtpl:179
+		qw422016.N().S(`</option>
+        `)
+//line home.qtpl:180
+	}
+//line home.qtpl:180
+	qw422016.N().S(`</select> <span class="hint">Named adapters configured on this server</span></p>
 
-      <p><input type="text" id="token" name="token" value="demo account - do not use for production" placeholder="Your Glip Webhook URL" style="width:400px" onchange="buildAndShowRedirectUrl()" /> Required</p>
+      <p><label for="defaultIcon">defaultIcon</label><input type="text" id="defaultIcon" name="defaultIcon" value="" placeholder="Icon URL or emoji, e.g. :rocket:" style="width:400px" onchange="buildAndShowWebhookUrl()" /></p>
+
+      <p><label for="defaultActivity">defaultActivity</label><input type="text" id="defaultActivity" name="defaultActivity" value="" placeholder="Activity used when the event has none" style="width:400px" onchange="buildAndShowWebhookUrl()" /></p>
+
+      <p><label for="token">token</label><input type="text" id="token" name="token" value="" placeholder="Your Chathooks token" style="width:400px" onchange="buildAndShowWebhookUrl()" /></p>
 
       <p>Your Chathooks Webhook Proxy URL (be sure to verify):</p>
-      <textarea id="proxyUrl" class="code"></textarea>
+      <textarea id="proxyUrl" class="code" readonly></textarea>
+
+      <h2>Step 3) Send a test example</h2>
+
+      <p><label for="example">example</label><select id="example" name="example"></select>
+        <button type="button" id="sendExample" onclick="sendExample()">Send test example</button></p>
+      <p class="fixed" id="exampleResult"></p>
 
     </form>
 
-    <h2>Step 3) Add Your Chathooks URL to your webapp</h2>
+    <h2>Step 4) Add Your Chathooks URL to your webapp</h2>
 
     <h2>Next Steps</h2>
 
@@ -130,36 +287,36 @@ function buildAndShowRedirectUrl() {
     <hr/>
     <p><a href="https://github.com/grokify/chathooks">https://github.com/grokify/chathooks</a></p>
   <script>
-    buildAndShowRedirectUrl();
+    showHandlerOptions();
   </script>
   </body>
 </html>
 `)
-//line home.qtpl:103
+//line home.qtpl:212
 }
 
-//line home.qtpl:103
+//line home.qtpl:212
 func WriteHomePage(qq422016 qtio422016.Writer, data HomeData) {
-//line home.qtpl:103
+//line home.qtpl:212
 	qw422016 := qt422016.AcquireWriter(qq422016)
-//line home.qtpl:103
+//line home.qtpl:212
 	StreamHomePage(qw422016, data)
-//line home.qtpl:103
+//line home.qtpl:212
 	qt422016.ReleaseWriter(qw422016)
-//line home.qtpl:103
+//line home.qtpl:212
 }
 
-//line home.qtpl:103
+//line home.qtpl:212
 func HomePage(data HomeData) string {
-//line home.qtpl:103
+//line home.qtpl:212
 	qb422016 := qt422016.AcquireByteBuffer()
-//line home.qtpl:103
+//line home.qtpl:212
 	WriteHomePage(qb422016, data)
-//line home.qtpl:103
+//line home.qtpl:212
 	qs422016 := string(qb422016.B)
-//line home.qtpl:103
+//line home.qtpl:212
 	qt422016.ReleaseByteBuffer(qb422016)
-//line home.qtpl:103
+//line home.qtpl:212
 	return qs422016
-//line home.qtpl:103
+//line home.qtpl:212
 }
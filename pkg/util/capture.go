@@ -0,0 +1,212 @@
+package util
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/grokify/chathooks/pkg/config"
+)
+
+const (
+	CaptureSlugPrefix  = "captured-"
+	CaptureMetaPrefix  = "event-capture_"
+	CaptureRedacted    = "REDACTED"
+	captureDirPerm     = 0o755
+	captureFilePerm    = 0o600
+	captureMetaFileExt = "json"
+
+	// CaptureMaxBodyBytes is the largest request body captured.
+	CaptureMaxBodyBytes = 1 << 20
+	// CaptureMaxFiles is the default number of requests captured per
+	// handler.
+	CaptureMaxFiles = 100
+)
+
+var (
+	ErrCaptureBodyTooLarge = errors.New("capture body too large")
+	ErrCaptureLimitReached = errors.New("capture limit reached")
+
+	// captureSecretParams are query string parameters whose values
+	// are never written to disk.
+	captureSecretParams = map[string]int{
+		strings.ToLower(config.ParamNameToken):     1,
+		strings.ToLower(config.ParamNameOutputURL): 1,
+		"url": 1}
+	// captureSecretHeaders are request headers whose values are never
+	// written to disk. Headers containing `token`, `secret`, `signature`
+	// or `key` are also redacted.
+	captureSecretHeaders = map[string]int{
+		"authorization":       1,
+		"cookie":              1,
+		"proxy-authorization": 1,
+		"set-cookie":          1}
+	captureSecretHeaderParts = []string{"token", "secret", "signature", "key", "password"}
+)
+
+// CaptureWriter writes inbound webhook requests to a directory using the
+// `docs/handlers/<key>/event-example_<slug>.<ext>` layout so captured
+// traffic can be used directly as example events and test fixtures.
+// Bodies over `MaxBodyBytes` are skipped and at most `MaxFiles` requests
+// are captured per handler.
+type CaptureWriter struct {
+	Dir          string
+	ExampleData  ExampleData
+	MaxBodyBytes int
+	MaxFiles     int
+	mutex        sync.Mutex
+}
+
+// CapturedRequest is the request metadata written alongside each captured body.
+type CapturedRequest struct {
+	HandlerKey  string      `json:"handlerKey"`
+	EventSlug   string      `json:"eventSlug"`
+	BodyFile    string      `json:"bodyFile"`
+	Method      string      `json:"method,omitempty"`
+	Headers     http.Header `json:"headers,omitempty"`
+	QueryParams url.Values  `json:"queryParams,omitempty"`
+	CapturedAt  time.Time   `json:"capturedAt"`
+}
+
+// NewCaptureWriter returns a `CaptureWriter` for `dir`. It returns
+// an error if `dir` is empty.
+func NewCaptureWriter(dir string) (*CaptureWriter, error) {
+	dir = strings.TrimSpace(dir)
+	if len(dir) == 0 {
+		return nil, errors.New("capture directory not set")
+	}
+	data, err := NewExampleData()
+	if err != nil {
+		return nil, err
+	}
+	return &CaptureWriter{
+		Dir:          dir,
+		ExampleData:  data,
+		MaxBodyBytes: CaptureMaxBodyBytes,
+		MaxFiles:     CaptureMaxFiles}, nil
+}
+
+// Capture writes the request body to `<dir>/<handlerKey>/event-example_<slug>.<ext>`
+// and the redacted headers and query parameters to
+// `<dir>/<handlerKey>/event-capture_<slug>.json`. It returns the body file path,
+// or `ErrCaptureBodyTooLarge` or `ErrCaptureLimitReached`.
+func (cw *CaptureWriter) Capture(handlerKey, method string, headers http.Header, query url.Values, body []byte) (string, error) {
+	handlerKey = strings.ToLower(strings.TrimSpace(handlerKey))
+	if len(handlerKey) == 0 || strings.ContainsAny(handlerKey, `/\.`) {
+		return "", fmt.Errorf("invalid handler key [%s]", handlerKey)
+	} else if cw.MaxBodyBytes > 0 && len(body) > cw.MaxBodyBytes {
+		return "", ErrCaptureBodyTooLarge
+	}
+	handlerDir := filepath.Join(cw.Dir, handlerKey)
+
+	cw.mutex.Lock()
+	defer cw.mutex.Unlock()
+
+	if err := os.MkdirAll(handlerDir, captureDirPerm); err != nil {
+		return "", err
+	}
+	if cw.MaxFiles > 0 {
+		if count, err := captureCount(handlerDir); err != nil {
+			return "", err
+		} else if count >= cw.MaxFiles {
+			return "", ErrCaptureLimitReached
+		}
+	}
+	now := time.Now().UTC()
+	eventSlug := cw.nextEventSlug(handlerDir, handlerKey, now)
+	bodyFile := filepath.Join(handlerDir, cw.ExampleData.BuildFilename(handlerKey, eventSlug))
+	if err := os.WriteFile(bodyFile, body, captureFilePerm); err != nil {
+		return "", err
+	}
+
+	meta := CapturedRequest{
+		HandlerKey:  handlerKey,
+		EventSlug:   eventSlug,
+		BodyFile:    filepath.Base(bodyFile),
+		Method:      method,
+		Headers:     RedactCaptureHeaders(headers),
+		QueryParams: RedactCaptureQuery(query),
+		CapturedAt:  now}
+	metaBytes, err := json.MarshalIndent(meta, "", "  ")
+	if err != nil {
+		return bodyFile, err
+	}
+	metaFile := filepath.Join(handlerDir, fmt.Sprintf("%s%s.%s", CaptureMetaPrefix, eventSlug, captureMetaFileExt))
+	return bodyFile, os.WriteFile(metaFile, metaBytes, captureFilePerm)
+}
+
+// captureCount returns the number of requests captured in `handlerDir`.
+func captureCount(handlerDir string) (int, error) {
+	entries, err := os.ReadDir(handlerDir)
+	if err != nil {
+		return 0, err
+	}
+	count := 0
+	for _, entry := range entries {
+		if strings.HasPrefix(entry.Name(), CaptureMetaPrefix+CaptureSlugPrefix) {
+			count++
+		}
+	}
+	return count, nil
+}
+
+// nextEventSlug returns a timestamp based slug that does not collide
+// with an existing capture.
+func (cw *CaptureWriter) nextEventSlug(handlerDir, handlerKey string, t time.Time) string {
+	base := CaptureSlugPrefix + t.Format("20060102t150405z")
+	slug := base
+	for i := 2; ; i++ {
+		if _, err := os.Stat(filepath.Join(handlerDir, cw.ExampleData.BuildFilename(handlerKey, slug))); errors.Is(err, os.ErrNotExist) {
+			return slug
+		}
+		slug = base + "-" + strconv.Itoa(i)
+	}
+}
+
+// RedactCaptureHeaders returns a copy of `headers` with credential values replaced.
+func RedactCaptureHeaders(headers http.Header) http.Header {
+	out := http.Header{}
+	for key, vals := range headers {
+		canonical := http.CanonicalHeaderKey(key)
+		if isCaptureSecretHeader(canonical) {
+			out[canonical] = []string{CaptureRedacted}
+			continue
+		}
+		out[canonical] = append([]string{}, vals...)
+	}
+	return out
+}
+
+// RedactCaptureQuery returns a copy of `query` with token and output URL values replaced.
+func RedactCaptureQuery(query url.Values) url.Values {
+	out := url.Values{}
+	for key, vals := range query {
+		if _, ok := captureSecretParams[strings.ToLower(key)]; ok {
+			out[key] = []string{CaptureRedacted}
+			continue
+		}
+		out[key] = append([]string{}, vals...)
+	}
+	return out
+}
+
+func isCaptureSecretHeader(key string) bool {
+	key = strings.ToLower(key)
+	if _, ok := captureSecretHeaders[key]; ok {
+		return true
+	}
+	for _, part := range captureSecretHeaderParts {
+		if strings.Contains(key, part) {
+			return true
+		}
+	}
+	return false
+}
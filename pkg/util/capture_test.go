@@ -0,0 +1,82 @@
+package util
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var CaptureTests = []struct {
+	handlerKey string
+	body       string
+	wantExt    string
+}{
+	{"datadog", `{"title":"test"}`, ".json"},
+	{"heroku", `app=secure-woodland-9775`, ".txt"}}
+
+func TestCaptureWriter(t *testing.T) {
+	for _, tt := range CaptureTests {
+		cw, err := NewCaptureWriter(t.TempDir())
+		if err != nil {
+			t.Fatalf("NewCaptureWriter(): err %v", err)
+		}
+		headers := http.Header{}
+		headers.Set("Content-Type", "application/json")
+		headers.Set("Authorization", "Bearer secret")
+		query := url.Values{}
+		query.Set("inputType", tt.handlerKey)
+		query.Set("token", "secret")
+		query.Set("outputURL", "https://hooks.glip.com/webhook/secret")
+
+		bodyFile, err := cw.Capture(tt.handlerKey, http.MethodPost, headers, query, []byte(tt.body))
+		if err != nil {
+			t.Fatalf("CaptureWriter.Capture(%s): err %v", tt.handlerKey, err)
+		}
+		name := filepath.Base(bodyFile)
+		if !strings.HasPrefix(name, "event-example_"+CaptureSlugPrefix) || filepath.Ext(name) != tt.wantExt {
+			t.Errorf("CaptureWriter.Capture(%s): bad filename [%s]", tt.handlerKey, name)
+		}
+		body, err := os.ReadFile(bodyFile)
+		if err != nil || string(body) != tt.body {
+			t.Errorf("CaptureWriter.Capture(%s): want body [%s], got [%s]", tt.handlerKey, tt.body, string(body))
+		}
+
+		metaFile := filepath.Join(filepath.Dir(bodyFile),
+			strings.Replace(strings.TrimSuffix(name, tt.wantExt), "event-example_", CaptureMetaPrefix, 1)+".json")
+		metaBytes, err := os.ReadFile(metaFile)
+		if err != nil {
+			t.Fatalf("CaptureWriter.Capture(%s): missing metadata: %v", tt.handlerKey, err)
+		}
+		if strings.Contains(string(metaBytes), "secret") {
+			t.Errorf("CaptureWriter.Capture(%s): metadata not redacted [%s]", tt.handlerKey, string(metaBytes))
+		}
+		meta := CapturedRequest{}
+		if err := json.Unmarshal(metaBytes, &meta); err != nil {
+			t.Fatalf("CaptureWriter.Capture(%s): bad metadata: %v", tt.handlerKey, err)
+		}
+		if meta.QueryParams.Get("inputType") != tt.handlerKey {
+			t.Errorf("CaptureWriter.Capture(%s): want inputType [%s], got [%s]", tt.handlerKey, tt.handlerKey, meta.QueryParams.Get("inputType"))
+		}
+	}
+}
+
+func TestCaptureWriterLimits(t *testing.T) {
+	cw, err := NewCaptureWriter(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewCaptureWriter(): err %v", err)
+	}
+	cw.MaxBodyBytes, cw.MaxFiles = 8, 1
+	if _, err := cw.Capture("datadog", http.MethodPost, nil, nil, []byte(`{"title":"large"}`)); err != ErrCaptureBodyTooLarge {
+		t.Errorf("CaptureWriter.Capture(large): want [%v], got [%v]", ErrCaptureBodyTooLarge, err)
+	}
+	if _, err := cw.Capture("datadog", http.MethodPost, nil, nil, []byte(`{}`)); err != nil {
+		t.Fatalf("CaptureWriter.Capture(): err %v", err)
+	}
+	if _, err := cw.Capture("datadog", http.MethodPost, nil, nil, []byte(`{}`)); err != ErrCaptureLimitReached {
+		t.Errorf("CaptureWriter.Capture(second): want [%v], got [%v]", ErrCaptureLimitReached, err)
+	}
+}
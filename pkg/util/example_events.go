@@ -1,20 +1,28 @@
 package util
 
 import (
-	"encoding/json"
 	"fmt"
-	"os"
+	"io/fs"
 	"path"
+	"regexp"
+	"sort"
 
-	"github.com/grokify/chathooks/pkg/config"
+	"github.com/grokify/chathooks/docs"
 )
 
 const (
 	DefaultExtension = "json"
 )
 
+// rxExampleFile matches example event filenames, capturing the event slug
+// and file extension, e.g. `event-example_build.txt`.
+var rxExampleFile = regexp.MustCompile(`^event-example_(.+)\.(json|txt)$`)
+
+// ExampleData provides the example events for each handler. The handlers
+// and event slugs are read from the example files themselves.
 type ExampleData struct {
 	Data map[string]ExampleSource `json:"data,omitempty"`
+	fsys fs.FS
 }
 
 type ExampleSource struct {
@@ -22,88 +30,66 @@ type ExampleSource struct {
 	EventSlugs    []string `json:"event_slugs,omitempty"`
 }
 
+// NewExampleData returns the example events embedded from `docs/handlers`.
 func NewExampleData() (ExampleData, error) {
-	data := ExampleData{}
-	err := json.Unmarshal(ExampleDataRaw(), &data)
-	return data, err
+	return NewExampleDataFS(docs.Handlers())
 }
 
-func ExampleDataRaw() []byte {
-	return []byte(`{
-    "data": {
-        "appsignal": {
-            "file_extension": "json",
-            "event_slugs": ["marker","exception","performance"]
-        },
-        "apteligent":{
-            "event_slugs": ["alert","alert-open","alert-close"]
-        },
-        "bugsnag":{
-            "event_slugs": ["exception-stack-trace-single","exception-stack-trace-multi","exception-error-message-long"]
-        },
-        "confluence":{
-            "event_slugs": ["page-created","comment-created"]
-        },
-        "deskdotcom":{
-            "event_slugs": ["formatted1","formatted2"]
-        },
-        "gosquared":{
-            "event_slugs": ["site-traffic","smart-group","live-chat"]
-        },
-        "heroku":{
-            "file_extension": "txt",
-            "event_slugs":["build"]
-        },
-        "librato":{
-            "event_slugs":["2","alert-triggered","alert-cleared"]
-        },
-        "marketo":{
-            "event_slugs":["formatted1","formatted2","demo1"]
-        },
-        "opsgenie":{
-            "event_slugs_":["create","close","delete",
-            "acknowledge","unacknowledge"],
+// NewExampleDataFS returns the example events in `fsys`, which has a
+// directory per handler key containing `event-example_<slug>.<ext>` files.
+func NewExampleDataFS(fsys fs.FS) (ExampleData, error) {
+	data := ExampleData{Data: map[string]ExampleSource{}, fsys: fsys}
+	dirs, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return data, err
+	}
+	for _, dir := range dirs {
+		if !dir.IsDir() {
+			continue
+		}
+		files, err := fs.ReadDir(fsys, dir.Name())
+		if err != nil {
+			return data, err
+		}
+		src := ExampleSource{}
+		for _, file := range files {
+			m := rxExampleFile.FindStringSubmatch(file.Name())
+			if file.IsDir() || m == nil {
+				continue
+			}
+			src.EventSlugs = append(src.EventSlugs, m[1])
+			if len(src.FileExtension) == 0 || m[2] != DefaultExtension {
+				src.FileExtension = m[2]
+			}
+		}
+		if len(src.EventSlugs) > 0 {
+			sort.Strings(src.EventSlugs)
+			data.Data[dir.Name()] = src
+		}
+	}
+	return data, nil
+}
 
-            "event_slugs__": [
-            "add-note","add-recipient","add-tags","add-team"],
+// HandlerKeys returns the sorted keys of the handlers with example events.
+func (data *ExampleData) HandlerKeys() []string {
+	keys := []string{}
+	for key := range data.Data {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
 
-            "event_slugs":["remove-tags","assign-ownership","take-ownership", "escalate",
-            "custom-action-test-action"]
-        },
-        "papertrail":{
-            "event_slugs":["notifications-array-len-1","notifications-array"]
-        },
-        "pingdom":{
-            "event_slugs":["http-check"],
-        	"event_slugs_":["dns-check","http-check","http-custom-check","imap-check","ping-check","pop3-check","smtp-check","tcp-check","transaction-check","udp-check"]
-        },
-        "semaphore":{
-            "event_slugs":["build","deploy"]
-        },
-        "slack":{
-            "event_slugs":["attachment","link-emoji"]
-        },
-        "statuspage":{
-            "event_slugs":["incident-updates","incident-updates-create","component-updates"]
-        },
-        "userlike":{
-        	"event_slugs_":["chat-meta_feedback","chat-meta_forward","chat-meta_rating","chat-meta_receive","chat-meta_start","chat-meta_survey"],
-            "event_slugs":["chat-widget_config","offline-message_receive","operator_away","operator_back","operator_offline","operator_online"]
-        },
-        "wootric":{
-            "file_extension": "txt",
-            "event_slugs":["decline-created","response-created"]
-        }
-    }
-}`)
+// EventSlugs returns the sorted example event slugs for a handler.
+func (data *ExampleData) EventSlugs(handlerKey string) []string {
+	return data.Data[handlerKey].EventSlugs
 }
 
 func (data *ExampleData) ExampleMessageBytes(handlerKey string, eventSlug string) ([]byte, error) {
-	filepath := path.Join(
-		config.DocsHandlersDir(),
-		handlerKey,
-		data.BuildFilename(handlerKey, eventSlug))
-	return os.ReadFile(filepath)
+	if data.fsys == nil {
+		return nil, fmt.Errorf("example data has no files for [%s]", handlerKey)
+	}
+	return fs.ReadFile(data.fsys, path.Join(handlerKey, data.BuildFilename(handlerKey, eventSlug)))
 }
 
 func (data *ExampleData) BuildFilename(handlerKey string, eventSlug string) string {
@@ -0,0 +1,55 @@
+package util
+
+import (
+	"slices"
+	"testing"
+	"testing/fstest"
+)
+
+var ExampleDataTests = []struct {
+	handlerKey string
+	eventSlug  string
+	wantFile   string
+}{
+	{"datadog", "formatted1", "event-example_formatted1.json"},
+	{"heroku", "build", "event-example_build.txt"},
+	{"wootric", "response-created", "event-example_response-created.txt"}}
+
+func TestExampleDataEmbedded(t *testing.T) {
+	data, err := NewExampleData()
+	if err != nil {
+		t.Fatalf("NewExampleData(): error [%v]", err)
+	}
+	for _, tt := range ExampleDataTests {
+		if !slices.Contains(data.HandlerKeys(), tt.handlerKey) {
+			t.Errorf("ExampleData.HandlerKeys(): missing [%s]", tt.handlerKey)
+		}
+		if !slices.Contains(data.EventSlugs(tt.handlerKey), tt.eventSlug) {
+			t.Errorf("ExampleData.EventSlugs(%s): missing [%s]", tt.handlerKey, tt.eventSlug)
+		}
+		if filename := data.BuildFilename(tt.handlerKey, tt.eventSlug); filename != tt.wantFile {
+			t.Errorf("ExampleData.BuildFilename(%s, %s): want [%s], got [%s]", tt.handlerKey, tt.eventSlug, tt.wantFile, filename)
+		}
+		if bytes, err := data.ExampleMessageBytes(tt.handlerKey, tt.eventSlug); err != nil || len(bytes) == 0 {
+			t.Errorf("ExampleData.ExampleMessageBytes(%s, %s): error [%v]", tt.handlerKey, tt.eventSlug, err)
+		}
+	}
+}
+
+func TestExampleDataFS(t *testing.T) {
+	data, err := NewExampleDataFS(fstest.MapFS{
+		"acme/event-example_b.json":     {Data: []byte(`{}`)},
+		"acme/event-example_a.json":     {Data: []byte(`{}`)},
+		"acme/event-example_a_demo.png": {Data: []byte{}},
+		"acme/README.md":                {Data: []byte{}},
+		"empty/README.md":               {Data: []byte{}}})
+	if err != nil {
+		t.Fatalf("NewExampleDataFS(): error [%v]", err)
+	}
+	if keys := data.HandlerKeys(); !slices.Equal(keys, []string{"acme"}) {
+		t.Errorf("ExampleData.HandlerKeys(): want [acme], got %v", keys)
+	}
+	if slugs := data.EventSlugs("acme"); !slices.Equal(slugs, []string{"a", "b"}) {
+		t.Errorf("ExampleData.EventSlugs(acme): want [a b], got %v", slugs)
+	}
+}